@@ -0,0 +1,456 @@
+// Package agent adalah agen perekam yang berjalan di komputer ruang kelas.
+// Agen mendaftar ke backend dengan API key scope agen, mengambil job rekaman
+// untuk ruangannya lewat long-poll, merekam secara lokal, lalu mengunggah
+// file WAV per sesi di latar belakang dalam potongan yang bisa dilanjutkan
+// jika terputus.
+// Heartbeat berkala memberi tahu backend bahwa agen masih online dan
+// membawa permintaan penghentian job.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"CLAIRE/config"
+	"CLAIRE/models"
+	"CLAIRE/recorder"
+)
+
+// Versi protokol agen yang dilaporkan saat register
+const Versi = "1.0.0"
+
+// errDihentikan adalah penyebab pembatalan job ketika server meminta berhenti
+var errDihentikan = errors.New("job dihentikan oleh server")
+
+// Config adalah pengaturan agen
+type Config struct {
+	ServerURL         string
+	APIKey            string
+	Nama              string
+	Ruangan           []string // kode ruangan yang diminta saat register
+	Dir               string   // direktori rekaman lokal sebelum diunggah
+	HeartbeatInterval time.Duration
+	LongPoll          time.Duration
+	ChunkSize         int64
+	MaxJobs           int // jumlah job yang boleh direkam bersamaan
+
+	// Konfigurasi perekam (RECORDER_*), sama dengan di server
+	Recorder *config.Config
+}
+
+// Agent menjalankan siklus register, heartbeat dan pengambilan job
+type Agent struct {
+	cfg    Config
+	client *Client
+
+	mu    sync.Mutex
+	aktif map[string]context.CancelCauseFunc // key: job ID
+	slot  chan struct{}
+	wg    sync.WaitGroup
+
+	// Antrian upload (lihat unggah.go)
+	sinyalUnggah  chan struct{}
+	unggahBerubah chan struct{}
+}
+
+// New membuat Agent dari konfigurasi
+func New(cfg Config) *Agent {
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = 15 * time.Second
+	}
+	if cfg.LongPoll <= 0 {
+		cfg.LongPoll = 30 * time.Second
+	}
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = 1 << 20
+	}
+	if cfg.MaxJobs <= 0 {
+		cfg.MaxJobs = 1
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = "agent-recordings"
+	}
+	if cfg.Recorder == nil {
+		cfg.Recorder = config.LoadConfig()
+	}
+
+	return &Agent{
+		cfg:    cfg,
+		client: NewClient(cfg.ServerURL, cfg.APIKey),
+		aktif:  make(map[string]context.CancelCauseFunc),
+		slot:   make(chan struct{}, cfg.MaxJobs),
+
+		sinyalUnggah:  make(chan struct{}, 1),
+		unggahBerubah: make(chan struct{}),
+	}
+}
+
+// Run menjalankan agen sampai ctx dibatalkan. Job yang sedang direkam saat
+// agen berhenti dilanjutkan pada start berikutnya.
+func (a *Agent) Run(ctx context.Context) error {
+	if err := os.MkdirAll(a.cfg.Dir, 0755); err != nil {
+		return fmt.Errorf("gagal membuat direktori rekaman: %v", err)
+	}
+	if err := a.register(ctx); err != nil {
+		return err
+	}
+
+	a.wg.Add(2)
+	go a.heartbeat(ctx)
+	go a.pengunggah(ctx)
+
+	gagal := 0
+	for ctx.Err() == nil {
+		// Tunggu slot kosong sebelum meminta job baru
+		select {
+		case a.slot <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+
+		paket, err := a.client.JobBerikutnya(ctx, a.jobAktif(), a.cfg.LongPoll)
+		if err != nil || paket == nil {
+			<-a.slot
+			if err != nil && ctx.Err() == nil {
+				gagal++
+				log.Printf("Gagal mengambil job: %v", err)
+				tunggu(ctx, backoff(gagal))
+			}
+			continue
+		}
+		gagal = 0
+
+		jobCtx, cancel := context.WithCancelCause(ctx)
+		a.mu.Lock()
+		a.aktif[paket.Job.ID.String()] = cancel
+		a.mu.Unlock()
+
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			var once sync.Once
+			lepasSlot := func() { once.Do(func() { <-a.slot }) }
+			defer lepasSlot()
+			defer a.lepas(paket.Job.ID.String())
+			a.jalankan(ctx, jobCtx, paket, lepasSlot)
+		}()
+	}
+
+	a.wg.Wait()
+	return nil
+}
+
+// register mendaftarkan agen, diulang sampai berhasil kecuali API key ditolak
+func (a *Agent) register(ctx context.Context) error {
+	hostname, _ := os.Hostname()
+	info := InfoRegister{
+		Nama:     a.cfg.Nama,
+		Hostname: hostname,
+		Versi:    Versi,
+		Recorder: a.cfg.Recorder.RecorderBackend,
+		Ruangan:  a.cfg.Ruangan,
+	}
+
+	for percobaan := 1; ; percobaan++ {
+		hasil, err := a.client.Register(ctx, info)
+		if err == nil {
+			kode := make([]string, 0, len(hasil.Ruangan))
+			for _, ruangan := range hasil.Ruangan {
+				kode = append(kode, ruangan.Kode)
+			}
+			log.Printf("Agen %s terdaftar (id %s), melayani ruangan: %v", hasil.Agent.Nama, hasil.Agent.ID, kode)
+			for ruangan, alasan := range hasil.RuanganDitolak {
+				log.Printf("Ruangan %s tidak diberikan: %s", ruangan, alasan)
+			}
+			return nil
+		}
+		if Permanen(err) {
+			return fmt.Errorf("register ditolak: %v", err)
+		}
+		log.Printf("Gagal register ke %s (percobaan %d): %v", a.cfg.ServerURL, percobaan, err)
+		if !tunggu(ctx, backoff(percobaan)) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (a *Agent) heartbeat(ctx context.Context) {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		hentikan, err := a.client.Heartbeat(ctx, a.jobAktif())
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Heartbeat gagal: %v", err)
+		}
+		for _, jobID := range hentikan {
+			a.mu.Lock()
+			cancel, ok := a.aktif[jobID]
+			a.mu.Unlock()
+			if ok {
+				log.Printf("Server meminta job %s dihentikan", jobID)
+				cancel(errDihentikan)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Agent) jobAktif() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ids := make([]string, 0, len(a.aktif))
+	for id := range a.aktif {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (a *Agent) lepas(jobID string) {
+	a.mu.Lock()
+	cancel := a.aktif[jobID]
+	delete(a.aktif, jobID)
+	a.mu.Unlock()
+	if cancel != nil {
+		cancel(nil)
+	}
+}
+
+// jalankan merekam semua sesi job, menunggu antrian upload job kosong, lalu
+// menyerahkannya ke server. ctx adalah umur agen; jobCtx ikut dibatalkan
+// ketika server meminta job dihentikan. Upload memakai ctx supaya rekaman
+// parsial tetap terkirim setelah stop. lepasSlot dipanggil setelah perekaman
+// selesai supaya job berikutnya bisa direkam selama upload berjalan.
+func (a *Agent) jalankan(ctx context.Context, jobCtx context.Context, paket *PaketJob, lepasSlot func()) {
+	job := &paket.Job
+	jobID := job.ID.String()
+	log.Printf("Memulai job %s untuk jadwal %s (%d sesi)", jobID, job.JadwalID, len(job.Sesi))
+
+	rec, err := a.perekam(paket)
+	if err != nil {
+		a.selesai(ctx, job, fmt.Sprintf("gagal menyiapkan perekam: %v", err))
+		return
+	}
+
+	durasi := time.Duration(job.DurasiSesi) * time.Second
+	jeda := time.Duration(job.JedaSesi) * time.Second
+	direkam := false
+	for i := range job.Sesi {
+		sesi := &job.Sesi[i]
+		switch sesi.Status {
+		case models.SesiStatusRecording:
+			// Agen berhenti di tengah sesi ini: kirim apa yang sudah terekam
+			a.lanjutkanSesi(ctx, job, sesi)
+		case models.SesiStatusPending:
+			if job.StopDiminta || jobCtx.Err() != nil {
+				continue
+			}
+			if direkam && jeda > 0 && !tunggu(jobCtx, jeda) {
+				continue
+			}
+			a.rekamSesi(ctx, jobCtx, rec, job, sesi, durasi)
+			direkam = true
+		}
+	}
+	lepasSlot()
+
+	if !a.tungguUnggah(ctx, jobID) || ctx.Err() != nil {
+		// Agen berhenti: job dilanjutkan saat agen start lagi
+		return
+	}
+	a.selesai(ctx, job, "")
+}
+
+// perekam memilih perekam ruangan seperti di server: perangkat yang
+// terdaftar di data ruangan didahulukan, selain itu RECORDER_ROOM_DEVICES
+// atau backend default agen
+func (a *Agent) perekam(paket *PaketJob) (recorder.Recorder, error) {
+	if paket.Ruangan != nil && paket.Ruangan.RecorderBackend != "" {
+		return recorder.FromRegistry(a.cfg.Recorder, paket.Ruangan.RecorderBackend, paket.Ruangan.RecorderDevice)
+	}
+	return recorder.ForRoom(a.cfg.Recorder, paket.Job.Jadwal.Ruangan)
+}
+
+func (a *Agent) pathLokal(job *models.RekamanJob, sesi *models.RekamanSesi) string {
+	return filepath.Join(a.cfg.Dir, fmt.Sprintf("job_%s_sesi%d.wav", job.ID, sesi.NomorSesi))
+}
+
+func (a *Agent) rekamSesi(ctx, jobCtx context.Context, rec recorder.Recorder, job *models.RekamanJob, sesi *models.RekamanSesi, durasi time.Duration) {
+	jobID := job.ID.String()
+	if err := a.client.UpdateSesi(ctx, jobID, sesi.NomorSesi, models.SesiStatusRecording, ""); err != nil {
+		log.Printf("Sesi %d job %s tidak bisa dimulai: %v", sesi.NomorSesi, jobID, err)
+		return
+	}
+
+	path := a.pathLokal(job, sesi)
+	sementara := path + ".rec"
+	log.Printf("Merekam sesi %d job %s dengan %s", sesi.NomorSesi, jobID, rec.Name())
+
+	err := rec.Record(jobCtx, sementara, durasi)
+	parsial := false
+	if err != nil {
+		switch {
+		case errors.Is(context.Cause(jobCtx), errDihentikan):
+			size, ferr := recorder.FinalizeWAV(sementara)
+			if ferr != nil || size == 0 {
+				os.Remove(sementara)
+				a.gagalSesi(ctx, job, sesi, "rekaman dihentikan sebelum ada audio yang tersimpan")
+				return
+			}
+			parsial = true
+		case ctx.Err() != nil:
+			// Agen berhenti: file dirapikan dan diunggah saat start berikutnya
+			return
+		default:
+			os.Remove(sementara)
+			a.gagalSesi(ctx, job, sesi, fmt.Sprintf("error recording audio: %v", err))
+			return
+		}
+	}
+
+	if parsial {
+		path = a.pathParsial(job, sesi)
+	}
+	if err := os.Rename(sementara, path); err != nil {
+		a.gagalSesi(ctx, job, sesi, fmt.Sprintf("gagal menyimpan rekaman lokal: %v", err))
+		return
+	}
+	a.antrekanUnggah()
+}
+
+// lanjutkanSesi menangani sesi yang tercatat sedang direkam ketika job
+// diambil kembali setelah agen restart
+func (a *Agent) lanjutkanSesi(ctx context.Context, job *models.RekamanJob, sesi *models.RekamanSesi) {
+	path := a.pathLokal(job, sesi)
+	for _, siap := range []string{path, a.pathParsial(job, sesi)} {
+		if _, err := os.Stat(siap); err == nil {
+			// Sudah ada di antrian upload
+			return
+		}
+	}
+
+	sementara := path + ".rec"
+	size, err := recorder.FinalizeWAV(sementara)
+	if err != nil || size == 0 {
+		os.Remove(sementara)
+		a.gagalSesi(ctx, job, sesi, "rekaman terputus karena agen restart")
+		return
+	}
+	if err := os.Rename(sementara, a.pathParsial(job, sesi)); err != nil {
+		a.gagalSesi(ctx, job, sesi, fmt.Sprintf("gagal menyimpan rekaman lokal: %v", err))
+		return
+	}
+	a.antrekanUnggah()
+}
+
+func (a *Agent) gagalSesi(ctx context.Context, job *models.RekamanJob, sesi *models.RekamanSesi, pesan string) {
+	log.Printf("Sesi %d job %s gagal: %s", sesi.NomorSesi, job.ID, pesan)
+	if err := a.client.UpdateSesi(ctx, job.ID.String(), sesi.NomorSesi, models.SesiStatusFailed, pesan); err != nil {
+		log.Printf("Gagal melaporkan sesi %d job %s: %v", sesi.NomorSesi, job.ID, err)
+	}
+}
+
+// unggahSekali melanjutkan upload dari offset yang sudah diterima server
+func (a *Agent) unggahSekali(ctx context.Context, jobID string, nomor int, path string, parsial bool) error {
+	offset, selesai, err := a.client.OffsetUpload(ctx, jobID, nomor)
+	if err != nil || selesai {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	total := info.Size()
+	if total == 0 {
+		return fmt.Errorf("file rekaman %s kosong", path)
+	}
+
+	buf := make([]byte, a.cfg.ChunkSize)
+	for {
+		if offset > total {
+			return fmt.Errorf("server menerima %d byte, lebih dari ukuran file %d", offset, total)
+		}
+		n, err := file.ReadAt(buf, offset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("tidak ada data tersisa di offset %d", offset)
+		}
+
+		offset, selesai, err = a.client.UploadChunk(ctx, jobID, nomor, offset, buf[:n], total, parsial)
+		if err != nil {
+			var httpErr *HTTPError
+			if errors.As(err, &httpErr) && httpErr.Offset != nil {
+				// Server punya offset lain (chunk sebelumnya ternyata diterima)
+				offset = *httpErr.Offset
+				continue
+			}
+			return err
+		}
+		if selesai {
+			return nil
+		}
+	}
+}
+
+// selesai menyerahkan job ke server, diulang jika koneksi putus
+func (a *Agent) selesai(ctx context.Context, job *models.RekamanJob, pesan string) {
+	for percobaan := 1; ; percobaan++ {
+		err := a.client.Selesai(ctx, job.ID.String(), pesan)
+		if err == nil {
+			log.Printf("Job %s diserahkan ke server untuk dianalisis", job.ID)
+			return
+		}
+		if Permanen(err) {
+			log.Printf("Job %s tidak diterima server: %v", job.ID, err)
+			return
+		}
+		log.Printf("Gagal menyerahkan job %s (percobaan %d): %v", job.ID, percobaan, err)
+		if !tunggu(ctx, backoff(percobaan)) {
+			return
+		}
+	}
+}
+
+// backoff menghitung jeda percobaan ulang: 1, 2, 4, ... detik, maksimal 1 menit
+func backoff(percobaan int) time.Duration {
+	if percobaan > 6 {
+		return time.Minute
+	}
+	return time.Duration(1<<(percobaan-1)) * time.Second
+}
+
+// tunggu menunggu selama d. Bernilai false jika ctx dibatalkan lebih dulu.
+func tunggu(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
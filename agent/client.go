@@ -0,0 +1,234 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"CLAIRE/models"
+)
+
+// HTTPError adalah response error dari server
+type HTTPError struct {
+	Status int
+	Pesan  string
+	Offset *int64 // diisi server ketika offset upload tidak sesuai
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("server mengembalikan %d: %s", e.Status, e.Pesan)
+}
+
+// Permanen melaporkan error yang tidak akan berhasil jika diulang, misalnya
+// job sudah tidak dimiliki agen atau API key dicabut
+func Permanen(err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	if httpErr.Offset != nil {
+		return false
+	}
+	return httpErr.Status >= 400 && httpErr.Status < 500 && httpErr.Status != http.StatusTooManyRequests
+}
+
+// PaketJob adalah job dari server beserta ruangan dan perangkat perekamnya
+type PaketJob struct {
+	Job     models.RekamanJob `json:"job"`
+	Ruangan *models.Ruangan   `json:"ruangan"`
+}
+
+// InfoRegister adalah data agen yang dikirim saat register
+type InfoRegister struct {
+	Nama     string   `json:"nama"`
+	Hostname string   `json:"hostname"`
+	Versi    string   `json:"versi"`
+	Recorder string   `json:"recorder"`
+	Ruangan  []string `json:"ruangan"`
+}
+
+// HasilRegister adalah response register
+type HasilRegister struct {
+	Agent          models.RecordingAgent `json:"agent"`
+	Ruangan        []models.Ruangan      `json:"ruangan"`
+	RuanganDitolak map[string]string     `json:"ruangan_ditolak"`
+	BatasOffline   int                   `json:"batas_offline"` // detik
+}
+
+// Client memanggil endpoint /api/v1/agent di backend dengan API key agen
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// NewClient membuat Client. serverURL adalah alamat backend, misalnya
+// http://claire.kampus.ac.id:8080
+func NewClient(serverURL string, apiKey string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(serverURL, "/") + "/api/v1/agent",
+		apiKey:  apiKey,
+		// Tanpa timeout global: long-poll dan upload dibatasi lewat context
+		http: &http.Client{},
+	}
+}
+
+func (c *Client) request(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		var data struct {
+			Error  string `json:"error"`
+			Offset *int64 `json:"offset"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&data)
+		if data.Error == "" {
+			data.Error = resp.Status
+		}
+		return nil, &HTTPError{Status: resp.StatusCode, Pesan: data.Error, Offset: data.Offset}
+	}
+	return resp, nil
+}
+
+// kirimJSON mengirim body JSON dan membaca response JSON ke hasil jika tidak nil
+func (c *Client) kirimJSON(ctx context.Context, method, path string, input interface{}, hasil interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	resp, err := c.request(ctx, method, path, bytes.NewReader(data), header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if hasil == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(hasil)
+}
+
+// Register mendaftarkan agen dan ruangan yang dilayaninya
+func (c *Client) Register(ctx context.Context, info InfoRegister) (*HasilRegister, error) {
+	var hasil HasilRegister
+	if err := c.kirimJSON(ctx, http.MethodPost, "/register", info, &hasil); err != nil {
+		return nil, err
+	}
+	return &hasil, nil
+}
+
+// Heartbeat melaporkan job yang sedang dikerjakan dan mengembalikan job
+// yang harus dihentikan
+func (c *Client) Heartbeat(ctx context.Context, jobAktif []string) ([]string, error) {
+	var hasil struct {
+		Hentikan []string `json:"hentikan"`
+	}
+	input := map[string]interface{}{"job_aktif": jobAktif}
+	if err := c.kirimJSON(ctx, http.MethodPost, "/heartbeat", input, &hasil); err != nil {
+		return nil, err
+	}
+	return hasil.Hentikan, nil
+}
+
+// JobBerikutnya mengambil job dari server, menunggu paling lama wait.
+// Mengembalikan nil jika tidak ada job.
+func (c *Client) JobBerikutnya(ctx context.Context, jobAktif []string, wait time.Duration) (*PaketJob, error) {
+	query := url.Values{}
+	query.Set("wait", strconv.Itoa(int(wait.Seconds())))
+	if len(jobAktif) > 0 {
+		query.Set("aktif", strings.Join(jobAktif, ","))
+	}
+
+	resp, err := c.request(ctx, http.MethodGet, "/jobs/next?"+query.Encode(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	var paket PaketJob
+	if err := json.NewDecoder(resp.Body).Decode(&paket); err != nil {
+		return nil, err
+	}
+	return &paket, nil
+}
+
+// UpdateSesi melaporkan status sesi: models.SesiStatusRecording saat mulai
+// merekam atau models.SesiStatusFailed beserta pesan error
+func (c *Client) UpdateSesi(ctx context.Context, jobID string, nomor int, status string, pesan string) error {
+	path := fmt.Sprintf("/jobs/%s/sesi/%d/status", jobID, nomor)
+	return c.kirimJSON(ctx, http.MethodPost, path, map[string]interface{}{"status": status, "pesan": pesan}, nil)
+}
+
+// OffsetUpload menanyakan jumlah byte rekaman sesi yang sudah diterima
+func (c *Client) OffsetUpload(ctx context.Context, jobID string, nomor int) (offset int64, selesai bool, err error) {
+	path := fmt.Sprintf("/jobs/%s/sesi/%d/audio", jobID, nomor)
+	resp, err := c.request(ctx, http.MethodHead, path, nil, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	resp.Body.Close()
+
+	offset, err = strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("header Upload-Offset tidak valid: %v", err)
+	}
+	return offset, resp.Header.Get("Upload-Complete") == "true", nil
+}
+
+// UploadChunk mengirim data sebagai byte mulai..mulai+len(data)-1 dari file
+// berukuran total. Mengembalikan offset yang sudah diterima server.
+func (c *Client) UploadChunk(ctx context.Context, jobID string, nomor int, mulai int64, data []byte, total int64, parsial bool) (offset int64, selesai bool, err error) {
+	path := fmt.Sprintf("/jobs/%s/sesi/%d/audio", jobID, nomor)
+	if parsial {
+		path += "?parsial=true"
+	}
+	header := http.Header{
+		"Content-Type":  []string{"application/octet-stream"},
+		"Content-Range": []string{fmt.Sprintf("bytes %d-%d/%d", mulai, mulai+int64(len(data))-1, total)},
+	}
+	resp, err := c.request(ctx, http.MethodPut, path, bytes.NewReader(data), header)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	var hasil struct {
+		Offset  int64 `json:"offset"`
+		Selesai bool  `json:"selesai"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&hasil); err != nil {
+		return 0, false, err
+	}
+	return hasil.Offset, hasil.Selesai, nil
+}
+
+// Selesai menyerahkan job ke server untuk dianalisis. pesan diisi jika job
+// berakhir karena error di agen.
+func (c *Client) Selesai(ctx context.Context, jobID string, pesan string) error {
+	return c.kirimJSON(ctx, http.MethodPost, "/jobs/"+jobID+"/selesai", map[string]interface{}{"pesan": pesan}, nil)
+}
@@ -0,0 +1,199 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"CLAIRE/models"
+)
+
+// Antrian upload agen. Rekaman sesi yang selesai disimpan sebagai file .wav
+// di cfg.Dir dan diunggah di latar belakang, sehingga sesi berikutnya tetap
+// direkam sesuai jadwal walaupun koneksi ke server lambat atau putus.
+// Antrian dibaca ulang dari direktori, jadi file yang belum terkirim saat
+// agen berhenti dilanjutkan pada start berikutnya.
+
+// Jarak pemeriksaan ulang antrian upload ketika tidak ada sinyal baru
+const intervalAntrianUnggah = time.Minute
+
+// Akhiran file yang ditolak server; disimpan untuk dipulihkan manual
+const akhiranDitolak = ".ditolak"
+
+// polaBerkasUnggah mencocokkan nama file rekaman yang siap diunggah
+var polaBerkasUnggah = regexp.MustCompile(`^job_([0-9a-f-]{36})_sesi(\d+)(_parsial)?\.wav$`)
+
+// berkasUnggah adalah satu file rekaman di antrian upload
+type berkasUnggah struct {
+	path    string
+	jobID   string
+	nomor   int
+	parsial bool
+}
+
+// pathParsial adalah nama file rekaman yang dihentikan sebelum durasi sesi
+// selesai. Tanda parsial disimpan di nama file supaya tetap terbawa setelah
+// agen restart.
+func (a *Agent) pathParsial(job *models.RekamanJob, sesi *models.RekamanSesi) string {
+	return filepath.Join(a.cfg.Dir, fmt.Sprintf("job_%s_sesi%d_parsial.wav", job.ID, sesi.NomorSesi))
+}
+
+// daftarUnggah membaca antrian upload dari direktori rekaman, urut menurut
+// job dan nomor sesi
+func (a *Agent) daftarUnggah() ([]berkasUnggah, error) {
+	entries, err := os.ReadDir(a.cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var antrian []berkasUnggah
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		cocok := polaBerkasUnggah.FindStringSubmatch(entry.Name())
+		if cocok == nil {
+			continue
+		}
+		nomor, err := strconv.Atoi(cocok[2])
+		if err != nil {
+			continue
+		}
+		antrian = append(antrian, berkasUnggah{
+			path:    filepath.Join(a.cfg.Dir, entry.Name()),
+			jobID:   cocok[1],
+			nomor:   nomor,
+			parsial: cocok[3] != "",
+		})
+	}
+	sort.Slice(antrian, func(i, j int) bool {
+		if antrian[i].jobID != antrian[j].jobID {
+			return antrian[i].jobID < antrian[j].jobID
+		}
+		return antrian[i].nomor < antrian[j].nomor
+	})
+	return antrian, nil
+}
+
+// antrekanUnggah membangunkan pengunggah setelah file rekaman baru disimpan
+func (a *Agent) antrekanUnggah() {
+	select {
+	case a.sinyalUnggah <- struct{}{}:
+	default:
+	}
+}
+
+// kabariUnggah memberi tahu yang menunggu bahwa isi antrian berubah
+func (a *Agent) kabariUnggah() {
+	a.mu.Lock()
+	close(a.unggahBerubah)
+	a.unggahBerubah = make(chan struct{})
+	a.mu.Unlock()
+}
+
+func (a *Agent) unggahBerubahSaatIni() <-chan struct{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.unggahBerubah
+}
+
+// pengunggah mengirim isi antrian upload satu per satu sampai ctx dibatalkan.
+// Upload yang terputus diulang dengan backoff.
+func (a *Agent) pengunggah(ctx context.Context) {
+	defer a.wg.Done()
+
+	gagal := 0
+	for ctx.Err() == nil {
+		antrian, err := a.daftarUnggah()
+		if err != nil {
+			log.Printf("Gagal membaca antrian upload: %v", err)
+		}
+
+		tertunda := err != nil
+		for _, berkas := range antrian {
+			if ctx.Err() != nil {
+				return
+			}
+			if !a.unggah(ctx, berkas) {
+				tertunda = true
+			}
+			a.kabariUnggah()
+		}
+
+		jeda := intervalAntrianUnggah
+		if tertunda {
+			gagal++
+			jeda = backoff(gagal)
+		} else {
+			gagal = 0
+		}
+
+		timer := time.NewTimer(jeda)
+		select {
+		case <-ctx.Done():
+		case <-a.sinyalUnggah:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// unggah mengirim satu file rekaman. File lokal dihapus setelah server
+// menerimanya, atau diberi akhiran .ditolak jika server menolaknya. Bernilai
+// false jika upload perlu diulang.
+func (a *Agent) unggah(ctx context.Context, berkas berkasUnggah) bool {
+	err := a.unggahSekali(ctx, berkas.jobID, berkas.nomor, berkas.path, berkas.parsial)
+	if err == nil {
+		os.Remove(berkas.path)
+		log.Printf("Rekaman sesi %d job %s terkirim", berkas.nomor, berkas.jobID)
+		return true
+	}
+	if Permanen(err) {
+		// File lokal disimpan supaya bisa dipulihkan manual
+		ditolak := berkas.path + akhiranDitolak
+		if rerr := os.Rename(berkas.path, ditolak); rerr != nil {
+			log.Printf("Gagal memindahkan %s: %v", berkas.path, rerr)
+			return false
+		}
+		log.Printf("Upload sesi %d job %s ditolak, file tetap di %s: %v", berkas.nomor, berkas.jobID, ditolak, err)
+		return true
+	}
+	if ctx.Err() == nil {
+		log.Printf("Upload sesi %d job %s terputus: %v", berkas.nomor, berkas.jobID, err)
+	}
+	return false
+}
+
+// tungguUnggah menunggu sampai semua rekaman job keluar dari antrian upload.
+// Bernilai false jika ctx dibatalkan lebih dulu.
+func (a *Agent) tungguUnggah(ctx context.Context, jobID string) bool {
+	for {
+		berubah := a.unggahBerubahSaatIni()
+		antrian, err := a.daftarUnggah()
+		if err == nil {
+			ada := false
+			for _, berkas := range antrian {
+				if berkas.jobID == jobID {
+					ada = true
+					break
+				}
+			}
+			if !ada {
+				return true
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-berubah:
+		case <-time.After(intervalAntrianUnggah):
+		}
+	}
+}
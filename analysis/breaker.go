@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen dikembalikan ketika layanan analisis dianggap down dan
+// request tidak dikirim sama sekali
+var ErrCircuitOpen = errors.New("layanan analisis tidak tersedia (circuit breaker terbuka)")
+
+// Status circuit breaker
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// CircuitBreaker membuka sirkuit setelah threshold kegagalan berturut-turut,
+// lalu mengizinkan satu request percobaan setelah cooldown berlalu
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	state     string
+	openedAt  time.Time
+	now       func() time.Time
+}
+
+// NewCircuitBreaker membuat CircuitBreaker. Threshold <= 0 menonaktifkan breaker.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow melaporkan apakah request boleh dikirim
+func (b *CircuitBreaker) Allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		return true
+	case BreakerHalfOpen:
+		// Hanya satu request percobaan yang boleh berjalan
+		return false
+	default:
+		return true
+	}
+}
+
+// Success mencatat request yang berhasil dan menutup sirkuit
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.state = BreakerClosed
+}
+
+// Failure mencatat request yang gagal
+func (b *CircuitBreaker) Failure() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// State mengembalikan status breaker saat ini
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,41 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerHalfOpenSatuPercobaan(t *testing.T) {
+	sekarang := time.Now()
+	b := NewCircuitBreaker(1, time.Second)
+	b.now = func() time.Time { return sekarang }
+
+	b.Failure()
+	if b.Allow() {
+		t.Fatal("Allow saat sirkuit terbuka = true, want false")
+	}
+
+	sekarang = sekarang.Add(time.Second)
+	if !b.Allow() {
+		t.Fatal("Allow setelah cooldown = false, want true")
+	}
+	// Percobaan pertama belum selesai, request lain ditahan
+	if b.Allow() {
+		t.Error("Allow kedua saat half-open = true, want false")
+	}
+
+	b.Success()
+	if !b.Allow() || b.State() != BreakerClosed {
+		t.Errorf("setelah Success: State = %q, want %q", b.State(), BreakerClosed)
+	}
+}
+
+func TestCircuitBreakerNonaktif(t *testing.T) {
+	b := NewCircuitBreaker(0, time.Hour)
+	for i := 0; i < 10; i++ {
+		b.Failure()
+	}
+	if !b.Allow() || b.State() != BreakerClosed {
+		t.Errorf("breaker dengan threshold 0: State = %q, want %q", b.State(), BreakerClosed)
+	}
+}
@@ -0,0 +1,231 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"CLAIRE/config"
+)
+
+// UploadPath adalah endpoint layanan analisis untuk menerima audio WAV
+const UploadPath = "/upload-audio"
+
+// Struct untuk response analisis dari Python backend
+type AudioAnalysisResponse struct {
+	Analysis struct {
+		ClarityScore  float64  `json:"clarity_score"`
+		Effectiveness int      `json:"effectiveness"`
+		Feedback      string   `json:"feedback"`
+		IsEffective   bool     `json:"is_effective"`
+		Redundancy    float64  `json:"redundancy"`
+		Summary       string   `json:"summary"`
+		TopicFocus    []string `json:"topic_focus"`
+	} `json:"analysis"`
+	Similarity float64 `json:"similarity"`
+	Speaker    string  `json:"speaker"`
+	Status     string  `json:"status"`
+	Timestamp  string  `json:"timestamp"`
+	Transcript string  `json:"transcript"`
+}
+
+// AnalysisClient mengirim audio ke layanan analisis
+type AnalysisClient interface {
+	// Analyze mengirim file WAV di audioPath dan mengembalikan hasil analisis
+	Analyze(ctx context.Context, audioPath string) (*AudioAnalysisResponse, error)
+	// Ping memeriksa apakah layanan analisis dapat dijangkau
+	Ping(ctx context.Context) error
+}
+
+// Options berisi pengaturan HTTPClient
+type Options struct {
+	BaseURL          string
+	Timeout          time.Duration
+	AuthHeader       string
+	AuthToken        string
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// OptionsFromConfig membaca Options dari config.Config
+func OptionsFromConfig(cfg *config.Config) Options {
+	return Options{
+		BaseURL:          cfg.AnalysisBaseURL,
+		Timeout:          cfg.AnalysisTimeout,
+		AuthHeader:       cfg.AnalysisAuthHeader,
+		AuthToken:        cfg.AnalysisAuthToken,
+		MaxRetries:       cfg.AnalysisMaxRetries,
+		RetryBackoff:     cfg.AnalysisRetryBackoff,
+		BreakerThreshold: cfg.AnalysisBreakerThreshold,
+		BreakerCooldown:  cfg.AnalysisBreakerCooldown,
+	}
+}
+
+// HTTPClient adalah AnalysisClient yang berbicara dengan Python backend
+// melalui HTTP, dengan retry, backoff dan circuit breaker
+type HTTPClient struct {
+	opts    Options
+	http    *http.Client
+	breaker *CircuitBreaker
+}
+
+// NewHTTPClient membuat HTTPClient dari Options
+func NewHTTPClient(opts Options) *HTTPClient {
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = time.Second
+	}
+
+	return &HTTPClient{
+		opts:    opts,
+		http:    &http.Client{Timeout: opts.Timeout},
+		breaker: NewCircuitBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+	}
+}
+
+// NewFromConfig membuat HTTPClient dari config.Config
+func NewFromConfig(cfg *config.Config) *HTTPClient {
+	return NewHTTPClient(OptionsFromConfig(cfg))
+}
+
+// errPermanent menandai error yang tidak perlu di-retry (misalnya 4xx)
+type errPermanent struct {
+	err error
+}
+
+func (e errPermanent) Error() string { return e.err.Error() }
+func (e errPermanent) Unwrap() error { return e.err }
+
+func (c *HTTPClient) Analyze(ctx context.Context, audioPath string) (*AudioAnalysisResponse, error) {
+	// Baca file audio
+	audioData, err := os.ReadFile(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading audio file: %v", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			// Exponential backoff: backoff, 2x backoff, 4x backoff, ...
+			wait := c.opts.RetryBackoff << (attempt - 1)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		if !c.breaker.Allow() {
+			return nil, ErrCircuitOpen
+		}
+
+		resp, err := c.upload(ctx, audioData)
+		if err == nil {
+			c.breaker.Success()
+			return resp, nil
+		}
+
+		var permanent errPermanent
+		if errors.As(err, &permanent) {
+			// Layanan merespons tapi menolak request: bukan tanda layanan down
+			c.breaker.Success()
+			return nil, permanent.err
+		}
+
+		c.breaker.Failure()
+		lastErr = err
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, fmt.Errorf("analisis gagal setelah %d percobaan: %v", c.opts.MaxRetries+1, lastErr)
+}
+
+func (c *HTTPClient) upload(ctx context.Context, audioData []byte) (*AudioAnalysisResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url(UploadPath), bytes.NewReader(audioData))
+	if err != nil {
+		return nil, errPermanent{fmt.Errorf("error creating request: %v", err)}
+	}
+
+	req.Header.Set("Content-Type", "audio/wav")
+	c.authorize(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		statusErr := fmt.Errorf("server returned non-200 status: %d", resp.StatusCode)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return nil, statusErr
+		}
+		return nil, errPermanent{statusErr}
+	}
+
+	var analysisResp AudioAnalysisResponse
+	if err := json.NewDecoder(resp.Body).Decode(&analysisResp); err != nil {
+		return nil, errPermanent{fmt.Errorf("error decoding response: %v", err)}
+	}
+
+	return &analysisResp, nil
+}
+
+func (c *HTTPClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url("/"), nil)
+	if err != nil {
+		return err
+	}
+	c.authorize(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("server returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// BreakerState mengembalikan status circuit breaker saat ini
+func (c *HTTPClient) BreakerState() string {
+	return c.breaker.State()
+}
+
+func (c *HTTPClient) url(path string) string {
+	return strings.TrimRight(c.opts.BaseURL, "/") + path
+}
+
+func (c *HTTPClient) authorize(req *http.Request) {
+	if c.opts.AuthToken == "" {
+		return
+	}
+
+	header := c.opts.AuthHeader
+	if header == "" {
+		header = "Authorization"
+	}
+
+	value := c.opts.AuthToken
+	if strings.EqualFold(header, "Authorization") && !strings.Contains(value, " ") {
+		value = "Bearer " + value
+	}
+	req.Header.Set(header, value)
+}
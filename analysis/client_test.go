@@ -0,0 +1,240 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"CLAIRE/recorder"
+)
+
+// layananUji membungkus StubServer: n request pertama dijawab dengan status
+// gagal, dan waktu setiap request dicatat
+type layananUji struct {
+	stub *StubServer
+
+	mu     sync.Mutex
+	gagal  int
+	status int
+	waktu  []time.Time
+}
+
+func (l *layananUji) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	l.waktu = append(l.waktu, time.Now())
+	gagal := l.gagal > 0
+	if gagal {
+		l.gagal--
+	}
+	l.mu.Unlock()
+
+	if gagal {
+		writeJSON(w, l.status, map[string]string{"error": "gangguan"})
+		return
+	}
+	l.stub.ServeHTTP(w, r)
+}
+
+// setelGagal membuat n request berikutnya dijawab dengan status
+func (l *layananUji) setelGagal(n int, status int) {
+	l.mu.Lock()
+	l.gagal = n
+	l.status = status
+	l.mu.Unlock()
+}
+
+func (l *layananUji) jumlahRequest() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.waktu)
+}
+
+// klienUji menjalankan layanan di httptest dan membuat HTTPClient yang
+// mengarah ke sana
+func klienUji(t *testing.T, layanan http.Handler, opts Options) *HTTPClient {
+	t.Helper()
+	server := httptest.NewServer(layanan)
+	t.Cleanup(server.Close)
+
+	opts.BaseURL = server.URL
+	if opts.Timeout == 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	return NewHTTPClient(opts)
+}
+
+// audioUji menulis rekaman hening 10 ms yang diterima StubServer
+func audioUji(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audio.wav")
+	if err := recorder.WriteWAV(path, make([]byte, 320)); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAnalyzeStubServer(t *testing.T) {
+	layanan := &layananUji{stub: NewStubServer(nil)}
+	client := klienUji(t, layanan, Options{AuthToken: "rahasia"})
+	layanan.stub.AuthToken = "rahasia"
+	audio := audioUji(t)
+
+	canned := CannedResponses()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Analyze(context.Background(), audio)
+		if err != nil {
+			t.Fatalf("Analyze #%d: %v", i, err)
+		}
+		want := canned[i%len(canned)]
+		if resp.Analysis.Effectiveness != want.Analysis.Effectiveness || resp.Transcript != want.Transcript {
+			t.Errorf("Analyze #%d = %+v, want response kalengan ke-%d", i, resp.Analysis, i%len(canned))
+		}
+	}
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+}
+
+func TestAnalyzeRetryDenganBackoff(t *testing.T) {
+	backoff := 40 * time.Millisecond
+	layanan := &layananUji{stub: NewStubServer(nil)}
+	client := klienUji(t, layanan, Options{MaxRetries: 3, RetryBackoff: backoff})
+	layanan.setelGagal(2, http.StatusServiceUnavailable)
+
+	resp, err := client.Analyze(context.Background(), audioUji(t))
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if resp.Status != "success" {
+		t.Errorf("Status = %q, want success", resp.Status)
+	}
+	if n := layanan.jumlahRequest(); n != 3 {
+		t.Fatalf("jumlah request = %d, want 3", n)
+	}
+
+	// Jeda antar percobaan berlipat dua: backoff, lalu 2x backoff
+	for i, minimal := range []time.Duration{backoff, 2 * backoff} {
+		if jeda := layanan.waktu[i+1].Sub(layanan.waktu[i]); jeda < minimal {
+			t.Errorf("jeda sebelum percobaan %d = %v, want >= %v", i+2, jeda, minimal)
+		}
+	}
+	if state := client.BreakerState(); state != BreakerClosed {
+		t.Errorf("BreakerState = %q, want %q", state, BreakerClosed)
+	}
+}
+
+func TestAnalyzeRetryHabis(t *testing.T) {
+	layanan := &layananUji{stub: NewStubServer(nil)}
+	client := klienUji(t, layanan, Options{MaxRetries: 2, RetryBackoff: time.Millisecond})
+	layanan.setelGagal(10, http.StatusTooManyRequests)
+
+	if _, err := client.Analyze(context.Background(), audioUji(t)); err == nil {
+		t.Fatal("Analyze harus gagal setelah semua percobaan habis")
+	}
+	if n := layanan.jumlahRequest(); n != 3 {
+		t.Errorf("jumlah request = %d, want 3", n)
+	}
+}
+
+func TestAnalyzeTidakRetryErrorPermanen(t *testing.T) {
+	layanan := &layananUji{stub: NewStubServer(nil)}
+	client := klienUji(t, layanan, Options{MaxRetries: 3, RetryBackoff: time.Millisecond, BreakerThreshold: 1, BreakerCooldown: time.Hour})
+
+	// StubServer menolak body yang bukan WAV dengan 400
+	bukanWAV := filepath.Join(t.TempDir(), "audio.txt")
+	os.WriteFile(bukanWAV, []byte("bukan audio"), 0644)
+
+	if _, err := client.Analyze(context.Background(), bukanWAV); err == nil {
+		t.Fatal("Analyze dengan body bukan WAV harus gagal")
+	}
+	if n := layanan.jumlahRequest(); n != 1 {
+		t.Errorf("jumlah request = %d, want 1 (error 4xx tidak di-retry)", n)
+	}
+	if state := client.BreakerState(); state != BreakerClosed {
+		t.Errorf("BreakerState = %q, want %q", state, BreakerClosed)
+	}
+}
+
+func TestAnalyzeBackoffDibatalkan(t *testing.T) {
+	layanan := &layananUji{stub: NewStubServer(nil)}
+	client := klienUji(t, layanan, Options{MaxRetries: 3, RetryBackoff: time.Hour})
+	layanan.setelGagal(10, http.StatusBadGateway)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	mulai := time.Now()
+	_, err := client.Analyze(ctx, audioUji(t))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Analyze = %v, want context.DeadlineExceeded", err)
+	}
+	if lama := time.Since(mulai); lama > 5*time.Second {
+		t.Errorf("Analyze berhenti setelah %v, want segera setelah ctx selesai", lama)
+	}
+	if n := layanan.jumlahRequest(); n != 1 {
+		t.Errorf("jumlah request = %d, want 1", n)
+	}
+}
+
+func TestAnalyzeCircuitBreaker(t *testing.T) {
+	layanan := &layananUji{stub: NewStubServer(nil)}
+	client := klienUji(t, layanan, Options{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+	audio := audioUji(t)
+
+	sekarang := time.Now()
+	client.breaker.now = func() time.Time { return sekarang }
+
+	// Dua kegagalan berturut-turut membuka sirkuit
+	layanan.setelGagal(2, http.StatusInternalServerError)
+	for i := 0; i < 2; i++ {
+		if _, err := client.Analyze(context.Background(), audio); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Analyze #%d = %v, want error dari layanan", i, err)
+		}
+	}
+	if state := client.BreakerState(); state != BreakerOpen {
+		t.Fatalf("BreakerState = %q, want %q", state, BreakerOpen)
+	}
+
+	// Selama sirkuit terbuka request tidak dikirim
+	if _, err := client.Analyze(context.Background(), audio); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Analyze = %v, want ErrCircuitOpen", err)
+	}
+	if n := layanan.jumlahRequest(); n != 2 {
+		t.Fatalf("jumlah request = %d, want 2", n)
+	}
+
+	// Setelah cooldown satu percobaan dikirim; jika gagal sirkuit terbuka lagi
+	sekarang = sekarang.Add(time.Minute)
+	if state := client.BreakerState(); state != BreakerHalfOpen {
+		t.Fatalf("BreakerState = %q, want %q", state, BreakerHalfOpen)
+	}
+	layanan.setelGagal(1, http.StatusServiceUnavailable)
+	if _, err := client.Analyze(context.Background(), audio); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("percobaan half-open = %v, want error dari layanan", err)
+	}
+	if state := client.BreakerState(); state != BreakerOpen {
+		t.Fatalf("BreakerState = %q, want %q", state, BreakerOpen)
+	}
+	if _, err := client.Analyze(context.Background(), audio); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Analyze = %v, want ErrCircuitOpen", err)
+	}
+
+	// Percobaan half-open yang berhasil menutup sirkuit
+	sekarang = sekarang.Add(time.Minute)
+	if _, err := client.Analyze(context.Background(), audio); err != nil {
+		t.Fatalf("percobaan half-open: %v", err)
+	}
+	if state := client.BreakerState(); state != BreakerClosed {
+		t.Errorf("BreakerState = %q, want %q", state, BreakerClosed)
+	}
+	if n := layanan.jumlahRequest(); n != 4 {
+		t.Errorf("jumlah request = %d, want 4", n)
+	}
+}
@@ -0,0 +1,39 @@
+package analysis
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeClient adalah AnalysisClient palsu untuk pengujian handler. Setiap
+// panggilan Analyze dicatat dan mengembalikan Response atau Err.
+type FakeClient struct {
+	mu       sync.Mutex
+	Response *AudioAnalysisResponse
+	Err      error
+	PingErr  error
+	Calls    []string
+}
+
+// NewFakeClient membuat FakeClient yang selalu mengembalikan hasil kalengan
+func NewFakeClient() *FakeClient {
+	responses := CannedResponses()
+	return &FakeClient{Response: &responses[0]}
+}
+
+func (f *FakeClient) Analyze(ctx context.Context, audioPath string) (*AudioAnalysisResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Calls = append(f.Calls, audioPath)
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	resp := *f.Response
+	return &resp, nil
+}
+
+func (f *FakeClient) Ping(ctx context.Context) error {
+	return f.PingErr
+}
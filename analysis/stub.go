@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CannedResponses mengembalikan contoh hasil analisis yang dipakai oleh
+// FakeClient dan stub server
+func CannedResponses() []AudioAnalysisResponse {
+	var efektif AudioAnalysisResponse
+	efektif.Analysis.ClarityScore = 0.87
+	efektif.Analysis.Effectiveness = 82
+	efektif.Analysis.Feedback = "Penjelasan runtut dan jelas, contoh sudah relevan."
+	efektif.Analysis.IsEffective = true
+	efektif.Analysis.Redundancy = 0.12
+	efektif.Analysis.Summary = "Dosen menjelaskan konsep dasar algoritma pengurutan beserta contohnya."
+	efektif.Analysis.TopicFocus = []string{"algoritma", "pengurutan"}
+	efektif.Similarity = 0.91
+	efektif.Speaker = "dosen"
+	efektif.Status = "success"
+	efektif.Transcript = "Baik, hari ini kita membahas algoritma pengurutan."
+
+	var kurangEfektif AudioAnalysisResponse
+	kurangEfektif.Analysis.ClarityScore = 0.54
+	kurangEfektif.Analysis.Effectiveness = 48
+	kurangEfektif.Analysis.Feedback = "Banyak pengulangan, fokus topik kurang terjaga."
+	kurangEfektif.Analysis.IsEffective = false
+	kurangEfektif.Analysis.Redundancy = 0.41
+	kurangEfektif.Analysis.Summary = "Pembahasan melebar ke beberapa topik tanpa kesimpulan."
+	kurangEfektif.Analysis.TopicFocus = []string{"basis data"}
+	kurangEfektif.Similarity = 0.63
+	kurangEfektif.Speaker = "dosen"
+	kurangEfektif.Status = "success"
+	kurangEfektif.Transcript = "Jadi seperti yang tadi saya bilang, seperti yang tadi..."
+
+	return []AudioAnalysisResponse{efektif, kurangEfektif}
+}
+
+// StubServer adalah pengganti layanan analisis Python untuk pengujian
+// end-to-end tanpa jaringan. Response kalengan dikembalikan bergiliran.
+type StubServer struct {
+	mu        sync.Mutex
+	responses []AudioAnalysisResponse
+	next      int
+	AuthToken string
+}
+
+// NewStubServer membuat StubServer. Jika responses kosong, CannedResponses dipakai.
+func NewStubServer(responses []AudioAnalysisResponse) *StubServer {
+	if len(responses) == 0 {
+		responses = CannedResponses()
+	}
+	return &StubServer{responses: responses}
+}
+
+func (s *StubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == UploadPath && r.Method == http.MethodPost:
+		s.handleUpload(w, r)
+	case r.URL.Path == "/" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "service": "analysis-stub"})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+}
+
+func (s *StubServer) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if s.AuthToken != "" && !strings.HasSuffix(r.Header.Get("Authorization"), s.AuthToken) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil || len(body) < 12 || string(body[0:4]) != "RIFF" || string(body[8:12]) != "WAVE" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "body harus berupa file WAV"})
+		return
+	}
+
+	s.mu.Lock()
+	resp := s.responses[s.next%len(s.responses)]
+	s.next++
+	s.mu.Unlock()
+
+	resp.Timestamp = time.Now().Format(time.RFC3339)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,83 @@
+// Package audio mengenali format file audio dari isinya dan menormalkan
+// audio ke format yang dipakai perekam dan layanan analisis: WAV PCM 16-bit,
+// 16 kHz, mono.
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrBukanAudio dikembalikan ketika isi file tidak dikenali sebagai audio
+// atau tidak bisa didekode
+var ErrBukanAudio = errors.New("file bukan audio yang didukung")
+
+// Format audio yang dikenali dari magic byte
+const (
+	FormatWAV  = "wav"
+	FormatWebM = "webm" // juga Matroska (.mka)
+	FormatOgg  = "ogg"  // Vorbis dan Opus
+	FormatFLAC = "flac"
+	FormatMP3  = "mp3"
+	FormatAAC  = "aac" // ADTS tanpa container
+	FormatMP4  = "mp4" // .m4a dan container ISO BMFF lainnya
+)
+
+// SemuaFormat berisi semua format yang dikenali Deteksi
+var SemuaFormat = []string{FormatWAV, FormatWebM, FormatOgg, FormatFLAC, FormatMP3, FormatAAC, FormatMP4}
+
+// PanjangSniff adalah jumlah byte awal yang cukup untuk Deteksi
+const PanjangSniff = 512
+
+// Ekstensi mengembalikan ekstensi file untuk format, misalnya ".webm"
+func Ekstensi(format string) string {
+	if format == FormatMP4 {
+		return ".m4a"
+	}
+	return "." + format
+}
+
+// Deteksi mengenali format audio dari byte awal file. Ekstensi nama file
+// tidak dipakai karena browser sering mengirim WebM dengan nama .wav.
+func Deteksi(header []byte) (string, error) {
+	switch {
+	case len(header) >= 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		return FormatWAV, nil
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return FormatWebM, nil
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return FormatOgg, nil
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return FormatFLAC, nil
+	case bytes.HasPrefix(header, []byte("ID3")):
+		// Tag ID3v2 hampir selalu diikuti frame MP3
+		return FormatMP3, nil
+	case len(header) >= 12 && string(header[4:8]) == "ftyp":
+		return FormatMP4, nil
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		// Frame sync MPEG: layer 00 dipakai ADTS (AAC), selain itu MP3
+		if header[1]&0x06 == 0 {
+			return FormatAAC, nil
+		}
+		return FormatMP3, nil
+	}
+	return "", ErrBukanAudio
+}
+
+// DeteksiReader membaca byte awal r untuk Deteksi lalu mengembalikan reader
+// yang tetap berisi seluruh isi r dari awal
+func DeteksiReader(r io.Reader) (string, io.Reader, error) {
+	header := make([]byte, PanjangSniff)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", nil, err
+	}
+	header = header[:n]
+
+	format, err := Deteksi(header)
+	if err != nil {
+		return "", nil, err
+	}
+	return format, io.MultiReader(bytes.NewReader(header), r), nil
+}
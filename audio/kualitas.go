@@ -0,0 +1,150 @@
+package audio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Nama cek kualitas sampel suara
+const (
+	CekAnalisis     = "analisis"
+	CekSampleRate   = "sample_rate"
+	CekDurasiBicara = "durasi_bicara"
+	CekRasioHening  = "rasio_hening"
+	CekClipping     = "clipping"
+)
+
+// SyaratKualitas berisi batas kualitas sampel suara untuk pendaftaran dosen.
+// Sampel yang terlalu pendek, hening, clipping, atau direkam dengan sample
+// rate rendah menurunkan akurasi pengenalan pembicara.
+type SyaratKualitas struct {
+	DurasiBicaraMin   float64 `json:"durasi_bicara_min"`   // detik audio yang tidak hening
+	RasioHeningMaks   float64 `json:"rasio_hening_maks"`   // 0..1
+	RasioClippingMaks float64 `json:"rasio_clipping_maks"` // 0..1
+	SampleRateMin     int     `json:"sample_rate_min"`     // Hz, sebelum dikonversi
+}
+
+// DefaultSyaratKualitas mengembalikan syarat kualitas bawaan
+func DefaultSyaratKualitas() SyaratKualitas {
+	return SyaratKualitas{
+		DurasiBicaraMin:   5,
+		RasioHeningMaks:   0.6,
+		RasioClippingMaks: 0.01,
+		SampleRateMin:     SampleRate,
+	}
+}
+
+// HasilCek adalah hasil satu cek kualitas
+type HasilCek struct {
+	Nama  string  `json:"nama"`
+	Lolos bool    `json:"lolos"`
+	Nilai float64 `json:"nilai"`
+	Batas float64 `json:"batas"`
+	Pesan string  `json:"pesan"`
+}
+
+// LaporanKualitas berisi hasil semua cek kualitas sampel suara
+type LaporanKualitas struct {
+	Lolos bool       `json:"lolos"`
+	Cek   []HasilCek `json:"cek"`
+}
+
+// Gagal mengembalikan cek yang tidak lolos
+func (laporan LaporanKualitas) Gagal() []HasilCek {
+	var gagal []HasilCek
+	for _, cek := range laporan.Cek {
+		if !cek.Lolos {
+			gagal = append(gagal, cek)
+		}
+	}
+	return gagal
+}
+
+// ErrKualitasSampel dikembalikan ketika sampel suara tidak memenuhi syarat
+// kualitas. Laporan berisi hasil setiap cek untuk ditampilkan ke pengguna.
+type ErrKualitasSampel struct {
+	Laporan LaporanKualitas
+}
+
+func (e *ErrKualitasSampel) Error() string {
+	var pesan []string
+	for _, cek := range e.Laporan.Gagal() {
+		pesan = append(pesan, cek.Pesan)
+	}
+	return "sampel suara tidak memenuhi syarat kualitas: " + strings.Join(pesan, "; ")
+}
+
+// PeriksaKualitas mengecek metadata sampel suara yang sudah dinormalkan
+// terhadap syarat. sampleRateAsli adalah sample rate file sebelum
+// dikonversi; 0 berarti tidak diketahui dan cek sample rate dilewati. meta
+// bernilai nil jika analisis audio gagal.
+func PeriksaKualitas(meta *Metadata, sampleRateAsli int, syarat SyaratKualitas) LaporanKualitas {
+	if meta == nil || meta.RasioHening == nil || meta.RasioClipping == nil {
+		return LaporanKualitas{Cek: []HasilCek{{
+			Nama:  CekAnalisis,
+			Pesan: "Level audio tidak dapat dianalisis",
+		}}}
+	}
+
+	var cek []HasilCek
+
+	sampleRate := HasilCek{
+		Nama:  CekSampleRate,
+		Lolos: true,
+		Nilai: float64(sampleRateAsli),
+		Batas: float64(syarat.SampleRateMin),
+		Pesan: fmt.Sprintf("Sample rate %d Hz", sampleRateAsli),
+	}
+	if sampleRateAsli == 0 {
+		sampleRate.Pesan = "Sample rate rekaman asli tidak diketahui"
+	} else if sampleRateAsli < syarat.SampleRateMin {
+		sampleRate.Lolos = false
+		sampleRate.Pesan = fmt.Sprintf("Sample rate %d Hz di bawah minimal %d Hz, gunakan mikrofon atau pengaturan rekaman yang lebih baik", sampleRateAsli, syarat.SampleRateMin)
+	}
+	cek = append(cek, sampleRate)
+
+	durasiBicara := meta.DurasiDetik * (1 - *meta.RasioHening)
+	durasi := HasilCek{
+		Nama:  CekDurasiBicara,
+		Lolos: durasiBicara >= syarat.DurasiBicaraMin,
+		Nilai: durasiBicara,
+		Batas: syarat.DurasiBicaraMin,
+		Pesan: fmt.Sprintf("Durasi bicara %.1f detik", durasiBicara),
+	}
+	if !durasi.Lolos {
+		durasi.Pesan = fmt.Sprintf("Durasi bicara %.1f detik, minimal %.1f detik; rekam ulang dengan berbicara lebih lama", durasiBicara, syarat.DurasiBicaraMin)
+	}
+	cek = append(cek, durasi)
+
+	hening := HasilCek{
+		Nama:  CekRasioHening,
+		Lolos: *meta.RasioHening <= syarat.RasioHeningMaks,
+		Nilai: *meta.RasioHening,
+		Batas: syarat.RasioHeningMaks,
+		Pesan: fmt.Sprintf("Bagian hening %.0f%%", *meta.RasioHening*100),
+	}
+	if !hening.Lolos {
+		hening.Pesan = fmt.Sprintf("Bagian hening %.0f%%, maksimal %.0f%%; dekatkan mikrofon atau kurangi jeda", *meta.RasioHening*100, syarat.RasioHeningMaks*100)
+	}
+	cek = append(cek, hening)
+
+	clipping := HasilCek{
+		Nama:  CekClipping,
+		Lolos: *meta.RasioClipping <= syarat.RasioClippingMaks,
+		Nilai: *meta.RasioClipping,
+		Batas: syarat.RasioClippingMaks,
+		Pesan: fmt.Sprintf("Clipping %.2f%%", *meta.RasioClipping*100),
+	}
+	if !clipping.Lolos {
+		clipping.Pesan = fmt.Sprintf("Clipping %.2f%%, maksimal %.2f%%; kecilkan volume input atau jauhkan mikrofon", *meta.RasioClipping*100, syarat.RasioClippingMaks*100)
+	}
+	cek = append(cek, clipping)
+
+	laporan := LaporanKualitas{Lolos: true, Cek: cek}
+	for _, hasil := range cek {
+		if !hasil.Lolos {
+			laporan.Lolos = false
+		}
+	}
+	return laporan
+}
@@ -0,0 +1,339 @@
+package audio
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// Ambang analisis level audio
+const (
+	// LevelMinimumDB dipakai untuk audio yang benar-benar hening karena
+	// log10(0) tidak terdefinisi
+	LevelMinimumDB = -100.0
+	// AmbangHeningDB adalah level RMS jendela yang dianggap hening
+	AmbangHeningDB = -50.0
+	// AmbangClipping adalah amplitudo (relatif terhadap skala penuh) yang
+	// dianggap clipping
+	AmbangClipping = 0.99
+	// panjangJendela adalah panjang jendela perhitungan rasio hening
+	panjangJendela = 0.02 // detik
+)
+
+// FFprobe adalah path binary ffprobe untuk format selain WAV PCM
+var FFprobe = "ffprobe"
+
+// Metadata berisi informasi teknis file audio. Level (RMS, peak, hening,
+// clipping) hanya dihitung untuk WAV PCM; untuk format lain bernilai nil.
+type Metadata struct {
+	Format        string   `json:"format"`
+	DurasiDetik   float64  `json:"durasi_detik"`
+	SampleRate    int      `json:"sample_rate"`
+	Channels      int      `json:"channels"`
+	BitsPerSample int      `json:"bits_per_sample"`
+	RMSDB         *float64 `json:"rms_db"`
+	PeakDB        *float64 `json:"peak_db"`
+	RasioHening   *float64 `json:"rasio_hening"`
+	RasioClipping *float64 `json:"rasio_clipping"`
+}
+
+// AnalisisFile membaca metadata file audio. WAV PCM dianalisis langsung,
+// format lain memakai ffprobe.
+func AnalisisFile(ctx context.Context, pathFile string) (*Metadata, error) {
+	file, err := os.Open(pathFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	format, r, err := DeteksiReader(file)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatWAV {
+		meta, err := AnalisisWAV(r)
+		if err == nil {
+			return meta, nil
+		}
+		// WAV terkompresi (misalnya ADPCM) dibaca lewat ffprobe
+	}
+
+	meta, err := Probe(ctx, pathFile)
+	if err != nil {
+		return nil, err
+	}
+	meta.Format = format
+	return meta, nil
+}
+
+// AnalisisWAV membaca file WAV PCM (integer 8/16/24/32-bit atau float
+// 32/64-bit) dan menghitung durasi serta level audionya. Data dibaca
+// bertahap sehingga aman untuk rekaman panjang.
+func AnalisisWAV(r io.Reader) (*Metadata, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+
+	var riff [12]byte
+	if _, err := io.ReadFull(br, riff[:]); err != nil {
+		return nil, fmt.Errorf("header WAV tidak valid: %v", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("bukan file WAV")
+	}
+
+	var (
+		info      InfoWAV
+		hasFormat bool
+	)
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			return nil, fmt.Errorf("chunk data tidak ditemukan")
+		}
+		chunkID := string(header[0:4])
+		chunkSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+
+		switch chunkID {
+		case "fmt ":
+			if chunkSize < 16 {
+				return nil, fmt.Errorf("chunk fmt terlalu pendek")
+			}
+			// Hanya 40 byte pertama yang dipakai (termasuk SubFormat
+			// WAVE_FORMAT_EXTENSIBLE); ukuran chunk berasal dari file upload
+			// sehingga sisanya dilewati tanpa dialokasikan
+			chunk := make([]byte, min(chunkSize, 40))
+			if _, err := io.ReadFull(br, chunk); err != nil {
+				return nil, fmt.Errorf("chunk fmt tidak valid: %v", err)
+			}
+			if _, err := br.Discard(int(chunkSize - int64(len(chunk)))); err != nil {
+				return nil, fmt.Errorf("chunk fmt tidak valid: %v", err)
+			}
+			info = InfoWAV{
+				AudioFormat:   binary.LittleEndian.Uint16(chunk[0:2]),
+				Channels:      binary.LittleEndian.Uint16(chunk[2:4]),
+				SampleRate:    binary.LittleEndian.Uint32(chunk[4:8]),
+				BitsPerSample: binary.LittleEndian.Uint16(chunk[14:16]),
+			}
+			// WAVE_FORMAT_EXTENSIBLE menyimpan format asli di awal SubFormat
+			if info.AudioFormat == 0xFFFE && len(chunk) >= 26 {
+				info.AudioFormat = binary.LittleEndian.Uint16(chunk[24:26])
+			}
+			if chunkSize%2 == 1 {
+				br.Discard(1)
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return nil, fmt.Errorf("chunk data muncul sebelum chunk fmt")
+			}
+			data := io.Reader(br)
+			// Ukuran 0 atau 0xFFFFFFFF dipakai rekaman yang ditulis streaming
+			if chunkSize > 0 && chunkSize < math.MaxUint32 {
+				data = io.LimitReader(br, chunkSize)
+			}
+			return analisisPCM(info, data)
+		default:
+			if _, err := br.Discard(int(chunkSize + chunkSize%2)); err != nil {
+				return nil, fmt.Errorf("chunk data tidak ditemukan")
+			}
+		}
+	}
+}
+
+// analisisPCM menghitung durasi, RMS, peak, rasio hening, dan rasio
+// clipping dari data sampel yang saling berselang antar-channel
+func analisisPCM(info InfoWAV, data io.Reader) (*Metadata, error) {
+	bytesPerSample := int(info.BitsPerSample) / 8
+	if info.Channels == 0 || info.SampleRate == 0 || bytesPerSample == 0 {
+		return nil, fmt.Errorf("format WAV tidak valid")
+	}
+	decode, err := dekoderSampel(info.AudioFormat, info.BitsPerSample)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := int(info.Channels)
+	frameBytes := bytesPerSample * channels
+	samplesPerWindow := int(float64(info.SampleRate)*panjangJendela) * channels
+	if samplesPerWindow < channels {
+		samplesPerWindow = channels
+	}
+
+	var (
+		total, clipping        int64
+		jumlahKuadrat, peak    float64
+		kuadratJendela         float64
+		sampelJendela          int
+		jendela, jendelaHening int64
+	)
+	tutupJendela := func() {
+		if sampelJendela == 0 {
+			return
+		}
+		jendela++
+		if keDB(math.Sqrt(kuadratJendela/float64(sampelJendela))) < AmbangHeningDB {
+			jendelaHening++
+		}
+		kuadratJendela = 0
+		sampelJendela = 0
+	}
+
+	buf := make([]byte, frameBytes*4096)
+	sisa := 0
+	for {
+		n, err := data.Read(buf[sisa:])
+		n += sisa
+		lengkap := n - n%bytesPerSample
+		for i := 0; i < lengkap; i += bytesPerSample {
+			v := decode(buf[i : i+bytesPerSample])
+			abs := math.Abs(v)
+			if abs > peak {
+				peak = abs
+			}
+			if abs >= AmbangClipping {
+				clipping++
+			}
+			jumlahKuadrat += v * v
+			kuadratJendela += v * v
+			sampelJendela++
+			total++
+			if sampelJendela == samplesPerWindow {
+				tutupJendela()
+			}
+		}
+		sisa = copy(buf, buf[lengkap:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	tutupJendela()
+
+	meta := &Metadata{
+		Format:        FormatWAV,
+		DurasiDetik:   float64(total/int64(channels)) / float64(info.SampleRate),
+		SampleRate:    int(info.SampleRate),
+		Channels:      channels,
+		BitsPerSample: int(info.BitsPerSample),
+	}
+	rms, peakDB, hening, rasioClipping := LevelMinimumDB, LevelMinimumDB, 1.0, 0.0
+	if total > 0 {
+		rms = keDB(math.Sqrt(jumlahKuadrat / float64(total)))
+		peakDB = keDB(peak)
+		hening = float64(jendelaHening) / float64(jendela)
+		rasioClipping = float64(clipping) / float64(total)
+	}
+	meta.RMSDB = &rms
+	meta.PeakDB = &peakDB
+	meta.RasioHening = &hening
+	meta.RasioClipping = &rasioClipping
+	return meta, nil
+}
+
+// dekoderSampel mengembalikan fungsi yang mengubah satu sampel menjadi
+// nilai -1..1 relatif terhadap skala penuh
+func dekoderSampel(audioFormat uint16, bits uint16) (func([]byte) float64, error) {
+	switch {
+	case audioFormat == 1 && bits == 8:
+		// PCM 8-bit tidak bertanda dengan titik nol 128
+		return func(b []byte) float64 { return (float64(b[0]) - 128) / 128 }, nil
+	case audioFormat == 1 && bits == 16:
+		return func(b []byte) float64 { return float64(int16(binary.LittleEndian.Uint16(b))) / 32768 }, nil
+	case audioFormat == 1 && bits == 24:
+		return func(b []byte) float64 {
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			return float64(v) / 8388608
+		}, nil
+	case audioFormat == 1 && bits == 32:
+		return func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648 }, nil
+	case audioFormat == 3 && bits == 32:
+		return func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) }, nil
+	case audioFormat == 3 && bits == 64:
+		return func(b []byte) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(b)) }, nil
+	}
+	return nil, fmt.Errorf("format WAV %d dengan %d bit tidak didukung", audioFormat, bits)
+}
+
+// keDB mengubah amplitudo linear menjadi dBFS dengan batas bawah LevelMinimumDB
+func keDB(amplitudo float64) float64 {
+	if amplitudo <= 0 {
+		return LevelMinimumDB
+	}
+	return math.Max(20*math.Log10(amplitudo), LevelMinimumDB)
+}
+
+// SampleRateFile membaca sample rate file audio tanpa menganalisis isinya
+func SampleRateFile(ctx context.Context, pathFile string) (int, error) {
+	file, err := os.Open(pathFile)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := BacaInfoWAV(file)
+	if err == nil {
+		return int(info.SampleRate), nil
+	}
+
+	meta, err := Probe(ctx, pathFile)
+	if err != nil {
+		return 0, err
+	}
+	return meta.SampleRate, nil
+}
+
+// Probe membaca durasi, sample rate, dan jumlah channel memakai ffprobe
+func Probe(ctx context.Context, pathFile string) (*Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, batasWaktuTranscode)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, FFprobe,
+		"-v", "error",
+		"-select_streams", "a:0",
+		"-show_entries", "stream=sample_rate,channels,bits_per_sample,duration:format=duration",
+		"-of", "json",
+		pathFile,
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe gagal membaca %s: %v", pathFile, err)
+	}
+
+	var hasil struct {
+		Streams []struct {
+			SampleRate    string `json:"sample_rate"`
+			Channels      int    `json:"channels"`
+			BitsPerSample int    `json:"bits_per_sample"`
+			Duration      string `json:"duration"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &hasil); err != nil {
+		return nil, fmt.Errorf("output ffprobe tidak valid: %v", err)
+	}
+	if len(hasil.Streams) == 0 {
+		return nil, fmt.Errorf("%w: tidak ada stream audio", ErrBukanAudio)
+	}
+
+	stream := hasil.Streams[0]
+	meta := &Metadata{
+		Channels:      stream.Channels,
+		BitsPerSample: stream.BitsPerSample,
+	}
+	meta.SampleRate, _ = strconv.Atoi(stream.SampleRate)
+	durasi := stream.Duration
+	if durasi == "" || durasi == "N/A" {
+		durasi = hasil.Format.Duration
+	}
+	meta.DurasiDetik, _ = strconv.ParseFloat(durasi, 64)
+	return meta, nil
+}
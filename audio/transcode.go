@@ -0,0 +1,119 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"CLAIRE/recorder"
+)
+
+// Format hasil normalisasi, sama dengan keluaran perekam
+const (
+	SampleRate    = recorder.SampleRate
+	Channels      = recorder.Channels
+	BitsPerSample = recorder.BitsPerSample
+)
+
+// FFmpeg adalah path binary ffmpeg yang dipakai untuk transcoding
+var FFmpeg = "ffmpeg"
+
+// demuxerFFmpeg memetakan format hasil Deteksi ke demuxer ffmpeg
+var demuxerFFmpeg = map[string]string{
+	FormatWAV:  "wav",
+	FormatWebM: "matroska",
+	FormatOgg:  "ogg",
+	FormatFLAC: "flac",
+	FormatMP3:  "mp3",
+	FormatAAC:  "aac",
+	FormatMP4:  "mov",
+}
+
+// batasWaktuTranscode membatasi lama satu proses ffmpeg
+const batasWaktuTranscode = 5 * time.Minute
+
+// PerluTranscode mengecek apakah file berformat format harus dikonversi.
+// Hanya WAV yang sudah PCM 16-bit 16 kHz mono yang disimpan apa adanya.
+func PerluTranscode(pathFile string, format string) (bool, error) {
+	if format != FormatWAV {
+		return true, nil
+	}
+
+	file, err := os.Open(pathFile)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	info, err := BacaInfoWAV(file)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrBukanAudio, err)
+	}
+	return !info.Normal(), nil
+}
+
+// Transcode mengonversi input berformat format (hasil Deteksi) menjadi WAV
+// PCM 16-bit 16 kHz mono di output. Track video diabaikan. Input yang tidak
+// bisa didekode menghasilkan error yang membungkus ErrBukanAudio.
+//
+// Demuxer ffmpeg dipaksa sesuai format dan hanya protokol file yang
+// diizinkan, sehingga upload berisi playlist (HLS, concat, ...) tidak bisa
+// membuat ffmpeg membuka file atau URL lain.
+func Transcode(ctx context.Context, input string, format string, output string) error {
+	demuxer, ok := demuxerFFmpeg[format]
+	if !ok {
+		return fmt.Errorf("%w: format %q tidak dikenal", ErrBukanAudio, format)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, batasWaktuTranscode)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, FFmpeg,
+		"-nostdin",
+		"-hide_banner",
+		"-y",
+		"-protocol_whitelist", "file",
+		"-f", demuxer,
+		"-i", input,
+		"-vn",                  // Abaikan track video
+		"-acodec", "pcm_s16le", // Audio codec
+		"-ar", strconv.Itoa(SampleRate),
+		"-ac", strconv.Itoa(Channels),
+		"-f", "wav",
+		output,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("ffmpeg tidak ditemukan untuk konversi audio: %v", err)
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("konversi audio dihentikan: %v", ctx.Err())
+		}
+		return fmt.Errorf("%w: ffmpeg gagal mendekode audio: %s", ErrBukanAudio, barisTerakhir(stderr.String()))
+	}
+
+	// Container tanpa track audio menghasilkan WAV tanpa data
+	size, err := recorder.FinalizeWAV(output)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		return fmt.Errorf("%w: file tidak berisi audio", ErrBukanAudio)
+	}
+	return nil
+}
+
+// barisTerakhir mengambil baris terakhir output ffmpeg sebagai ringkasan error
+func barisTerakhir(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,66 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// InfoWAV berisi field penting dari chunk "fmt " file WAV
+type InfoWAV struct {
+	AudioFormat   uint16 // 1 = PCM
+	Channels      uint16
+	SampleRate    uint32
+	BitsPerSample uint16
+}
+
+// BacaInfoWAV membaca header WAV sampai chunk "fmt " ditemukan
+func BacaInfoWAV(r io.Reader) (InfoWAV, error) {
+	var info InfoWAV
+
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return info, fmt.Errorf("header WAV tidak valid: %v", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return info, fmt.Errorf("bukan file WAV")
+	}
+
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return info, fmt.Errorf("chunk fmt tidak ditemukan")
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+
+		if string(header[0:4]) != "fmt " {
+			// Lewati chunk lain (LIST, fact, ...) beserta padding-nya
+			if _, err := io.CopyN(io.Discard, r, chunkSize+chunkSize%2); err != nil {
+				return info, fmt.Errorf("chunk fmt tidak ditemukan")
+			}
+			continue
+		}
+		if chunkSize < 16 {
+			return info, fmt.Errorf("chunk fmt terlalu pendek")
+		}
+		var chunk [16]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return info, fmt.Errorf("chunk fmt tidak valid: %v", err)
+		}
+		info = InfoWAV{
+			AudioFormat:   binary.LittleEndian.Uint16(chunk[0:2]),
+			Channels:      binary.LittleEndian.Uint16(chunk[2:4]),
+			SampleRate:    binary.LittleEndian.Uint32(chunk[4:8]),
+			BitsPerSample: binary.LittleEndian.Uint16(chunk[14:16]),
+		}
+		return info, nil
+	}
+}
+
+// Normal mengecek apakah WAV sudah berformat PCM 16-bit, 16 kHz, mono
+func (info InfoWAV) Normal() bool {
+	return info.AudioFormat == 1 &&
+		info.Channels == Channels &&
+		info.SampleRate == SampleRate &&
+		info.BitsPerSample == BitsPerSample
+}
@@ -0,0 +1,123 @@
+// Package audit mencatat operasi create, update dan delete lewat API beserta
+// pelaku, route, IP dan selisih nilai sebelum/sesudah per field.
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"CLAIRE/models"
+
+	"gorm.io/gorm"
+)
+
+// Aksi yang dicatat
+const (
+	AksiBuat  = "create"
+	AksiUbah  = "update"
+	AksiHapus = "delete"
+)
+
+// Jenis entitas yang diaudit
+const (
+	EntitasDosen    = "dosen"
+	EntitasJadwal   = "jadwal"
+	EntitasEvaluasi = "evaluasi"
+)
+
+// Sumber adalah asal sebuah perubahan: siapa pelakunya dan lewat request apa
+type Sumber struct {
+	Aktor  string
+	Metode string
+	Route  string
+	Path   string
+	IP     string
+}
+
+// Perubahan adalah nilai sebuah field sebelum dan sesudah operasi
+type Perubahan struct {
+	Sebelum interface{} `json:"sebelum"`
+	Sesudah interface{} `json:"sesudah"`
+}
+
+// fieldDiabaikan tidak ikut dibandingkan karena selalu berubah di setiap update
+var fieldDiabaikan = map[string]bool{
+	"tanggal_diupdate": true,
+}
+
+// Snapshot mengubah entitas menjadi map field -> nilai seperti di response
+// JSON. Relasi (objek dan array bersarang) dibuang supaya audit hanya berisi
+// kolom entitas itu sendiri.
+func Snapshot(entitas interface{}) map[string]interface{} {
+	if entitas == nil {
+		return nil
+	}
+	value := reflect.ValueOf(entitas)
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return nil
+	}
+
+	data, err := json.Marshal(entitas)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	for nama, nilai := range fields {
+		switch nilai.(type) {
+		case map[string]interface{}, []interface{}:
+			delete(fields, nama)
+		}
+		if fieldDiabaikan[nama] {
+			delete(fields, nama)
+		}
+	}
+	return fields
+}
+
+// Diff membandingkan dua snapshot. Snapshot nil berarti entitas belum ada
+// (create) atau sudah tidak ada (delete).
+func Diff(sebelum, sesudah map[string]interface{}) map[string]Perubahan {
+	hasil := make(map[string]Perubahan)
+	for nama, lama := range sebelum {
+		baru, ada := sesudah[nama]
+		if !ada || !reflect.DeepEqual(lama, baru) {
+			hasil[nama] = Perubahan{Sebelum: lama, Sesudah: baru}
+		}
+	}
+	for nama, baru := range sesudah {
+		if _, ada := sebelum[nama]; !ada {
+			hasil[nama] = Perubahan{Sebelum: nil, Sesudah: baru}
+		}
+	}
+	return hasil
+}
+
+// Catat menyimpan satu entri audit. Update yang tidak mengubah field apa pun
+// tidak dicatat. Panggil dengan transaksi yang sama dengan perubahannya jika
+// ada, supaya audit ikut dibatalkan saat transaksi gagal.
+func Catat(db *gorm.DB, sumber Sumber, jenis string, entitasID string, aksi string, sebelum, sesudah interface{}) error {
+	perubahan := Diff(Snapshot(sebelum), Snapshot(sesudah))
+	if aksi == AksiUbah && len(perubahan) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(perubahan)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(&models.AuditLog{
+		Aktor:        sumber.Aktor,
+		Metode:       sumber.Metode,
+		Route:        sumber.Route,
+		Path:         sumber.Path,
+		IP:           sumber.IP,
+		JenisEntitas: jenis,
+		EntitasID:    entitasID,
+		Aksi:         aksi,
+		Perubahan:    data,
+	}).Error
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Scope API key untuk route mesin
+const (
+	ScopeAnalisis = "analisis" // callback hasil analisis dari service Python
+	ScopeRekaman  = "rekaman"  // mulai/hentikan dan status rekaman
+	ScopeAgen     = "agen"     // agen perekam jarak jauh di ruang kelas
+)
+
+// awalanAPIKey memudahkan mengenali kunci yang bocor di log atau repository
+const awalanAPIKey = "clk_"
+
+// kunciAPIKey adalah kunci gin.Context untuk API key yang dipakai request
+const kunciAPIKey = "auth.apikey"
+
+// ScopeValid mengecek apakah scope dikenal
+func ScopeValid(scope string) bool {
+	return scope == ScopeAnalisis || scope == ScopeRekaman || scope == ScopeAgen
+}
+
+// BuatAPIKey membuat kunci acak baru. Yang disimpan hanya hash dan prefix;
+// kunci asli harus langsung diberikan ke pemakainya.
+func BuatAPIKey() (kunci string, prefix string, err error) {
+	return kunciAcak(awalanAPIKey)
+}
+
+// kunciAcak membuat kunci acak 256 bit dengan awalan tertentu
+func kunciAcak(awalan string) (kunci string, prefix string, err error) {
+	acak := make([]byte, 32)
+	if _, err := rand.Read(acak); err != nil {
+		return "", "", err
+	}
+	kunci = awalan + base64.RawURLEncoding.EncodeToString(acak)
+	return kunci, kunci[:len(awalan)+8], nil
+}
+
+// HashAPIKey menghitung hash SHA-256 kunci dalam heksadesimal. Kunci sudah
+// acak 256 bit sehingga tidak perlu hash lambat seperti bcrypt.
+func HashAPIKey(kunci string) string {
+	sum := sha256.Sum256([]byte(kunci))
+	return hex.EncodeToString(sum[:])
+}
+
+// Mesin dipasang pada route yang dipanggil layanan mesin. Request boleh
+// memakai API key dengan scope yang sesuai (header X-API-Key atau
+// "Authorization: ApiKey ...") atau token login pengguna yang memiliki izin.
+// Waktu terakhir dipakai dicatat untuk setiap API key yang diterima.
+func Mesin(signer *Signer, db *gorm.DB, scope string, izin string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		kunci := apiKeyDari(c)
+		if kunci == "" {
+			user, status, pesan := autentikasi(c, signer, db)
+			if user == nil {
+				c.AbortWithStatusJSON(status, gin.H{"error": pesan})
+				return
+			}
+			if !Punya(user.Role, izin) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Akses ditolak"})
+				return
+			}
+			c.Set(kunciPengguna, user)
+			c.Next()
+			return
+		}
+
+		var key models.APIKey
+		if err := db.First(&key, "hash_kunci = ?", HashAPIKey(kunci)).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key tidak valid"})
+			return
+		}
+		now := time.Now()
+		if !key.Berlaku(now) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key sudah dicabut atau kedaluwarsa"})
+			return
+		}
+		if !key.PunyaScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key tidak memiliki scope " + scope})
+			return
+		}
+
+		db.Model(&models.APIKey{}).Where("id = ?", key.ID).UpdateColumn("terakhir_dipakai", now)
+		key.TerakhirDipakai = &now
+
+		c.Set(kunciAPIKey, &key)
+		c.Next()
+	}
+}
+
+// APIKeyRequest mengembalikan API key yang dipakai request, nil jika request
+// memakai token login
+func APIKeyRequest(c *gin.Context) *models.APIKey {
+	value, ok := c.Get(kunciAPIKey)
+	if !ok {
+		return nil
+	}
+	key, _ := value.(*models.APIKey)
+	return key
+}
+
+func apiKeyDari(c *gin.Context) string {
+	if kunci := c.GetHeader("X-API-Key"); kunci != "" {
+		return strings.TrimSpace(kunci)
+	}
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "ApiKey ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"crypto/rand"
+	"log"
+
+	"CLAIRE/models"
+
+	"gorm.io/gorm"
+)
+
+// SecretDari mengembalikan secret penandatangan token. Jika AUTH_SECRET
+// kosong, secret acak dibuat sehingga semua token tidak berlaku lagi
+// setelah server restart.
+func SecretDari(secret string) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+	log.Println("AUTH_SECRET tidak diatur, memakai secret acak; token tidak berlaku lagi setelah restart")
+	acak := make([]byte, 32)
+	if _, err := rand.Read(acak); err != nil {
+		log.Fatal("Gagal membuat secret token:", err)
+	}
+	return acak
+}
+
+// BootstrapAdmin membuat akun admin pertama jika belum ada pengguna sama
+// sekali dan ADMIN_PASSWORD diatur
+func BootstrapAdmin(db *gorm.DB, username string, password string) error {
+	var jumlah int64
+	if err := db.Model(&models.User{}).Count(&jumlah).Error; err != nil {
+		return err
+	}
+	if jumlah > 0 {
+		return nil
+	}
+	if password == "" {
+		log.Println("Belum ada pengguna; atur ADMIN_PASSWORD untuk membuat akun admin pertama")
+		return nil
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	admin := models.User{
+		Username:     username,
+		Nama:         "Administrator",
+		PasswordHash: hash,
+		Role:         models.RoleAdmin,
+		Aktif:        true,
+	}
+	if err := db.Create(&admin).Error; err != nil {
+		return err
+	}
+	log.Printf("Akun admin pertama %q dibuat", username)
+	return nil
+}
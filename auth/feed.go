@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// awalanTokenFeed membedakan token feed kalender dari token URL
+const awalanTokenFeed = "clf_"
+
+// BuatTokenFeed membuat token feed kalender acak baru. Seperti API key,
+// yang disimpan hanya hash (HashAPIKey) dan prefix.
+func BuatTokenFeed() (token string, prefix string, err error) {
+	return kunciAcak(awalanTokenFeed)
+}
+
+// FeedKalender dipasang pada route feed .ics. Selain cara yang diterima
+// TokenURL, route ini menerima token feed (?token=clf_...) milik satu feed.
+// Token feed hanya berlaku untuk path feed tempat token dibuat, tidak bisa
+// dipakai sebagai token login, dan berhenti berlaku begitu dicabut atau
+// akun pembuatnya dinonaktifkan.
+func FeedKalender(signer *Signer, db *gorm.DB) gin.HandlerFunc {
+	tautan := TokenURL(signer, db)
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if tokenDari(c) != "" || !strings.HasPrefix(token, awalanTokenFeed) {
+			tautan(c)
+			return
+		}
+
+		var feed models.FeedKalender
+		if err := db.First(&feed, "hash_token = ?", HashAPIKey(token)).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token feed kalender tidak valid"})
+			return
+		}
+		if feed.DicabutPada != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token feed kalender sudah dicabut"})
+			return
+		}
+		if feed.Path != c.Request.URL.Path {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token feed kalender tidak berlaku untuk alamat ini"})
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, "id = ?", feed.UserID).Error; err != nil || !user.Aktif {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Pemilik token feed kalender tidak aktif"})
+			return
+		}
+
+		db.Model(&models.FeedKalender{}).Where("id = ?", feed.ID).UpdateColumn("terakhir_dipakai", time.Now())
+
+		c.Set(kunciPengguna, &user)
+		c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFeedKalender(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := dbUji(t)
+	user := models.User{Username: "budi", PasswordHash: "x", Role: models.RoleViewer}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	signer := NewSigner([]byte("rahasia"), time.Hour)
+	router := gin.New()
+	router.GET("/api/dosen/:id/kalender.ics", FeedKalender(signer, db), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/dosen", Middleware(signer, db), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// buatFeed menyimpan feed untuk path dan mengembalikan token aslinya
+	buatFeed := func(path string) (models.FeedKalender, string) {
+		token, prefix, err := BuatTokenFeed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		feed := models.FeedKalender{UserID: user.ID, Path: path, Prefix: prefix, HashToken: HashAPIKey(token)}
+		if err := db.Create(&feed).Error; err != nil {
+			t.Fatal(err)
+		}
+		return feed, token
+	}
+	feed, token := buatFeed("/api/dosen/1/kalender.ics")
+	dicabut, tokenDicabut := buatFeed("/api/dosen/1/kalender.ics")
+	db.Model(&dicabut).Update("dicabut_pada", time.Now())
+
+	tests := []struct {
+		nama string
+		path string
+		want int
+	}{
+		{"token feed untuk path ini", "/api/dosen/1/kalender.ics?token=" + token, http.StatusOK},
+		{"token feed untuk path lain", "/api/dosen/2/kalender.ics?token=" + token, http.StatusUnauthorized},
+		{"token feed dicabut", "/api/dosen/1/kalender.ics?token=" + tokenDicabut, http.StatusUnauthorized},
+		{"token feed tidak dikenal", "/api/dosen/1/kalender.ics?token=" + awalanTokenFeed + "palsu", http.StatusUnauthorized},
+		{"token feed sebagai token login", "/api/dosen?token=" + token, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := kirim(router, tt.path, ""); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.nama, got, tt.want)
+		}
+	}
+	if got := kirim(router, "/api/dosen", token); got != http.StatusUnauthorized {
+		t.Errorf("token feed di header: status = %d, want 401", got)
+	}
+
+	var tersimpan models.FeedKalender
+	db.First(&tersimpan, "id = ?", feed.ID)
+	if tersimpan.TerakhirDipakai == nil {
+		t.Error("terakhir_dipakai tidak diperbarui")
+	}
+
+	// Token berhenti berlaku begitu pemiliknya dinonaktifkan
+	db.Model(&user).Update("aktif", false)
+	if got := kirim(router, "/api/dosen/1/kalender.ics?token="+token, ""); got != http.StatusUnauthorized {
+		t.Errorf("pemilik tidak aktif: status = %d, want 401", got)
+	}
+}
@@ -0,0 +1,55 @@
+package auth
+
+import "CLAIRE/models"
+
+// Izin yang diperiksa per route
+const (
+	IzinBaca           = "data:baca"
+	IzinKelolaData     = "data:kelola"
+	IzinHapus          = "data:hapus"
+	IzinBacaEvaluasi   = "evaluasi:baca"
+	IzinKelolaEvaluasi = "evaluasi:kelola"
+	IzinDashboard      = "dashboard:baca"
+	IzinRekaman        = "rekaman:kelola"
+	IzinSistem         = "sistem:kelola"
+	IzinKelolaPengguna = "pengguna:kelola"
+	IzinSuaraSendiri   = "suara:rekam-sendiri"
+	IzinKelolaAPIKey   = "apikey:kelola"
+	IzinAudit          = "audit:baca"
+)
+
+// izinRole memetakan role ke izin yang dimilikinya. Dosen boleh membaca
+// jadwal dan evaluasi, tetapi handler membatasinya ke jadwal milik dosen itu
+// sendiri.
+var izinRole = map[string][]string{
+	models.RoleAdmin: {
+		IzinBaca, IzinKelolaData, IzinHapus, IzinBacaEvaluasi, IzinKelolaEvaluasi,
+		IzinDashboard, IzinRekaman, IzinSistem, IzinKelolaPengguna, IzinKelolaAPIKey,
+		IzinAudit,
+	},
+	models.RoleOperator: {
+		IzinBaca, IzinKelolaData, IzinBacaEvaluasi, IzinKelolaEvaluasi,
+		IzinDashboard, IzinRekaman, IzinSistem,
+	},
+	models.RoleDosen: {
+		IzinBaca, IzinBacaEvaluasi, IzinSuaraSendiri,
+	},
+	models.RoleViewer: {
+		IzinBaca, IzinBacaEvaluasi, IzinDashboard,
+	},
+}
+
+// Punya mengecek apakah role memiliki izin
+func Punya(role string, izin string) bool {
+	for _, i := range izinRole[role] {
+		if i == izin {
+			return true
+		}
+	}
+	return false
+}
+
+// IzinRole mengembalikan daftar izin sebuah role
+func IzinRole(role string) []string {
+	return izinRole[role]
+}
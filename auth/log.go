@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FormatLog adalah format log request gin (sama dengan format bawaan) yang
+// menyamarkan nilai query token supaya token URL tidak tersimpan di log
+func FormatLog(param gin.LogFormatterParams) string {
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		SamarkanToken(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// SamarkanToken mengganti nilai parameter token di path beserta query
+func SamarkanToken(path string) string {
+	i := strings.IndexByte(path, '?')
+	if i < 0 {
+		return path
+	}
+	query, err := url.ParseQuery(path[i+1:])
+	if err != nil {
+		return path[:i] + "?[disamarkan]"
+	}
+	if _, ada := query["token"]; !ada {
+		return path
+	}
+	query.Set("token", "disamarkan")
+	return path[:i] + "?" + query.Encode()
+}
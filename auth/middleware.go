@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// kunciPengguna adalah kunci gin.Context untuk pengguna yang sudah login
+const kunciPengguna = "auth.pengguna"
+
+// Middleware memverifikasi token Bearer dan memuat pengguna dari database.
+// Token login hanya diterima lewat header; route yang dibuka langsung dari
+// browser memakai TokenURL. API key tidak diterima di sini; route mesin
+// memakai Mesin.
+func Middleware(signer *Signer, db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, status, pesan := autentikasi(c, signer, db)
+		if user == nil {
+			c.AbortWithStatusJSON(status, gin.H{"error": pesan})
+			return
+		}
+
+		c.Set(kunciPengguna, user)
+		c.Next()
+	}
+}
+
+// TokenURL dipasang pada route GET yang dibuka langsung dari browser atau
+// aplikasi, misalnya file audio di elemen <audio>. Selain header Bearer,
+// route ini menerima token URL di query ?token= yang dibuat untuk path
+// request tersebut (lihat Signer.IssueURL). Token login biasa tidak diterima
+// lewat query supaya tidak bocor ke log atau riwayat browser.
+func TokenURL(signer *Signer, db *gorm.DB) gin.HandlerFunc {
+	login := Middleware(signer, db)
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if tokenDari(c) != "" || token == "" {
+			login(c)
+			return
+		}
+
+		claims, status, pesan := verifikasi(signer, token)
+		if claims == nil {
+			c.AbortWithStatusJSON(status, gin.H{"error": pesan})
+			return
+		}
+		if claims.Tujuan != TujuanURL || claims.Path != c.Request.URL.Path {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token URL tidak berlaku untuk alamat ini"})
+			return
+		}
+		user, status, pesan := penggunaToken(claims, db)
+		if user == nil {
+			c.AbortWithStatusJSON(status, gin.H{"error": pesan})
+			return
+		}
+
+		c.Set(kunciPengguna, user)
+		c.Next()
+	}
+}
+
+// autentikasi memverifikasi token login request. Jika gagal, pengguna nil
+// dan status serta pesan error dikembalikan.
+func autentikasi(c *gin.Context, signer *Signer, db *gorm.DB) (*models.User, int, string) {
+	token := tokenDari(c)
+	if token == "" {
+		if apiKeyDari(c) != "" {
+			return nil, http.StatusForbidden, "API key hanya berlaku untuk endpoint mesin"
+		}
+		return nil, http.StatusUnauthorized, "Token autentikasi diperlukan"
+	}
+
+	claims, status, pesan := verifikasi(signer, token)
+	if claims == nil {
+		return nil, status, pesan
+	}
+	if claims.Tujuan != "" {
+		return nil, http.StatusUnauthorized, "Token URL tidak berlaku sebagai token login"
+	}
+	return penggunaToken(claims, db)
+}
+
+// verifikasi memeriksa tanda tangan dan masa berlaku token
+func verifikasi(signer *Signer, token string) (*Claims, int, string) {
+	claims, err := signer.Verify(token)
+	if err != nil {
+		pesan := "Token tidak valid"
+		if errors.Is(err, ErrTokenKedaluwarsa) {
+			pesan = "Token sudah kedaluwarsa, silakan login ulang"
+		}
+		return nil, http.StatusUnauthorized, pesan
+	}
+	return claims, 0, ""
+}
+
+// penggunaToken memuat pengguna pemilik token
+func penggunaToken(claims *Claims, db *gorm.DB) (*models.User, int, string) {
+	var user models.User
+	if err := db.First(&user, "id = ?", claims.Subject).Error; err != nil {
+		return nil, http.StatusUnauthorized, "Pengguna tidak ditemukan"
+	}
+	// Token lama tidak berlaku lagi setelah password diganti atau akun
+	// dinonaktifkan
+	if !user.Aktif || user.VersiToken != claims.VersiToken {
+		return nil, http.StatusUnauthorized, "Token tidak berlaku lagi, silakan login ulang"
+	}
+	return &user, 0, ""
+}
+
+// Perlu memastikan pengguna yang login memiliki izin tertentu
+func Perlu(izin string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := Pengguna(c)
+		if user == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token autentikasi diperlukan"})
+			return
+		}
+		if !Punya(user.Role, izin) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Akses ditolak"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// PerluSalahSatu memastikan pengguna yang login memiliki minimal satu dari
+// izin yang disebutkan. Handler tetap harus membatasi data untuk izin yang
+// lebih sempit.
+func PerluSalahSatu(izin ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := Pengguna(c)
+		if user == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token autentikasi diperlukan"})
+			return
+		}
+		for _, i := range izin {
+			if Punya(user.Role, i) {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Akses ditolak"})
+	}
+}
+
+// Pengguna mengembalikan pengguna yang sedang login, nil jika tidak ada
+func Pengguna(c *gin.Context) *models.User {
+	value, ok := c.Get(kunciPengguna)
+	if !ok {
+		return nil
+	}
+	user, _ := value.(*models.User)
+	return user
+}
+
+func tokenDari(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dbUji membuka database SQLite sementara dengan skema aplikasi
+func dbUji(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "auth.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MigrateDB(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// kirim menjalankan request GET ke router dan mengembalikan status HTTP
+func kirim(router http.Handler, path string, bearer string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestTokenURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := dbUji(t)
+	user := models.User{Username: "budi", PasswordHash: "x", Role: models.RoleAdmin}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	signer := NewSigner([]byte("rahasia"), time.Hour)
+	router := gin.New()
+	router.GET("/api/evaluasi/:id/audio", TokenURL(signer, db), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/dosen", Middleware(signer, db), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	claims := Claims{Subject: user.ID.String(), Username: user.Username, VersiToken: user.VersiToken}
+	login, _, _ := signer.Issue(claims)
+	tokenURL, _, _ := signer.IssueURL(claims, "/api/evaluasi/1/audio")
+	tokenLama, _, _ := signer.IssueURL(Claims{Subject: user.ID.String(), VersiToken: user.VersiToken - 1}, "/api/evaluasi/1/audio")
+
+	tests := []struct {
+		nama   string
+		path   string
+		bearer string
+		want   int
+	}{
+		{"token URL untuk path ini", "/api/evaluasi/1/audio?token=" + tokenURL, "", http.StatusOK},
+		{"token URL untuk path lain", "/api/evaluasi/2/audio?token=" + tokenURL, "", http.StatusUnauthorized},
+		{"token login di query", "/api/evaluasi/1/audio?token=" + login, "", http.StatusUnauthorized},
+		{"token URL setelah versi berubah", "/api/evaluasi/1/audio?token=" + tokenLama, "", http.StatusUnauthorized},
+		{"header Bearer tetap diterima", "/api/evaluasi/1/audio", login, http.StatusOK},
+		{"tanpa token", "/api/evaluasi/1/audio", "", http.StatusUnauthorized},
+		{"token URL sebagai token login", "/api/dosen", tokenURL, http.StatusUnauthorized},
+		{"query token di route biasa", "/api/dosen?token=" + login, "", http.StatusUnauthorized},
+		{"token login di header", "/api/dosen", login, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := kirim(router, tt.path, tt.bearer); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.nama, got, tt.want)
+		}
+	}
+}
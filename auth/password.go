@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PanjangPasswordMinimal adalah panjang password paling pendek yang diterima
+const PanjangPasswordMinimal = 8
+
+// ErrPasswordPendek dikembalikan untuk password yang terlalu pendek
+var ErrPasswordPendek = errors.New("password minimal 8 karakter")
+
+// HashPassword membuat hash bcrypt dari password
+func HashPassword(password string) (string, error) {
+	if len(password) < PanjangPasswordMinimal {
+		return "", ErrPasswordPendek
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CekPassword membandingkan password dengan hash bcrypt
+func CekPassword(hash string, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,128 @@
+// Package auth menangani login pengguna: hash password, token bertanda
+// tangan HMAC, API key untuk layanan mesin dan middleware izin untuk route
+// API.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTokenTidakValid dikembalikan untuk token rusak atau tanda tangan salah
+	ErrTokenTidakValid = errors.New("token tidak valid")
+	// ErrTokenKedaluwarsa dikembalikan untuk token yang sudah lewat masa berlakunya
+	ErrTokenKedaluwarsa = errors.New("token sudah kedaluwarsa")
+)
+
+// TujuanURL menandai token URL: token sementara di query ?token= yang hanya
+// berlaku untuk satu path, misalnya file audio yang diputar lewat <audio>
+const TujuanURL = "url"
+
+// DefaultTTLURL adalah masa berlaku token URL jika tidak diatur
+const DefaultTTLURL = 15 * time.Minute
+
+// Claims adalah isi token. Role tidak disimpan di token; middleware selalu
+// membaca role terbaru dari database. Tujuan kosong untuk token login;
+// token URL berisi TujuanURL dan Path tempat token berlaku.
+type Claims struct {
+	Subject    string `json:"sub"`
+	Username   string `json:"username"`
+	VersiToken int    `json:"ver"`
+	Tujuan     string `json:"tujuan,omitempty"`
+	Path       string `json:"path,omitempty"`
+	IssuedAt   int64  `json:"iat"`
+	ExpiresAt  int64  `json:"exp"`
+}
+
+// header token dalam format JWT HS256 supaya bisa dibaca pustaka JWT umum
+var headerToken = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Signer membuat dan memverifikasi token HMAC-SHA256
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	ttlURL time.Duration
+	now    func() time.Time
+}
+
+// NewSigner membuat Signer dengan secret dan masa berlaku token
+func NewSigner(secret []byte, ttl time.Duration) *Signer {
+	if ttl <= 0 {
+		ttl = 12 * time.Hour
+	}
+	return &Signer{secret: secret, ttl: ttl, ttlURL: DefaultTTLURL, now: time.Now}
+}
+
+// SetTTLURL mengatur masa berlaku token URL
+func (s *Signer) SetTTLURL(ttl time.Duration) {
+	if ttl > 0 {
+		s.ttlURL = ttl
+	}
+}
+
+// Issue membuat token login untuk claims. IssuedAt dan ExpiresAt diisi
+// otomatis.
+func (s *Signer) Issue(claims Claims) (string, time.Time, error) {
+	claims.Tujuan = ""
+	claims.Path = ""
+	return s.issue(claims, s.ttl)
+}
+
+// IssueURL membuat token URL berumur pendek yang hanya berlaku untuk path
+func (s *Signer) IssueURL(claims Claims, path string) (string, time.Time, error) {
+	claims.Tujuan = TujuanURL
+	claims.Path = path
+	return s.issue(claims, s.ttlURL)
+}
+
+func (s *Signer) issue(claims Claims, ttl time.Duration) (string, time.Time, error) {
+	now := s.now()
+	expires := now.Add(ttl)
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = expires.Unix()
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	unsigned := headerToken + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + s.sign(unsigned), expires, nil
+}
+
+// Verify memeriksa tanda tangan dan masa berlaku token
+func (s *Signer) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != headerToken {
+		return nil, ErrTokenTidakValid
+	}
+
+	expected := s.sign(parts[0] + "." + parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrTokenTidakValid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrTokenTidakValid
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrTokenTidakValid
+	}
+	if s.now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenKedaluwarsa
+	}
+	return &claims, nil
+}
+
+func (s *Signer) sign(data string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signerUji membuat Signer dengan jam yang bisa diatur dari pengujian
+func signerUji(secret string, now *time.Time) *Signer {
+	s := NewSigner([]byte(secret), time.Hour)
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestIssueVerify(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	s := signerUji("rahasia", &now)
+
+	token, expires, err := s.Issue(Claims{Subject: "user-1", Username: "budi", VersiToken: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(time.Hour); !expires.Equal(want) {
+		t.Errorf("expires = %v, want %v", expires, want)
+	}
+
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "user-1" || claims.Username != "budi" || claims.VersiToken != 3 {
+		t.Errorf("claims = %+v", claims)
+	}
+	if claims.IssuedAt != now.Unix() || claims.ExpiresAt != expires.Unix() {
+		t.Errorf("iat/exp = %d/%d, want %d/%d", claims.IssuedAt, claims.ExpiresAt, now.Unix(), expires.Unix())
+	}
+}
+
+func TestVerifyTokenDiubah(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	s := signerUji("rahasia", &now)
+	token, _, err := s.Issue(Claims{Subject: "user-1", Username: "budi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+
+	// Payload palsu dengan subject lain tetapi tanda tangan lama
+	payloadPalsu := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin","username":"admin","ver":0,"iat":0,"exp":9999999999}`))
+	// Tanda tangan dengan karakter pertama diganti
+	ganti := "A"
+	if parts[2][0] == 'A' {
+		ganti = "B"
+	}
+	ttdDiubah := ganti + parts[2][1:]
+	headerNone := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+
+	tests := map[string]string{
+		"kosong":              "",
+		"bukan tiga bagian":   parts[0] + "." + parts[1],
+		"payload diganti":     parts[0] + "." + payloadPalsu + "." + parts[2],
+		"tanda tangan diubah": parts[0] + "." + parts[1] + "." + ttdDiubah,
+		"tanpa tanda tangan":  parts[0] + "." + parts[1] + ".",
+		"header alg none":     headerNone + "." + parts[1] + "." + parts[2],
+		"bagian tambahan":     token + ".x",
+	}
+	for nama, token := range tests {
+		if _, err := s.Verify(token); !errors.Is(err, ErrTokenTidakValid) {
+			t.Errorf("%s: err = %v, want ErrTokenTidakValid", nama, err)
+		}
+	}
+
+	lain := signerUji("rahasia-lain", &now)
+	if _, err := lain.Verify(token); !errors.Is(err, ErrTokenTidakValid) {
+		t.Errorf("secret lain: err = %v, want ErrTokenTidakValid", err)
+	}
+}
+
+func TestVerifyKedaluwarsa(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	s := signerUji("rahasia", &now)
+	token, expires, err := s.Issue(Claims{Subject: "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		nama    string
+		now     time.Time
+		wantErr error
+	}{
+		{"sebelum kedaluwarsa", expires.Add(-time.Second), nil},
+		{"tepat saat kedaluwarsa", expires, ErrTokenKedaluwarsa},
+		{"setelah kedaluwarsa", expires.Add(time.Minute), ErrTokenKedaluwarsa},
+	}
+	for _, tt := range tests {
+		now = tt.now
+		if _, err := s.Verify(token); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.nama, err, tt.wantErr)
+		}
+	}
+}
+
+func TestIssueURL(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	s := signerUji("rahasia", &now)
+
+	token, expires, err := s.IssueURL(Claims{Subject: "user-1", VersiToken: 2}, "/api/evaluasi/1/audio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(DefaultTTLURL); !expires.Equal(want) {
+		t.Errorf("expires = %v, want %v", expires, want)
+	}
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Tujuan != TujuanURL || claims.Path != "/api/evaluasi/1/audio" || claims.VersiToken != 2 {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	s.SetTTLURL(time.Minute)
+	if _, expires, _ := s.IssueURL(Claims{Subject: "user-1"}, "/a"); !expires.Equal(now.Add(time.Minute)) {
+		t.Errorf("expires setelah SetTTLURL = %v, want %v", expires, now.Add(time.Minute))
+	}
+
+	// Issue tidak boleh menghasilkan token URL walaupun claims berisi tujuan
+	token, _, err = s.Issue(Claims{Subject: "user-1", Tujuan: TujuanURL, Path: "/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims, _ := s.Verify(token); claims.Tujuan != "" || claims.Path != "" {
+		t.Errorf("Issue menghasilkan claims %+v, want tanpa tujuan dan path", claims)
+	}
+}
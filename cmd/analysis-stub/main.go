@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"CLAIRE/analysis"
+)
+
+// analysis-stub menjalankan pengganti layanan analisis Python yang
+// mengembalikan AudioAnalysisResponse kalengan. Arahkan backend ke stub ini
+// dengan ANALYSIS_BASE_URL=http://localhost:5001 untuk pengujian offline.
+func main() {
+	addr := flag.String("addr", ":5001", "alamat listen stub server")
+	fixture := flag.String("fixture", "", "file JSON berisi array AudioAnalysisResponse")
+	token := flag.String("token", "", "token yang wajib dikirim pada header Authorization")
+	flag.Parse()
+
+	var responses []analysis.AudioAnalysisResponse
+	if *fixture != "" {
+		data, err := os.ReadFile(*fixture)
+		if err != nil {
+			log.Fatal("Gagal membaca fixture:", err)
+		}
+		if err := json.Unmarshal(data, &responses); err != nil {
+			log.Fatal("Fixture tidak valid:", err)
+		}
+	}
+
+	stub := analysis.NewStubServer(responses)
+	stub.AuthToken = *token
+
+	log.Printf("Analysis stub server mulai pada %s", *addr)
+	if err := http.ListenAndServe(*addr, stub); err != nil {
+		log.Fatal("Gagal memulai stub server:", err)
+	}
+}
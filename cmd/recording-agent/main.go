@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"CLAIRE/agent"
+	"CLAIRE/config"
+)
+
+// recording-agent dijalankan di komputer ruang kelas sehingga ruangan tidak
+// perlu menjalankan backend. Buat API key dengan scope "agen" lalu jalankan:
+//
+//	AGENT_SERVER_URL=http://backend:8080 AGENT_API_KEY=clk_... AGENT_RUANGAN=A101 recording-agent
+//
+// Perangkat perekam diatur dengan variabel RECORDER_* yang sama dengan server.
+func main() {
+	hostname, _ := os.Hostname()
+
+	serverURL := flag.String("server", envAtau("AGENT_SERVER_URL", "http://localhost:8080"), "alamat backend CLAIRE")
+	apiKey := flag.String("api-key", os.Getenv("AGENT_API_KEY"), "API key dengan scope agen")
+	nama := flag.String("nama", envAtau("AGENT_NAMA", hostname), "nama agen yang tampil di backend")
+	ruangan := flag.String("ruangan", os.Getenv("AGENT_RUANGAN"), "kode ruangan yang dilayani, dipisah koma")
+	dir := flag.String("dir", envAtau("AGENT_DIR", "agent-recordings"), "direktori rekaman lokal sebelum diunggah")
+	heartbeat := flag.Duration("heartbeat", envDurasi("AGENT_HEARTBEAT_INTERVAL", 15*time.Second), "jarak antar heartbeat")
+	longPoll := flag.Duration("long-poll", envDurasi("AGENT_LONG_POLL", 30*time.Second), "lama menunggu job per request")
+	chunk := flag.Int64("chunk", envInt("AGENT_CHUNK_SIZE", 1<<20), "ukuran potongan upload dalam byte")
+	maxJobs := flag.Int("max-jobs", int(envInt("AGENT_MAX_JOBS", 1)), "jumlah job yang boleh direkam bersamaan")
+	flag.Parse()
+
+	if *apiKey == "" {
+		log.Fatal("API key agen wajib diisi (-api-key atau AGENT_API_KEY)")
+	}
+
+	var kodeRuangan []string
+	for _, kode := range strings.Split(*ruangan, ",") {
+		if kode = strings.TrimSpace(kode); kode != "" {
+			kodeRuangan = append(kodeRuangan, kode)
+		}
+	}
+
+	a := agent.New(agent.Config{
+		ServerURL:         *serverURL,
+		APIKey:            *apiKey,
+		Nama:              *nama,
+		Ruangan:           kodeRuangan,
+		Dir:               *dir,
+		HeartbeatInterval: *heartbeat,
+		LongPoll:          *longPoll,
+		ChunkSize:         *chunk,
+		MaxJobs:           *maxJobs,
+		Recorder:          config.LoadConfig(),
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Agen perekam %s terhubung ke %s", *nama, *serverURL)
+	if err := a.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Fatal("Agen berhenti: ", err)
+	}
+	log.Println("Agen perekam berhenti")
+}
+
+func envAtau(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func envDurasi(key string, defaultValue time.Duration) time.Duration {
+	parsed, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+func envInt(key string, defaultValue int64) int64 {
+	parsed, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
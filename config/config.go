@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"runtime"
+	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -13,6 +16,88 @@ type Config struct {
 	DBCharset  string
 	DBParseTime string
 	DBLoc      string
+
+	// Konfigurasi perekam audio
+	RecorderBackend        string
+	RecorderDevice         string
+	RecorderReplayFile     string
+	RecorderReplayRealtime bool
+	RecorderRoomDevices    string
+	// Daftar file replay yang boleh dipilih lewat registry ruangan, format
+	// "nama=/path/file.wav;nama2=/path/lain.wav". Registry hanya menyimpan
+	// namanya, tidak pernah path file.
+	RecorderReplayFiles    string
+
+	// Konfigurasi layanan analisis audio (Python backend)
+	AnalysisBaseURL          string
+	AnalysisTimeout          time.Duration
+	AnalysisAuthHeader       string
+	AnalysisAuthToken        string
+	AnalysisMaxRetries       int
+	AnalysisRetryBackoff     time.Duration
+	AnalysisBreakerThreshold int
+	AnalysisBreakerCooldown  time.Duration
+
+	// Konfigurasi antrian job rekaman
+	RecordingDir          string
+	RecordingWorkers      int
+	RecordingPollInterval time.Duration
+
+	// Kebijakan rekaman default untuk jadwal yang tidak mengatur sendiri
+	RecordingSessionCount    int
+	RecordingSessionDuration time.Duration
+	RecordingOffset          time.Duration
+	RecordingSessionGap      time.Duration
+	RecordingFullClass       bool
+
+	// Scheduler rekaman otomatis
+	SchedulerEnabled bool
+
+	// Jarak antar rekonsiliasi status jadwal dan pertemuan
+	StatusReconcileInterval time.Duration
+
+	// Agen perekam jarak jauh: agen dianggap offline jika tidak mengirim
+	// heartbeat selama AgentOfflineTimeout. Sesi yang sedang direkam agen
+	// offline tetap milik agen sampai akhir jadwal sesi ditambah
+	// AgentUploadGrace supaya rekamannya bisa diunggah saat agen online lagi.
+	AgentOfflineTimeout time.Duration
+	AgentLongPollMax    time.Duration
+	AgentUploadGrace    time.Duration
+
+	// Penyimpanan file audio: "local" (direktori StorageLocalDir) atau "s3"
+	// (object storage yang kompatibel dengan S3, misalnya MinIO). Jika
+	// StoragePresign aktif, audio diunduh langsung dari S3 lewat URL
+	// sementara; selain itu di-stream lewat API.
+	StorageBackend    string
+	StorageLocalDir   string
+	StoragePresign    bool
+	StoragePresignTTL time.Duration
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKey       string
+	S3SecretKey       string
+	S3Prefix          string
+	S3PathStyle       bool
+
+	// Syarat kualitas sampel suara pendaftaran dosen: durasi bicara
+	// minimal, rasio hening dan clipping maksimal (0..1), serta sample rate
+	// minimal rekaman asli
+	VoiceSampleMinSpeech   time.Duration
+	VoiceSampleMaxSilence  float64
+	VoiceSampleMaxClipping float64
+	VoiceSampleMinRate     int
+
+	// Zona waktu IANA tempat jadwal kuliah berlangsung
+	Timezone string
+
+	// Autentikasi API
+	AuthSecret         string
+	AuthTokenTTL       time.Duration
+	AuthURLTokenTTL    time.Duration // token URL untuk audio dan feed kalender
+	AdminUsername      string
+	AdminPassword      string
+	CORSAllowedOrigins string
 }
 
 func LoadConfig() *Config {
@@ -25,6 +110,66 @@ func LoadConfig() *Config {
 		DBCharset:   getEnv("DB_CHARSET", "utf8mb4"),
 		DBParseTime: getEnv("DB_PARSE_TIME", "True"),
 		DBLoc:       getEnv("DB_LOC", "Local"),
+
+		RecorderBackend:        getEnv("RECORDER_BACKEND", defaultRecorderBackend()),
+		RecorderDevice:         getEnv("RECORDER_DEVICE", ""),
+		RecorderReplayFile:     getEnv("RECORDER_REPLAY_FILE", ""),
+		RecorderReplayRealtime: getEnvBool("RECORDER_REPLAY_REALTIME", true),
+		RecorderRoomDevices:    getEnv("RECORDER_ROOM_DEVICES", ""),
+		RecorderReplayFiles:    getEnv("RECORDER_REPLAY_FILES", ""),
+
+		AnalysisBaseURL:          getEnv("ANALYSIS_BASE_URL", "http://192.168.1.75"),
+		AnalysisTimeout:          getEnvDuration("ANALYSIS_TIMEOUT", 300*time.Second),
+		AnalysisAuthHeader:       getEnv("ANALYSIS_AUTH_HEADER", "Authorization"),
+		AnalysisAuthToken:        getEnv("ANALYSIS_AUTH_TOKEN", ""),
+		AnalysisMaxRetries:       getEnvInt("ANALYSIS_MAX_RETRIES", 3),
+		AnalysisRetryBackoff:     getEnvDuration("ANALYSIS_RETRY_BACKOFF", 2*time.Second),
+		AnalysisBreakerThreshold: getEnvInt("ANALYSIS_BREAKER_THRESHOLD", 5),
+		AnalysisBreakerCooldown:  getEnvDuration("ANALYSIS_BREAKER_COOLDOWN", 60*time.Second),
+
+		RecordingDir:          getEnv("RECORDING_DIR", "recordings"),
+		RecordingWorkers:      getEnvInt("RECORDING_WORKERS", 2),
+		RecordingPollInterval: getEnvDuration("RECORDING_POLL_INTERVAL", 5*time.Second),
+
+		RecordingSessionCount:    getEnvInt("RECORDING_SESSION_COUNT", 5),
+		RecordingSessionDuration: getEnvDuration("RECORDING_SESSION_DURATION", 60*time.Second),
+		RecordingOffset:          getEnvDuration("RECORDING_OFFSET", 10*time.Minute),
+		RecordingSessionGap:      getEnvDuration("RECORDING_SESSION_GAP", 0),
+		RecordingFullClass:       getEnvBool("RECORDING_FULL_CLASS", false),
+
+		SchedulerEnabled: getEnvBool("SCHEDULER_ENABLED", true),
+
+		StatusReconcileInterval: getEnvDuration("STATUS_RECONCILE_INTERVAL", 30*time.Second),
+
+		AgentOfflineTimeout: getEnvDuration("AGENT_OFFLINE_TIMEOUT", 90*time.Second),
+		AgentLongPollMax:    getEnvDuration("AGENT_LONG_POLL_MAX", 30*time.Second),
+		AgentUploadGrace:    getEnvDuration("AGENT_UPLOAD_GRACE", 30*time.Minute),
+
+		StorageBackend:    getEnv("STORAGE_BACKEND", "local"),
+		StorageLocalDir:   getEnv("STORAGE_LOCAL_DIR", "."),
+		StoragePresign:    getEnvBool("STORAGE_PRESIGN", false),
+		StoragePresignTTL: getEnvDuration("STORAGE_PRESIGN_TTL", 15*time.Minute),
+		S3Endpoint:        getEnv("S3_ENDPOINT", ""),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:          getEnv("S3_BUCKET", ""),
+		S3AccessKey:       getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:       getEnv("S3_SECRET_KEY", ""),
+		S3Prefix:          getEnv("S3_PREFIX", ""),
+		S3PathStyle:       getEnvBool("S3_PATH_STYLE", true),
+
+		VoiceSampleMinSpeech:   getEnvDuration("VOICE_SAMPLE_MIN_SPEECH", 5*time.Second),
+		VoiceSampleMaxSilence:  getEnvFloat("VOICE_SAMPLE_MAX_SILENCE", 0.6),
+		VoiceSampleMaxClipping: getEnvFloat("VOICE_SAMPLE_MAX_CLIPPING", 0.01),
+		VoiceSampleMinRate:     getEnvInt("VOICE_SAMPLE_MIN_RATE", 16000),
+
+		Timezone: getEnv("TIMEZONE", "Asia/Jakarta"),
+
+		AuthSecret:         getEnv("AUTH_SECRET", ""),
+		AuthTokenTTL:       getEnvDuration("AUTH_TOKEN_TTL", 12*time.Hour),
+		AuthURLTokenTTL:    getEnvDuration("AUTH_URL_TOKEN_TTL", 15*time.Minute),
+		AdminUsername:      getEnv("ADMIN_USERNAME", "admin"),
+		AdminPassword:      getEnv("ADMIN_PASSWORD", ""),
+		CORSAllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:5173"),
 	}
 }
 
@@ -33,4 +178,60 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// defaultRecorderBackend memilih backend perekam sesuai sistem operasi
+func defaultRecorderBackend() string {
+	if runtime.GOOS == "windows" {
+		return "dshow"
+	}
+	return "alsa"
+}
@@ -28,7 +28,10 @@ func InitDB() (*gorm.DB, error) {
 		cfg.DBLoc,
 	)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		// Error driver seperti duplicate key diterjemahkan ke error gorm
+		TranslateError: true,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -41,12 +44,33 @@ func InitDB() (*gorm.DB, error) {
 func MigrateDB(db *gorm.DB) error {
 	err := db.AutoMigrate(
 		&models.Dosen{},
+		&models.Semester{},
+		&models.MataKuliah{},
+		&models.Ruangan{},
 		&models.Jadwal{},
+		&models.JadwalStatusLog{},
+		&models.Pertemuan{},
+		&models.HariLibur{},
 		&models.Evaluasi{},
+		&models.RekamanJob{},
+		&models.RekamanSesi{},
+		&models.RecordingAgent{},
+		&models.UploadSesi{},
+		&models.AudioFile{},
+		&models.User{},
+		&models.APIKey{},
+		&models.FeedKalender{},
+		&models.AuditLog{},
 	)
 	if err != nil {
 		return err
 	}
+	if err := migrasiMataKuliah(db); err != nil {
+		return err
+	}
+	if err := migrasiRuangan(db); err != nil {
+		return err
+	}
 	log.Println("Migrasi database MySQL selesai")
 	return nil
 }
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"log"
+
+	"CLAIRE/models"
+
+	"gorm.io/gorm"
+)
+
+// migrasiMataKuliah mengisi katalog mata kuliah dari NamaMatkul jadwal lama.
+// Nama yang sama setelah dinormalisasi (huruf besar/kecil, spasi) digabung
+// menjadi satu entri katalog. Aman dijalankan berulang kali karena hanya
+// memproses jadwal yang belum punya mata_kuliah_id.
+func migrasiMataKuliah(db *gorm.DB) error {
+	var jadwals []models.Jadwal
+	if err := db.Unscoped().Where("mata_kuliah_id IS NULL").Find(&jadwals).Error; err != nil {
+		return err
+	}
+	if len(jadwals) == 0 {
+		return nil
+	}
+
+	katalog := make(map[string]*models.MataKuliah)
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, jadwal := range jadwals {
+			kunci := models.NormalisasiNamaMatkul(jadwal.NamaMatkul)
+			if kunci == "" {
+				continue
+			}
+
+			matkul, ok := katalog[kunci]
+			if !ok {
+				var err error
+				matkul, err = models.CariAtauBuatMataKuliah(tx, jadwal.NamaMatkul)
+				if err != nil {
+					return err
+				}
+				katalog[kunci] = matkul
+			}
+
+			err := tx.Unscoped().Model(&models.Jadwal{}).Where("id = ?", jadwal.ID).
+				UpdateColumns(map[string]interface{}{
+					"mata_kuliah_id": matkul.ID,
+					"nama_matkul":    matkul.Nama,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Printf("Migrasi katalog mata kuliah: %d jadwal dihubungkan ke %d mata kuliah", len(jadwals), len(katalog))
+		return nil
+	})
+}
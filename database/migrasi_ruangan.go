@@ -0,0 +1,51 @@
+package database
+
+import (
+	"log"
+
+	"CLAIRE/models"
+
+	"gorm.io/gorm"
+)
+
+// migrasiRuangan mendaftarkan ruangan dari kolom teks Jadwal.Ruangan dan
+// menghubungkan jadwal ke ruangan tersebut. Hanya jadwal yang belum punya
+// ruangan_id yang diproses.
+func migrasiRuangan(db *gorm.DB) error {
+	var jadwals []models.Jadwal
+	err := db.Unscoped().Where("ruangan_id IS NULL AND ruangan IS NOT NULL AND ruangan <> ''").Find(&jadwals).Error
+	if err != nil {
+		return err
+	}
+	if len(jadwals) == 0 {
+		return nil
+	}
+
+	registry := make(map[string]*models.Ruangan)
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, jadwal := range jadwals {
+			kode := models.NormalisasiKodeRuangan(jadwal.Ruangan)
+			ruangan, ok := registry[kode]
+			if !ok {
+				var err error
+				ruangan, err = models.CariAtauBuatRuangan(tx, kode)
+				if err != nil {
+					return err
+				}
+				registry[kode] = ruangan
+			}
+
+			err := tx.Unscoped().Model(&models.Jadwal{}).Where("id = ?", jadwal.ID).
+				UpdateColumns(map[string]interface{}{
+					"ruangan_id": ruangan.ID,
+					"ruangan":    ruangan.Kode,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Printf("Migrasi ruangan: %d jadwal dihubungkan ke %d ruangan", len(jadwals), len(registry))
+		return nil
+	})
+}
@@ -1,23 +1,33 @@
-import { BrowserRouter as Router, Routes, Route } from 'react-router-dom';
+import { BrowserRouter as Router, Routes, Route, Navigate, useLocation } from 'react-router-dom';
 import Layout from './components/layout/Layout';
 import Dashboard from './pages/Dashboard';
 import DosenPage from './pages/DosenPage';
 import JadwalPage from './pages/JadwalPage';
 import EvaluasiPage from './pages/EvaluasiPage';
+import LoginPage from './pages/LoginPage';
+import { sesiAuth } from './services/api';
+
+// Halaman yang hanya bisa dibuka setelah login
+function RuteTerlindung({ children }) {
+  const location = useLocation();
+  if (!sesiAuth.token()) {
+    return <Navigate to="/login" replace state={{ dari: location.pathname }} />;
+  }
+  return <Layout>{children}</Layout>;
+}
 
 function App() {
   return (
     <Router>
-      <Layout>
-        <Routes>
-          <Route path="/" element={<Dashboard />} />
-          <Route path="/dosen" element={<DosenPage />} />
-          <Route path="/jadwal" element={<JadwalPage />} />
-          <Route path="/evaluasi" element={<EvaluasiPage />} />
-        </Routes>
-      </Layout>
+      <Routes>
+        <Route path="/login" element={<LoginPage />} />
+        <Route path="/" element={<RuteTerlindung><Dashboard /></RuteTerlindung>} />
+        <Route path="/dosen" element={<RuteTerlindung><DosenPage /></RuteTerlindung>} />
+        <Route path="/jadwal" element={<RuteTerlindung><JadwalPage /></RuteTerlindung>} />
+        <Route path="/evaluasi" element={<RuteTerlindung><EvaluasiPage /></RuteTerlindung>} />
+      </Routes>
     </Router>
   );
 }
 
-export default App;
\ No newline at end of file
+export default App;
@@ -1,6 +1,6 @@
 import { useState, useRef } from 'react';
 import { Edit2, Trash2, Mic, Play, Users, Volume2, Pause } from 'lucide-react';
-import { dosenAPI } from '../../services/api';
+import { dosenAPI, buatURLBertoken } from '../../services/api';
 import RekamSuaraModal from './RekamSuaraModal';
 
 export default function DosenList({ dosen, onEdit, onUpdate }) {
@@ -54,10 +54,8 @@ export default function DosenList({ dosen, onEdit, onUpdate }) {
           const dosenFolder = pathParts[1]; // Bagus_ST_MKom
           const filename = pathParts[2]; // e56a6e9b-fe2b-4c95-84c3-d130efc574e6.wav
           
-          // Buat URL sesuai endpoint yang benar
-          const audioUrl = `http://localhost:8080/api/v1/audio/${dosenFolder}/${filename}`;
-          
-          console.log('Playing audio from:', audioUrl); // Debug log
+          // URL dengan token sementara karena <audio> tidak mengirim header login
+          const audioUrl = await buatURLBertoken(`/audio/${dosenFolder}/${filename}`);
           
           const audio = new Audio(audioUrl);
           audioRef.current = audio;
@@ -245,6 +245,12 @@ export default function RekamSuaraModal({ dosen, onClose, onSuccess }) {
     return new Blob([wavBuffer], { type: 'audio/wav' });
   };
 
+  // Gabungkan pesan cek kualitas yang tidak lolos menjadi satu pesan error
+  const pesanKualitas = (kualitas) => {
+    const gagal = (kualitas?.cek || []).filter((cek) => !cek.lolos);
+    return `Sampel suara tidak memenuhi syarat kualitas: ${gagal.map((cek) => cek.pesan).join('; ')}`;
+  };
+
   const handleUpload = async () => {
     if (!audioBlob) return;
 
@@ -255,11 +261,22 @@ export default function RekamSuaraModal({ dosen, onClose, onSuccess }) {
       const audioFile = new File([audioBlob], `rekaman_${dosen.id}_${Date.now()}.wav`, { 
         type: 'audio/wav' 
       });
+
+      // Cek kualitas dulu agar rekaman yang buruk bisa langsung diulang
+      const cek = await dosenAPI.cekSampelSuara(audioFile);
+      if (!cek.data.kualitas.lolos) {
+        setError(pesanKualitas(cek.data.kualitas));
+        return;
+      }
       
       await dosenAPI.rekamSuara(dosen.id, audioFile);
       onSuccess();
     } catch (err) {
-      setError(err.response?.data?.error || 'Gagal mengupload rekaman');
+      if (err.response?.data?.kualitas) {
+        setError(pesanKualitas(err.response.data.kualitas));
+      } else {
+        setError(err.response?.data?.error || 'Gagal mengupload rekaman');
+      }
     } finally {
       setLoading(false);
     }
@@ -1,7 +1,16 @@
-import { Menu, Bell, User, Settings } from 'lucide-react';
-import { APP_CONFIG } from '../../services/api';
+import { useNavigate } from 'react-router-dom';
+import { Menu, Bell, User, Settings, LogOut } from 'lucide-react';
+import { APP_CONFIG, sesiAuth } from '../../services/api';
 
 export default function Header({ onMenuClick }) {
+  const navigate = useNavigate();
+  const user = sesiAuth.user();
+
+  const handleLogout = () => {
+    sesiAuth.hapus();
+    navigate('/login', { replace: true });
+  };
+
   return (
     <header className="bg-linear-to-r from-blue-50 to-cyan-50 shadow-sm border-b border-indigo-200">
       <div className="flex items-center justify-between px-4 py-3 md:px-6">
@@ -23,7 +32,19 @@ export default function Header({ onMenuClick }) {
         </div>
         
         <div className="flex items-center space-x-3">
-          
+          {user && (
+            <div className="hidden sm:flex items-center text-sm text-indigo-600">
+              <User className="h-4 w-4 mr-1" />
+              <span className="font-medium">{user.username}</span>
+            </div>
+          )}
+          <button
+            onClick={handleLogout}
+            className="flex items-center p-2 rounded-lg text-indigo-600 hover:bg-indigo-100 transition-colors duration-200"
+            title="Keluar"
+          >
+            <LogOut className="h-5 w-5" />
+          </button>
         </div>
       </div>
     </header>
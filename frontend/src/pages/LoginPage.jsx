@@ -0,0 +1,114 @@
+import { useState } from 'react';
+import { Navigate, useLocation, useNavigate } from 'react-router-dom';
+import { User, Lock, Loader, LogIn, AlertCircle } from 'lucide-react';
+import { authAPI, sesiAuth, APP_CONFIG } from '../services/api';
+
+export default function LoginPage() {
+  const navigate = useNavigate();
+  const location = useLocation();
+  const [formData, setFormData] = useState({ username: '', password: '' });
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+
+  // Kembali ke halaman yang diminta sebelum diarahkan ke login
+  const tujuan = location.state?.dari || '/';
+
+  if (sesiAuth.token()) {
+    return <Navigate to={tujuan} replace />;
+  }
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setLoading(true);
+    setError('');
+
+    try {
+      const response = await authAPI.login(formData.username, formData.password);
+      sesiAuth.simpan(response.data);
+      navigate(tujuan, { replace: true });
+    } catch (err) {
+      setError(err.response?.data?.error || 'Gagal login, coba lagi');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen flex items-center justify-center bg-linear-to-r from-blue-50 to-cyan-50 px-4">
+      <div className="w-full max-w-md bg-white rounded-2xl shadow-lg border border-indigo-100 p-8">
+        <div className="text-center mb-8">
+          <h1 className="text-2xl font-bold text-indigo-600">{APP_CONFIG.APP_TITLE}</h1>
+          <p className="text-sm text-gray-500 mt-1">Masuk untuk melanjutkan</p>
+        </div>
+
+        <form onSubmit={handleSubmit} className="space-y-6">
+          {error && (
+            <div className="bg-rose-50 border border-rose-200 text-rose-700 px-4 py-3 rounded-xl flex items-center space-x-3">
+              <AlertCircle className="h-5 w-5 shrink-0" />
+              <p className="text-sm font-medium">{error}</p>
+            </div>
+          )}
+
+          <div className="space-y-2">
+            <label className="block text-sm font-semibold text-gray-700">
+              Username
+            </label>
+            <div className="relative">
+              <div className="absolute inset-y-0 left-0 pl-3 flex items-center pointer-events-none">
+                <User className="h-5 w-5 text-gray-400" />
+              </div>
+              <input
+                type="text"
+                required
+                autoFocus
+                autoComplete="username"
+                value={formData.username}
+                onChange={(e) => setFormData({ ...formData, username: e.target.value })}
+                className="block w-full pl-10 pr-4 py-3 border border-gray-300 rounded-xl focus:ring-2 focus:ring-blue-500 focus:border-blue-500 transition-all duration-200 placeholder-gray-400"
+                placeholder="Masukkan username"
+              />
+            </div>
+          </div>
+
+          <div className="space-y-2">
+            <label className="block text-sm font-semibold text-gray-700">
+              Password
+            </label>
+            <div className="relative">
+              <div className="absolute inset-y-0 left-0 pl-3 flex items-center pointer-events-none">
+                <Lock className="h-5 w-5 text-gray-400" />
+              </div>
+              <input
+                type="password"
+                required
+                autoComplete="current-password"
+                value={formData.password}
+                onChange={(e) => setFormData({ ...formData, password: e.target.value })}
+                className="block w-full pl-10 pr-4 py-3 border border-gray-300 rounded-xl focus:ring-2 focus:ring-blue-500 focus:border-blue-500 transition-all duration-200 placeholder-gray-400"
+                placeholder="Masukkan password"
+              />
+            </div>
+          </div>
+
+          <button
+            type="submit"
+            disabled={loading}
+            className="w-full bg-blue-600 hover:bg-blue-700 disabled:bg-blue-400 text-white px-6 py-3 rounded-xl font-semibold flex items-center justify-center space-x-2 transition-all duration-200 hover:shadow-lg disabled:cursor-not-allowed"
+          >
+            {loading ? (
+              <>
+                <Loader className="h-4 w-4 animate-spin" />
+                <span>Memproses...</span>
+              </>
+            ) : (
+              <>
+                <LogIn className="h-4 w-4" />
+                <span>Masuk</span>
+              </>
+            )}
+          </button>
+        </form>
+      </div>
+    </div>
+  );
+}
@@ -16,6 +16,10 @@ const api = axios.create({
 api.interceptors.request.use(
     (config) => {
         console.log(`🚀 Making API request to: ${config.url}`);
+        const token = localStorage.getItem('token');
+        if (token) {
+            config.headers.Authorization = `Bearer ${token}`;
+        }
         return config;
     },
     (error) => {
@@ -30,10 +34,73 @@ api.interceptors.response.use(
     },
     (error) => {
         console.error('❌ API Error:', error.response?.data || error.message);
+        // Token kedaluwarsa atau dicabut: kembali ke halaman login
+        if (error.response?.status === 401 && !error.config?.url?.startsWith('/auth/login')) {
+            sesiAuth.hapus();
+            if (window.location.pathname !== '/login') {
+                window.location.assign('/login');
+            }
+        }
         return Promise.reject(error);
     }
 );
 
+// Penyimpanan token login di localStorage
+export const sesiAuth = {
+    token: () => localStorage.getItem('token'),
+    user: () => {
+        try {
+            return JSON.parse(localStorage.getItem('user')) || null;
+        } catch {
+            return null;
+        }
+    },
+    simpan: ({ token, user }) => {
+        localStorage.setItem('token', token);
+        localStorage.setItem('user', JSON.stringify(user));
+    },
+    hapus: () => {
+        localStorage.removeItem('token');
+        localStorage.removeItem('user');
+    },
+};
+
+// Auth API
+export const authAPI = {
+    login: (username, password) => api.post('/auth/login', { username, password }),
+    gantiPassword: (data) => api.post('/auth/ganti-password', data),
+    // Token sementara yang hanya berlaku untuk satu path
+    tokenURL: (path) => api.post('/auth/token-url', { path }),
+};
+
+// URL lengkap dengan token sementara untuk file yang dibuka langsung dari
+// browser (misalnya <audio> atau feed kalender), karena elemen tersebut
+// tidak bisa mengirim header Authorization. Untuk langganan kalender yang
+// dipakai terus-menerus gunakan feedKalenderAPI.
+export const buatURLBertoken = async (path) => {
+    const url = new URL(API_BASE_URL + path);
+    const response = await authAPI.tokenURL(url.pathname);
+    return url.origin + response.data.url;
+};
+
+// Token langganan feed kalender (.ics) yang berlaku sampai dicabut
+export const feedKalenderAPI = {
+    create: (path, nama) => api.post('/kalender/feed', { path, nama }),
+    getAll: (params) => api.get('/kalender/feed', { params }),
+    cabut: (id) => api.delete(`/kalender/feed/${id}`),
+};
+
+// Portal dosen API
+export const meAPI = {
+    getProfil: () => api.get('/me'),
+    getJadwal: (params) => api.get('/me/jadwal', { params }),
+    getEvaluasi: (params) => api.get('/me/evaluasi', { params }),
+    getTren: (params) => api.get('/me/evaluasi/tren', { params }),
+    rekamSuara: (formData) => api.post('/me/rekam-suara', formData, {
+        headers: { 'Content-Type': 'multipart/form-data' },
+    }),
+};
+
 // Dosen API
 export const dosenAPI = {
     getAll: () => api.get('/dosen'),
@@ -51,6 +118,14 @@ export const dosenAPI = {
         return api.post(`/dosen/${id}/rekam-suara`, formData, {
             headers: { 'Content-Type': 'multipart/form-data' }
         });
+    },
+    // Laporan kualitas sampel suara tanpa menyimpannya
+    cekSampelSuara: (audioFile) => {
+        const formData = new FormData();
+        formData.append('audio_data', audioFile);
+        return api.post('/sampel-suara/cek', formData, {
+            headers: { 'Content-Type': 'multipart/form-data' }
+        });
     }
 };
 
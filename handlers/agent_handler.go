@@ -0,0 +1,524 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"CLAIRE/auth"
+	"CLAIRE/database"
+	"CLAIRE/models"
+	"CLAIRE/recording"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// batasChunkAgen membatasi ukuran satu potongan upload rekaman dari agen
+const batasChunkAgen = 32 << 20
+
+// agenRequest memuat agen yang terikat ke API key request. Mengirim response
+// error dan mengembalikan nil jika request bukan dari agen terdaftar.
+func agenRequest(c *gin.Context) *models.RecordingAgent {
+	key := auth.APIKeyRequest(c)
+	if key == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Endpoint agen hanya bisa dipakai dengan API key agen"})
+		return nil
+	}
+
+	var agent models.RecordingAgent
+	if err := database.GetDB().First(&agent, "api_key_id = ?", key.ID).Error; err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Agen belum terdaftar, panggil /agent/register terlebih dahulu"})
+		return nil
+	}
+	return &agent
+}
+
+// paketJobAgen menggabungkan job dengan ruangan dan perangkat perekamnya
+// supaya agen tahu perangkat mana yang dipakai
+func paketJobAgen(db *gorm.DB, job *models.RekamanJob) gin.H {
+	paket := gin.H{"job": job, "ruangan": nil}
+	if job.Jadwal.RuanganID != nil {
+		var ruangan models.Ruangan
+		if err := db.First(&ruangan, "id = ?", *job.Jadwal.RuanganID).Error; err == nil {
+			paket["ruangan"] = ruangan
+		}
+	}
+	return paket
+}
+
+// Handler untuk mendaftarkan agen perekam. Dipanggil agen setiap kali
+// start; API key yang dipakai menjadi identitas agen. Ruangan yang diminta
+// hanya diberikan jika belum dilayani agen lain.
+func RegisterAgen(c *gin.Context) {
+	key := auth.APIKeyRequest(c)
+	if key == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Endpoint agen hanya bisa dipakai dengan API key agen"})
+		return
+	}
+
+	var input struct {
+		Nama     string   `json:"nama" binding:"required"`
+		Hostname string   `json:"hostname"`
+		Versi    string   `json:"versi"`
+		Recorder string   `json:"recorder"`
+		Ruangan  []string `json:"ruangan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	now := time.Now()
+	// Agen yang pernah dihapus didaftarkan ulang dengan ID yang sama
+	var agent models.RecordingAgent
+	err := db.Unscoped().First(&agent, "api_key_id = ?", key.ID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	agent.DeletedAt = gorm.DeletedAt{}
+	agent.APIKeyID = key.ID
+	agent.Nama = strings.TrimSpace(input.Nama)
+	agent.Hostname = input.Hostname
+	agent.Versi = input.Versi
+	agent.Recorder = input.Recorder
+	agent.IP = c.ClientIP()
+	agent.StatusKerja = "idle"
+	agent.TerakhirTerlihat = &now
+	if err := db.Unscoped().Save(&agent).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ditolak := map[string]string{}
+	for _, kode := range input.Ruangan {
+		kode = models.NormalisasiKodeRuangan(kode)
+		if kode == "" {
+			continue
+		}
+		var ruangan models.Ruangan
+		if err := db.Where("UPPER(kode) = ?", kode).First(&ruangan).Error; err != nil {
+			ditolak[kode] = "ruangan tidak terdaftar"
+			continue
+		}
+		if ruangan.AgentID != "" && ruangan.AgentID != agent.ID.String() {
+			ditolak[kode] = "ruangan sudah dilayani agen lain"
+			continue
+		}
+		db.Model(&models.Ruangan{}).Where("id = ?", ruangan.ID).Update("agent_id", agent.ID.String())
+	}
+
+	var ruangan []models.Ruangan
+	db.Where("agent_id = ?", agent.ID.String()).Order("kode ASC").Find(&ruangan)
+	for _, r := range ruangan {
+		agent.Ruangan = append(agent.Ruangan, r.Kode)
+	}
+	agent.Online = true
+
+	c.JSON(http.StatusOK, gin.H{
+		"agent":           agent,
+		"ruangan":         ruangan,
+		"ruangan_ditolak": ditolak,
+		"batas_offline":   int(recordingQueue.BatasOfflineAgen().Seconds()),
+	})
+}
+
+// Handler untuk heartbeat agen. Agen melaporkan job yang sedang
+// dikerjakannya dan menerima daftar job yang harus dihentikan.
+func HeartbeatAgen(c *gin.Context) {
+	agent := agenRequest(c)
+	if agent == nil {
+		return
+	}
+
+	var input struct {
+		StatusKerja string   `json:"status_kerja"`
+		JobAktif    []string `json:"job_aktif"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	aktif := make([]uuid.UUID, 0, len(input.JobAktif))
+	for _, value := range input.JobAktif {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID job tidak valid: " + value})
+			return
+		}
+		aktif = append(aktif, id)
+	}
+	status := input.StatusKerja
+	if status == "" {
+		status = "idle"
+		if len(aktif) > 0 {
+			status = "recording"
+		}
+	}
+
+	now := time.Now()
+	result := database.GetDB().Model(&models.RecordingAgent{}).Where("id = ?", agent.ID).Updates(map[string]interface{}{
+		"terakhir_terlihat": now,
+		"ip":                c.ClientIP(),
+		"status_kerja":      status,
+		"jumlah_job_aktif":  len(aktif),
+		"tanggal_diupdate":  now,
+	})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	hentikan, err := recordingQueue.JobPerluBerhenti(agent, aktif)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hentikan":     hentikan,
+		"waktu_server": now,
+	})
+}
+
+// Handler untuk mengambil job berikutnya. ?aktif=<id,id> berisi job yang
+// sedang dikerjakan agen; job lain yang masih tercatat berjalan di agen ini
+// (agen restart atau response sebelumnya hilang) dikembalikan lebih dulu.
+// ?wait=<detik> menahan request sampai ada job (long-poll), dibatasi
+// AGENT_LONG_POLL_MAX. Mengembalikan 204 jika tidak ada job.
+func AmbilJobAgen(c *gin.Context) {
+	agent := agenRequest(c)
+	if agent == nil {
+		return
+	}
+	db := database.GetDB()
+
+	aktif := map[string]bool{}
+	for _, id := range strings.Split(c.Query("aktif"), ",") {
+		aktif[strings.TrimSpace(id)] = true
+	}
+	berjalan, err := recordingQueue.JobBerjalanAgen(agent)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range berjalan {
+		if !aktif[berjalan[i].ID.String()] {
+			c.JSON(http.StatusOK, paketJobAgen(db, &berjalan[i]))
+			return
+		}
+	}
+
+	wait := time.Duration(0)
+	if value := c.Query("wait"); value != "" {
+		detik, err := strconv.Atoi(value)
+		if err != nil || detik < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter wait harus berupa jumlah detik"})
+			return
+		}
+		wait = time.Duration(detik) * time.Second
+	}
+	if batas := recordingQueue.BatasLongPollAgen(); wait > batas {
+		wait = batas
+	}
+
+	job, err := recordingQueue.ClaimForAgent(c.Request.Context(), agent, wait)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if job == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, paketJobAgen(db, job))
+}
+
+// jobSesiAgen memuat job milik agen dan nomor sesi dari parameter route
+func jobSesiAgen(c *gin.Context, agent *models.RecordingAgent) (*models.RekamanJob, int, bool) {
+	job, err := recordingQueue.JobAgen(agent, c.Param("job_id"))
+	if err != nil {
+		c.JSON(agenErrorStatus(err), gin.H{"error": err.Error()})
+		return nil, 0, false
+	}
+	nomor, err := strconv.Atoi(c.Param("nomor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nomor sesi tidak valid"})
+		return nil, 0, false
+	}
+	return job, nomor, true
+}
+
+// agenErrorStatus memetakan error protokol agen ke HTTP status
+func agenErrorStatus(err error) int {
+	var offsetErr *recording.ErrOffsetUpload
+	switch {
+	case errors.Is(err, recording.ErrSesiTidakDitemukan):
+		return http.StatusNotFound
+	case errors.Is(err, recording.ErrBukanJobAgen),
+		errors.Is(err, recording.ErrStatusSesiAgen),
+		errors.Is(err, recording.ErrDihentikan),
+		errors.As(err, &offsetErr):
+		return http.StatusConflict
+	case errors.Is(err, recording.ErrUkuranUpload):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Handler untuk laporan status sesi dari agen: "recording" saat mulai
+// merekam, "failed" jika perekaman gagal
+func UpdateSesiAgen(c *gin.Context) {
+	agent := agenRequest(c)
+	if agent == nil {
+		return
+	}
+	job, nomor, ok := jobSesiAgen(c, agent)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Status string `json:"status" binding:"required"`
+		Pesan  string `json:"pesan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var sesi *models.RekamanSesi
+	var err error
+	switch input.Status {
+	case models.SesiStatusRecording:
+		sesi, err = recordingQueue.MulaiSesiAgen(job, nomor)
+	case models.SesiStatusFailed:
+		sesi, err = recordingQueue.GagalSesiAgen(job, nomor, input.Pesan)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status tidak valid. Gunakan: recording, failed"})
+		return
+	}
+	if err != nil {
+		c.JSON(agenErrorStatus(err), gin.H{"error": err.Error(), "stop_diminta": job.StopDiminta})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sesi": sesi, "stop_diminta": job.StopDiminta})
+}
+
+// Handler HEAD untuk menanyakan berapa byte rekaman sesi yang sudah
+// diterima, supaya agen bisa melanjutkan upload yang terputus
+func OffsetAudioAgen(c *gin.Context) {
+	agent := agenRequest(c)
+	if agent == nil {
+		return
+	}
+	job, nomor, ok := jobSesiAgen(c, agent)
+	if !ok {
+		return
+	}
+
+	offset, selesai, err := recordingQueue.OffsetUploadAgen(job, nomor)
+	if err != nil {
+		c.Status(agenErrorStatus(err))
+		return
+	}
+	c.Header("Upload-Offset", strconv.FormatInt(offset, 10))
+	c.Header("Upload-Complete", strconv.FormatBool(selesai))
+	c.Status(http.StatusOK)
+}
+
+// Handler untuk upload satu potongan rekaman sesi. Header Content-Range
+// "bytes <awal>-<akhir>/<total>" wajib ada; ?parsial=true menandai rekaman
+// yang dihentikan sebelum durasi sesi selesai.
+func UploadAudioAgen(c *gin.Context) {
+	agent := agenRequest(c)
+	if agent == nil {
+		return
+	}
+	job, nomor, ok := jobSesiAgen(c, agent)
+	if !ok {
+		return
+	}
+
+	awal, akhir, total, err := parseContentRange(c.GetHeader("Content-Range"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if akhir-awal+1 > batasChunkAgen {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Ukuran chunk maksimal %d byte", batasChunkAgen)})
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, akhir-awal+1)
+	parsial := c.Query("parsial") == "true"
+	offset, selesai, err := recordingQueue.TulisChunkAgen(job, nomor, awal, akhir-awal+1, total, parsial, body)
+	c.Header("Upload-Offset", strconv.FormatInt(offset, 10))
+	var offsetErr *recording.ErrOffsetUpload
+	if errors.As(err, &offsetErr) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "offset": offsetErr.Offset})
+		return
+	}
+	if err != nil {
+		c.JSON(agenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"offset":       offset,
+		"selesai":      selesai,
+		"stop_diminta": job.StopDiminta,
+	})
+}
+
+// parseContentRange membaca header "bytes <awal>-<akhir>/<total>"
+func parseContentRange(header string) (awal, akhir, total int64, err error) {
+	invalid := errors.New("Header Content-Range harus berformat bytes <awal>-<akhir>/<total>")
+	if !strings.HasPrefix(header, "bytes ") {
+		return 0, 0, 0, invalid
+	}
+	rentang, totalStr, ok := strings.Cut(strings.TrimPrefix(header, "bytes "), "/")
+	if !ok {
+		return 0, 0, 0, invalid
+	}
+	awalStr, akhirStr, ok := strings.Cut(rentang, "-")
+	if !ok {
+		return 0, 0, 0, invalid
+	}
+	if awal, err = strconv.ParseInt(awalStr, 10, 64); err != nil {
+		return 0, 0, 0, invalid
+	}
+	if akhir, err = strconv.ParseInt(akhirStr, 10, 64); err != nil {
+		return 0, 0, 0, invalid
+	}
+	if total, err = strconv.ParseInt(totalStr, 10, 64); err != nil {
+		return 0, 0, 0, invalid
+	}
+	if awal < 0 || akhir < awal || akhir >= total {
+		return 0, 0, 0, invalid
+	}
+	return awal, akhir, total, nil
+}
+
+// Handler yang dipanggil agen setelah semua sesi job direkam dan diunggah.
+// Analisis berjalan di server sehingga response dikirim sebelum selesai.
+func SelesaiJobAgen(c *gin.Context) {
+	agent := agenRequest(c)
+	if agent == nil {
+		return
+	}
+	job, err := recordingQueue.JobAgen(agent, c.Param("job_id"))
+	if err != nil {
+		c.JSON(agenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	var input struct {
+		Pesan string `json:"pesan"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := recordingQueue.SelesaiAgen(job, input.Pesan); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Job diterima, analisis rekaman berjalan di server"})
+}
+
+// Handler untuk daftar agen perekam beserta status online dan ruangannya
+func DapatkanSemuaAgen(c *gin.Context) {
+	db := database.GetDB()
+	var agents []models.RecordingAgent
+	result := db.Order("nama ASC").Find(&agents)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	var ruangan []models.Ruangan
+	db.Where("agent_id <> ''").Order("kode ASC").Find(&ruangan)
+	perAgen := map[string][]string{}
+	for _, r := range ruangan {
+		perAgen[r.AgentID] = append(perAgen[r.AgentID], r.Kode)
+	}
+
+	now := time.Now()
+	timeout := recordingQueue.BatasOfflineAgen()
+	for i := range agents {
+		agents[i].Online = agents[i].OnlinePada(now, timeout)
+		agents[i].Ruangan = perAgen[agents[i].ID.String()]
+	}
+
+	c.JSON(http.StatusOK, agents)
+}
+
+// Handler untuk menghapus agen. Ruangannya dilepas sehingga kembali direkam
+// server; API key agen sebaiknya juga dicabut.
+func HapusAgen(c *gin.Context) {
+	id := c.Param("id")
+
+	db := database.GetDB()
+	var agent models.RecordingAgent
+	if err := db.First(&agent, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Agen tidak ditemukan"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Ruangan{}).Where("agent_id = ?", agent.ID.String()).Update("agent_id", "").Error; err != nil {
+			return err
+		}
+		return tx.Delete(&agent).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Agen berhasil dihapus"})
+}
+
+// isiStatusAgenRuangan mengisi agent_online untuk ruangan yang memakai agen
+func isiStatusAgenRuangan(db *gorm.DB, ruangan []models.Ruangan) {
+	ids := []string{}
+	for _, r := range ruangan {
+		if r.AgentID != "" {
+			ids = append(ids, r.AgentID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	var agents []models.RecordingAgent
+	db.Where("id IN ?", ids).Find(&agents)
+	now := time.Now()
+	timeout := recordingQueue.BatasOfflineAgen()
+	online := map[string]bool{}
+	for _, agent := range agents {
+		online[agent.ID.String()] = agent.OnlinePada(now, timeout)
+	}
+
+	for i := range ruangan {
+		if ruangan[i].AgentID != "" {
+			status := online[ruangan[i].AgentID]
+			ruangan[i].AgentOnline = &status
+		}
+	}
+}
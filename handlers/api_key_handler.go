@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"CLAIRE/auth"
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler untuk membuat API key baru. Kunci asli hanya dikirim di response
+// ini dan tidak bisa dilihat lagi.
+func BuatAPIKey(c *gin.Context) {
+	var input struct {
+		Nama            string     `json:"nama" binding:"required"`
+		Scope           []string   `json:"scope" binding:"required"`
+		KedaluwarsaPada *time.Time `json:"kedaluwarsa_pada"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(input.Scope) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimal satu scope wajib diisi"})
+		return
+	}
+	for _, scope := range input.Scope {
+		if !auth.ScopeValid(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Scope tidak valid. Gunakan: analisis, rekaman, agen"})
+			return
+		}
+	}
+	if input.KedaluwarsaPada != nil && !input.KedaluwarsaPada.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kedaluwarsa_pada harus di masa depan"})
+		return
+	}
+
+	kunci, prefix, err := auth.BuatAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	key := models.APIKey{
+		Nama:            strings.TrimSpace(input.Nama),
+		Prefix:          prefix,
+		HashKunci:       auth.HashAPIKey(kunci),
+		Scope:           strings.Join(input.Scope, ","),
+		DibuatOleh:      aktorRequest(c),
+		KedaluwarsaPada: input.KedaluwarsaPada,
+	}
+	if err := database.GetDB().Create(&key).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"api_key": key,
+		"kunci":   kunci,
+		"message": "Simpan kunci ini sekarang; kunci tidak akan ditampilkan lagi",
+	})
+}
+
+// Handler untuk daftar API key. Gunakan ?aktif=true untuk hanya menampilkan
+// kunci yang belum dicabut.
+func DapatkanSemuaAPIKey(c *gin.Context) {
+	query := database.GetDB()
+	if c.Query("aktif") == "true" {
+		query = query.Where("dicabut_pada IS NULL")
+	}
+
+	var keys []models.APIKey
+	result := query.Order("tanggal_dibuat DESC").Find(&keys)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// Handler untuk mencabut API key. Data kunci tetap disimpan untuk jejak
+// pemakaian.
+func CabutAPIKey(c *gin.Context) {
+	id := c.Param("id")
+
+	var key models.APIKey
+	db := database.GetDB()
+	if err := db.First(&key, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key tidak ditemukan"})
+		return
+	}
+	if key.DicabutPada != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "API key sudah dicabut"})
+		return
+	}
+
+	now := time.Now()
+	result := db.Model(&models.APIKey{}).Where("id = ?", key.ID).Updates(map[string]interface{}{
+		"dicabut_pada":     now,
+		"tanggal_diupdate": now,
+	})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	key.DicabutPada = &now
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key berhasil dicabut", "api_key": key})
+}
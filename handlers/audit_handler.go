@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"CLAIRE/audit"
+	"CLAIRE/database"
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// batasEksporAudit membatasi jumlah baris satu kali ekspor
+const batasEksporAudit = 50000
+
+// sumberAudit mengambil pelaku, route dan IP dari request
+func sumberAudit(c *gin.Context) audit.Sumber {
+	return audit.Sumber{
+		Aktor:  aktorRequest(c),
+		Metode: c.Request.Method,
+		Route:  c.FullPath(),
+		Path:   c.Request.URL.Path,
+		IP:     c.ClientIP(),
+	}
+}
+
+// catatAudit mencatat operasi pada entitas. Kegagalan menyimpan audit hanya
+// dicatat di log karena perubahan datanya sudah tersimpan.
+func catatAudit(c *gin.Context, jenis string, entitasID interface{}, aksi string, sebelum, sesudah interface{}) {
+	id := fmt.Sprint(entitasID)
+	if err := audit.Catat(database.GetDB(), sumberAudit(c), jenis, id, aksi, sebelum, sesudah); err != nil {
+		log.Printf("Gagal mencatat audit %s %s %s: %v", aksi, jenis, id, err)
+	}
+}
+
+// catatAuditUbah memuat ulang entitas setelah diubah lalu mencatat selisihnya
+// dengan kondisi sebelumnya. sesudah harus pointer ke model kosong.
+func catatAuditUbah(c *gin.Context, jenis string, entitasID interface{}, sebelum, sesudah interface{}) {
+	if err := database.GetDB().First(sesudah, "id = ?", entitasID).Error; err != nil {
+		log.Printf("Gagal memuat %s %v untuk audit: %v", jenis, entitasID, err)
+		return
+	}
+	catatAudit(c, jenis, entitasID, audit.AksiUbah, sebelum, sesudah)
+}
+
+// filterAudit menerapkan filter query string ke query audit log
+func filterAudit(c *gin.Context, query *gorm.DB) (*gorm.DB, error) {
+	if jenis := c.Query("jenis_entitas"); jenis != "" {
+		query = query.Where("jenis_entitas = ?", jenis)
+	}
+	if entitasID := c.Query("entitas_id"); entitasID != "" {
+		query = query.Where("entitas_id = ?", entitasID)
+	}
+	if aksi := c.Query("aksi"); aksi != "" {
+		query = query.Where("aksi = ?", aksi)
+	}
+	if aktor := c.Query("aktor"); aktor != "" {
+		query = query.Where("aktor = ?", aktor)
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if dari := c.Query("dari"); dari != "" {
+		tanggal, err := kalender.ParseTanggal(dari)
+		if err != nil {
+			return nil, fmt.Errorf("Format tanggal dari tidak valid")
+		}
+		query = query.Where("tanggal_dibuat >= ?", tanggal)
+	}
+	if sampai := c.Query("sampai"); sampai != "" {
+		tanggal, err := kalender.ParseTanggal(sampai)
+		if err != nil {
+			return nil, fmt.Errorf("Format tanggal sampai tidak valid")
+		}
+		query = query.Where("tanggal_dibuat < ?", tanggal.AddDate(0, 0, 1))
+	}
+	return query, nil
+}
+
+// Handler untuk melihat audit log. Filter: jenis_entitas, entitas_id, aksi,
+// aktor, ip, dari, sampai; paginasi dengan limit (default 100) dan offset.
+func DapatkanAuditLog(c *gin.Context) {
+	query, err := filterAudit(c, database.GetDB().Model(&models.AuditLog{}))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 100
+	if value := c.Query("limit"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 && parsed <= 1000 {
+			limit = parsed
+		}
+	}
+	offset := 0
+	if value := c.Query("offset"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var logs []models.AuditLog
+	result := query.Order("tanggal_dibuat DESC").Limit(limit).Offset(offset).Find(&logs)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+		"data":   logs,
+	})
+}
+
+// Handler untuk ekspor audit log dengan filter yang sama. Format csv
+// (default) atau json.
+func EksporAuditLog(c *gin.Context) {
+	query, err := filterAudit(c, database.GetDB())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format ekspor tidak valid. Gunakan: csv, json"})
+		return
+	}
+
+	var logs []models.AuditLog
+	result := query.Order("tanggal_dibuat ASC").Limit(batasEksporAudit).Find(&logs)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	if format == "json" {
+		c.Header("Content-Disposition", `attachment; filename="audit-log.json"`)
+		c.JSON(http.StatusOK, logs)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="audit-log.csv"`)
+	c.Status(http.StatusOK)
+
+	loc := kalender.Default().Lokasi()
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"waktu", "aktor", "aksi", "jenis_entitas", "entitas_id", "metode", "route", "path", "ip", "perubahan"})
+	for _, entri := range logs {
+		w.Write([]string{
+			entri.TanggalDibuat.In(loc).Format("2006-01-02 15:04:05"),
+			entri.Aktor,
+			entri.Aksi,
+			entri.JenisEntitas,
+			entri.EntitasID,
+			entri.Metode,
+			entri.Route,
+			entri.Path,
+			entri.IP,
+			string(entri.Perubahan),
+		})
+	}
+	w.Flush()
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"CLAIRE/auth"
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var authSigner *auth.Signer
+
+// SetAuthSigner memasang penandatangan token untuk endpoint login
+func SetAuthSigner(signer *auth.Signer) {
+	authSigner = signer
+}
+
+// Handler untuk login dengan username dan password
+func Login(c *gin.Context) {
+	var input struct {
+		Username string `json:"username" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	db := database.GetDB()
+	err := db.Preload("Dosen").First(&user, "username = ?", strings.TrimSpace(input.Username)).Error
+	if err != nil || !auth.CekPassword(user.PasswordHash, input.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Username atau password salah"})
+		return
+	}
+	if !user.Aktif {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Akun tidak aktif"})
+		return
+	}
+
+	token, expires, err := authSigner.Issue(auth.Claims{
+		Subject:    user.ID.String(),
+		Username:   user.Username,
+		VersiToken: user.VersiToken,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	db.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumn("login_terakhir", now)
+	user.LoginTerakhir = &now
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"expires_at": expires,
+		"user":       user,
+		"izin":       auth.IzinRole(user.Role),
+	})
+}
+
+// Handler untuk membuat token URL sementara bagi satu path, dipakai untuk
+// file audio dan feed kalender yang dibuka langsung dari browser sehingga
+// tidak bisa mengirim header Authorization
+func BuatTokenURL(c *gin.Context) {
+	var input struct {
+		Path string `json:"path" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !pathTokenURL(input.Path) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token URL hanya tersedia untuk file audio dan feed kalender"})
+		return
+	}
+
+	user := auth.Pengguna(c)
+	token, expires, err := authSigner.IssueURL(auth.Claims{
+		Subject:    user.ID.String(),
+		Username:   user.Username,
+		VersiToken: user.VersiToken,
+	}, input.Path)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"expires_at": expires,
+		"url":        input.Path + "?token=" + url.QueryEscape(token),
+	})
+}
+
+// pathTokenURL mengecek apakah path termasuk route yang menerima token URL
+// (lihat auth.TokenURL di main.go)
+func pathTokenURL(p string) bool {
+	if p != path.Clean(p) || !strings.HasPrefix(p, "/api/v1/") {
+		return false
+	}
+	rute := strings.TrimPrefix(p, "/api/v1")
+	return strings.HasPrefix(rute, "/audio/") ||
+		strings.HasPrefix(rute, "/audio-evaluasi/") ||
+		strings.HasSuffix(rute, "/kalender.ics")
+}
+
+// Handler untuk mengganti password sendiri. Semua token lama ikut dicabut.
+func GantiPassword(c *gin.Context) {
+	var input struct {
+		PasswordLama string `json:"password_lama" binding:"required"`
+		PasswordBaru string `json:"password_baru" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := auth.Pengguna(c)
+	if !auth.CekPassword(user.PasswordHash, input.PasswordLama) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password lama salah"})
+		return
+	}
+	hash, err := auth.HashPassword(input.PasswordBaru)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := database.GetDB().Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"password_hash":    hash,
+		"versi_token":      gorm.Expr("versi_token + 1"),
+		"tanggal_diupdate": time.Now(),
+	})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password berhasil diganti, silakan login ulang"})
+}
+
+// aktorRequest menentukan pelaku perubahan status dari request
+func aktorRequest(c *gin.Context) string {
+	if user := auth.Pengguna(c); user != nil {
+		return user.Username
+	}
+	if key := auth.APIKeyRequest(c); key != nil {
+		return "apikey:" + key.Nama
+	}
+	return models.AktorPengguna
+}
+
+// dosenPengguna mengembalikan ID dosen jika yang login ber-role dosen.
+// Akun dosen yang belum terhubung ke data dosen mendapat uuid.Nil sehingga
+// tidak melihat data apa pun.
+func dosenPengguna(c *gin.Context) (uuid.UUID, bool) {
+	user := auth.Pengguna(c)
+	if user == nil || user.Role != models.RoleDosen {
+		return uuid.Nil, false
+	}
+	if user.DosenID == nil {
+		return uuid.Nil, true
+	}
+	return *user.DosenID, true
+}
+
+// lingkupEvaluasi membatasi query evaluasi ke jadwal milik dosen yang login
+func lingkupEvaluasi(c *gin.Context, query *gorm.DB) *gorm.DB {
+	return lingkupKolomJadwal(c, query, "jadwal_id")
+}
+
+// lingkupKolomJadwal membatasi query dengan kolom jadwal_id (misalnya
+// evaluasi atau pertemuan) ke jadwal milik dosen yang login
+func lingkupKolomJadwal(c *gin.Context, query *gorm.DB, kolom string) *gorm.DB {
+	dosenID, ok := dosenPengguna(c)
+	if !ok {
+		return query
+	}
+	jadwalDosen := database.GetDB().Model(&models.Jadwal{}).Select("id").Where("dosen_id = ?", dosenID)
+	return query.Where(kolom+" IN (?)", jadwalDosen)
+}
+
+// lingkupJadwal membatasi query jadwal ke jadwal milik dosen yang login
+func lingkupJadwal(c *gin.Context, query *gorm.DB) *gorm.DB {
+	dosenID, ok := dosenPengguna(c)
+	if !ok {
+		return query
+	}
+	return query.Where("dosen_id = ?", dosenID)
+}
+
+// bolehAksesDosen mengecek apakah pengguna boleh mengubah data milik dosen
+// tertentu: pengelola data boleh semua dosen, akun dosen hanya dirinya sendiri
+func bolehAksesDosen(c *gin.Context, dosenID string) bool {
+	user := auth.Pengguna(c)
+	if user == nil {
+		return false
+	}
+	if auth.Punya(user.Role, auth.IzinKelolaData) {
+		return true
+	}
+	return user.DosenID != nil && user.DosenID.String() == dosenID
+}
@@ -2,13 +2,14 @@ package handlers
 
 import (
 	"fmt"
+	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 
+	"CLAIRE/audit"
 	"CLAIRE/database"
 	"CLAIRE/models"
+	"CLAIRE/storage"
 	"CLAIRE/utils"
 
 	"github.com/gin-gonic/gin"
@@ -34,6 +35,7 @@ func BuatDosen(c *gin.Context) {
 	// Generate nama folder dosen
 	dosenFolder := utils.GenerateDosenFolderName(nama, gelar)
 	var pathSampleSuara string
+	var berkasAudio *models.AudioFile
 
 	// Handle file upload jika ada
 	if err == nil && file != nil {
@@ -43,12 +45,13 @@ func BuatDosen(c *gin.Context) {
 			return
 		}
 
-		// Simpan file audio
-		pathSampleSuara, err = utils.SaveAudioFile(file, AudioUploadDirdosen, dosenFolder)
+		// Simpan file audio yang lolos syarat kualitas
+		berkasAudio, err = simpanFileSampelSuara(c, file, dosenFolder)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Gagal menyimpan file audio: %v", err)})
+			kirimGagalSampelSuara(c, err)
 			return
 		}
+		pathSampleSuara = berkasAudio.Path
 	} else if err != nil && err != http.ErrMissingFile {
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Error upload file: %v", err)})
 		return
@@ -67,13 +70,16 @@ func BuatDosen(c *gin.Context) {
 	if result.Error != nil {
 		// Hapus file yang sudah diupload jika gagal simpan ke database
 		if pathSampleSuara != "" {
-			utils.DeleteAudioFile(pathSampleSuara)
-			// Hapus folder dosen jika kosong
-			utils.DeleteDosenFolder(AudioUploadDirdosen, dosenFolder)
+			hapusFileAudio(c, pathSampleSuara)
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
 	}
+	catatAudit(c, audit.EntitasDosen, dosen.ID, audit.AksiBuat, nil, dosen)
+	if berkasAudio != nil {
+		catatMetadataAudio(berkasAudio)
+		dosen.Audio = berkasAudio
+	}
 
 	c.JSON(http.StatusCreated, dosen)
 }
@@ -82,7 +88,7 @@ func DapatkanSemuaDosen(c *gin.Context) {
 	var dosen []models.Dosen
 	
 	db := database.GetDB()
-	result := db.Find(&dosen)
+	result := db.Preload("Audio").Find(&dosen)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
@@ -96,7 +102,7 @@ func DapatkanDosen(c *gin.Context) {
 	
 	var dosen models.Dosen
 	db := database.GetDB()
-	result := db.First(&dosen, "id = ?", id)
+	result := db.Preload("Audio").First(&dosen, "id = ?", id)
 	if result.Error != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Dosen tidak ditemukan"})
 		return
@@ -123,6 +129,7 @@ func UpdateDosen(c *gin.Context) {
 	file, err := c.FormFile("sample_suara")
 
 	updateData := make(map[string]interface{})
+	var berkasAudio *models.AudioFile
 	
 	// Generate nama folder baru jika nama/gelar berubah
 	var newDosenFolder string
@@ -160,27 +167,34 @@ func UpdateDosen(c *gin.Context) {
 			dosenFolder = newDosenFolder
 		}
 
-		// Hapus file lama jika ada
-		if existingDosen.PathSampleSuara != "" {
-			utils.DeleteAudioFile(existingDosen.PathSampleSuara)
-		}
-
-		// Simpan file baru
-		pathSampleSuara, err := utils.SaveAudioFile(file, AudioUploadDirdosen, dosenFolder)
+		// Simpan file baru yang lolos syarat kualitas
+		berkasAudio, err = simpanFileSampelSuara(c, file, dosenFolder)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Gagal menyimpan file audio: %v", err)})
+			kirimGagalSampelSuara(c, err)
 			return
 		}
-		updateData["path_sample_suara"] = pathSampleSuara
+		updateData["path_sample_suara"] = berkasAudio.Path
 	}
 
 	// Update data dosen
 	if len(updateData) > 0 {
 		result = db.Model(&models.Dosen{}).Where("id = ?", id).Updates(updateData)
 		if result.Error != nil {
+			// Hapus file yang sudah diupload jika gagal update database
+			if pathBaru, ok := updateData["path_sample_suara"].(string); ok {
+				hapusFileAudio(c, pathBaru)
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 			return
 		}
+		// Hapus file lama setelah file baru tersimpan
+		if berkasAudio != nil {
+			catatMetadataAudio(berkasAudio)
+			if existingDosen.PathSampleSuara != "" {
+				hapusFileAudio(c, existingDosen.PathSampleSuara)
+			}
+		}
+		catatAuditUbah(c, audit.EntitasDosen, existingDosen.ID, existingDosen, &models.Dosen{})
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Dosen berhasil diupdate"})
@@ -200,12 +214,12 @@ func HapusDosen(c *gin.Context) {
 
 	// Hapus file audio jika ada
 	if dosen.PathSampleSuara != "" {
-		utils.DeleteAudioFile(dosen.PathSampleSuara)
+		hapusFileAudio(c, dosen.PathSampleSuara)
 	}
 
 	// Hapus folder dosen jika ada
 	if dosen.FolderDosen != "" {
-		utils.DeleteDosenFolder(AudioUploadDirdosen, dosen.FolderDosen)
+		utils.DeleteDosenFolder(c.Request.Context(), blobStore, AudioUploadDirdosen, dosen.FolderDosen)
 	}
 
 	// Hapus dari database
@@ -214,19 +228,26 @@ func HapusDosen(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
 	}
+	catatAudit(c, audit.EntitasDosen, dosen.ID, audit.AksiHapus, dosen, nil)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Dosen berhasil dihapus"})
 }
 
 func RekamSuaraDosen(c *gin.Context) {
+    dosenID := c.Param("id")
+
+    // Akun dosen hanya boleh merekam ulang sampel suaranya sendiri
+    if !bolehAksesDosen(c, dosenID) {
+        c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak"})
+        return
+    }
+
     // Untuk rekaman live dari microphone
     file, err := c.FormFile("audio_data")
     if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Data audio wajib diupload"})
         return
     }
-
-    dosenID := c.Param("id")
     
     // Validasi size file
     if err := utils.CheckAudioFileSize(file, MaxUploadSizedosen); err != nil {
@@ -243,24 +264,17 @@ func RekamSuaraDosen(c *gin.Context) {
         return
     }
 
-    // Hapus file lama jika ada
-    if dosen.PathSampleSuara != "" {
-        utils.DeleteAudioFile(dosen.PathSampleSuara)
-    }
-
-    // Simpan file audio baru
-    pathSampleSuara, err := utils.SaveAudioFile(file, AudioUploadDirdosen, dosen.FolderDosen)
+    // Buka file source
+    src, err := file.Open()
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Gagal menyimpan file audio: %v", err)})
+        c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Gagal membuka file: %v", err)})
         return
     }
+    defer src.Close()
 
-    // Update path sample suara dosen
-    result = db.Model(&models.Dosen{}).Where("id = ?", dosenID).Update("path_sample_suara", pathSampleSuara)
-    if result.Error != nil {
-        // Hapus file yang sudah diupload jika gagal update database
-        utils.DeleteAudioFile(pathSampleSuara)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+    pathSampleSuara, err := lampirkanSampelSuara(c, dosen, src, file.Size, file.Filename)
+    if err != nil {
+        kirimGagalSampelSuara(c, err)
         return
     }
 
@@ -271,6 +285,38 @@ func RekamSuaraDosen(c *gin.Context) {
     })
 }
 
+// lampirkanSampelSuara menyimpan sampel suara ke folder dosen, mengganti
+// file lama, dan mencatat audit. Dipakai upload langsung maupun upload
+// bertahap. Sampel yang tidak lolos syarat kualitas ditolak dan sampel lama
+// tetap dipakai.
+func lampirkanSampelSuara(c *gin.Context, dosen models.Dosen, src io.Reader, size int64, namaFile string) (string, error) {
+    // Simpan file audio baru
+    berkasAudio, err := simpanSampelSuara(c, src, size, namaFile, dosen.FolderDosen)
+    if err != nil {
+        return "", err
+    }
+    pathSampleSuara := berkasAudio.Path
+
+    // Update path sample suara dosen
+    db := database.GetDB()
+    result := db.Model(&models.Dosen{}).Where("id = ?", dosen.ID).Update("path_sample_suara", pathSampleSuara)
+    if result.Error != nil {
+        // Hapus file yang sudah diupload jika gagal update database
+        hapusFileAudio(c, pathSampleSuara)
+        return "", result.Error
+    }
+
+    catatMetadataAudio(berkasAudio)
+
+    // Hapus file lama setelah file baru tersimpan
+    if dosen.PathSampleSuara != "" && dosen.PathSampleSuara != pathSampleSuara {
+        hapusFileAudio(c, dosen.PathSampleSuara)
+    }
+    catatAuditUbah(c, audit.EntitasDosen, dosen.ID, dosen, &models.Dosen{})
+
+    return pathSampleSuara, nil
+}
+
 // Handler untuk serve file audio (diperbarui untuk struktur folder baru)
 func ServeAudioFile(c *gin.Context) {
 	dosenFolder := c.Param("dosenFolder")
@@ -282,21 +328,21 @@ func ServeAudioFile(c *gin.Context) {
 		return
 	}
 
-	filePath := filepath.Join(AudioUploadDirdosen, dosenFolder, filename)
-
 	// Validasi untuk mencegah directory traversal
-	cleanPath := filepath.Clean(filePath)
-	if !strings.HasPrefix(cleanPath, AudioUploadDirdosen) {
+	if strings.ContainsAny(dosenFolder+filename, "/\\") || dosenFolder == ".." || filename == ".." {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Akses file tidak diizinkan"})
 		return
 	}
 
-	// Cek apakah file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File audio tidak ditemukan"})
-		return
+	// Akun dosen hanya boleh membuka sampel suaranya sendiri
+	if dosenID, ok := dosenPengguna(c); ok {
+		var jumlah int64
+		database.GetDB().Model(&models.Dosen{}).Where("id = ? AND folder_dosen = ?", dosenID, dosenFolder).Count(&jumlah)
+		if jumlah == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File audio tidak ditemukan"})
+			return
+		}
 	}
 
-	// Serve file
-	c.File(filePath)
+	kirimAudio(c, storage.Key(AudioUploadDirdosen, dosenFolder, filename))
 }
\ No newline at end of file
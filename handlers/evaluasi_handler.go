@@ -2,14 +2,16 @@ package handlers
 
 import (
     "fmt"
+    "io"
     "net/http"
-    "os"
-    "path/filepath"
     "strings"
     "time"
 
+    "CLAIRE/audit"
     "CLAIRE/database"
+    "CLAIRE/kalender"
     "CLAIRE/models"
+    "CLAIRE/storage"
     "CLAIRE/utils"
 
     "github.com/gin-gonic/gin"
@@ -25,6 +27,7 @@ const (
 func BuatEvaluasi(c *gin.Context) {
     var evaluasiInput struct {
         JadwalID             string  `json:"jadwal_id" binding:"required"`
+        PertemuanID          string  `json:"pertemuan_id"`
         KepercayaanPembicara float64 `json:"kepercayaan_pembicara"`
         TeksTranskripsi      string  `json:"teks_transkripsi"`
         Rangkuman            string  `json:"rangkuman"`
@@ -53,9 +56,16 @@ func BuatEvaluasi(c *gin.Context) {
         return
     }
 
+    pertemuanID, err := resolvePertemuanID(jadwalID, evaluasiInput.PertemuanID)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
     // Buat objek evaluasi
     evaluasi := models.Evaluasi{
         JadwalID:             jadwalID,
+        PertemuanID:          pertemuanID,
         KepercayaanPembicara: evaluasiInput.KepercayaanPembicara,
         TeksTranskripsi:      evaluasiInput.TeksTranskripsi,
         Rangkuman:            evaluasiInput.Rangkuman,
@@ -69,9 +79,10 @@ func BuatEvaluasi(c *gin.Context) {
         c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
         return
     }
+    catatAudit(c, audit.EntitasEvaluasi, evaluasi.ID, audit.AksiBuat, nil, evaluasi)
 
     // Load relasi jadwal dan dosen
-    db.Preload("Jadwal.Dosen").First(&evaluasi, evaluasi.ID)
+    db.Preload("Jadwal.Dosen").Preload("Pertemuan").Preload("Audio").First(&evaluasi, "id = ?", evaluasi.ID)
 
     c.JSON(http.StatusCreated, evaluasi)
 }
@@ -107,27 +118,17 @@ func UploadAudioEvaluasi(c *gin.Context) {
         return
     }
 
-    // Generate folder name berdasarkan jadwal dan tanggal
-    folderName := generateEvaluasiFolderName(evaluasi.JadwalID, evaluasi.Jadwal.Dosen)
-
-    // Hapus file lama jika ada
-    if evaluasi.PathFileAudio != "" {
-        utils.DeleteAudioFile(evaluasi.PathFileAudio)
-    }
-
-    // Simpan file audio baru
-    pathFileAudio, err := utils.SaveAudioFile(file, AudioUploadDir, folderName)
+    // Buka file source
+    src, err := file.Open()
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Gagal menyimpan file audio: %v", err)})
+        c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Gagal membuka file: %v", err)})
         return
     }
+    defer src.Close()
 
-    // Update path file audio di evaluasi
-    result = db.Model(&models.Evaluasi{}).Where("id = ?", evaluasiID).Update("path_file_audio", pathFileAudio)
-    if result.Error != nil {
-        // Hapus file yang sudah diupload jika gagal update database
-        utils.DeleteAudioFile(pathFileAudio)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+    pathFileAudio, err := lampirkanAudioEvaluasi(c, evaluasi, src, file.Size, file.Filename)
+    if err != nil {
+        c.JSON(statusSimpanAudio(err), gin.H{"error": fmt.Sprintf("Gagal menyimpan file audio: %v", err)})
         return
     }
 
@@ -138,11 +139,45 @@ func UploadAudioEvaluasi(c *gin.Context) {
     })
 }
 
+// lampirkanAudioEvaluasi menyimpan audio ke folder evaluasi, mengganti file
+// lama, dan mencatat audit. evaluasi harus sudah di-preload Jadwal.Dosen.
+// Dipakai upload langsung maupun upload bertahap.
+func lampirkanAudioEvaluasi(c *gin.Context, evaluasi models.Evaluasi, src io.Reader, size int64, namaFile string) (string, error) {
+    // Generate folder name berdasarkan jadwal dan tanggal
+    folderName := generateEvaluasiFolderName(evaluasi.JadwalID, evaluasi.Jadwal.Dosen)
+
+    // Simpan file audio baru
+    berkasAudio, err := utils.SaveAudioReader(c.Request.Context(), blobStore, src, size, namaFile, AudioUploadDir, folderName)
+    if err != nil {
+        return "", err
+    }
+    pathFileAudio := berkasAudio.Path
+
+    // Update path file audio di evaluasi
+    db := database.GetDB()
+    result := db.Model(&models.Evaluasi{}).Where("id = ?", evaluasi.ID).Update("path_file_audio", pathFileAudio)
+    if result.Error != nil {
+        // Hapus file yang sudah diupload jika gagal update database
+        hapusFileAudio(c, pathFileAudio)
+        return "", result.Error
+    }
+
+    catatMetadataAudio(berkasAudio)
+
+    // Hapus file lama setelah file baru tersimpan
+    if evaluasi.PathFileAudio != "" && evaluasi.PathFileAudio != pathFileAudio {
+        hapusFileAudio(c, evaluasi.PathFileAudio)
+    }
+    catatAuditUbah(c, audit.EntitasEvaluasi, evaluasi.ID, evaluasi, &models.Evaluasi{})
+
+    return pathFileAudio, nil
+}
+
 func DapatkanSemuaEvaluasi(c *gin.Context) {
     var evaluasi []models.Evaluasi
     
     db := database.GetDB()
-    result := db.Preload("Jadwal.Dosen").Order("tanggal_dibuat DESC").Find(&evaluasi)
+    result := lingkupEvaluasi(c, db.Preload("Jadwal.Dosen").Preload("Audio")).Order("tanggal_dibuat DESC").Find(&evaluasi)
     if result.Error != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
         return
@@ -156,7 +191,7 @@ func DapatkanEvaluasi(c *gin.Context) {
     
     var evaluasi models.Evaluasi
     db := database.GetDB()
-    result := db.Preload("Jadwal.Dosen").First(&evaluasi, "id = ?", id)
+    result := lingkupEvaluasi(c, db.Preload("Jadwal.Dosen").Preload("Pertemuan").Preload("Audio")).First(&evaluasi, "id = ?", id)
     if result.Error != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "Evaluasi tidak ditemukan"})
         return
@@ -170,7 +205,7 @@ func DapatkanEvaluasiByJadwal(c *gin.Context) {
     
     var evaluasi []models.Evaluasi
     db := database.GetDB()
-    result := db.Preload("Jadwal.Dosen").Where("jadwal_id = ?", jadwalID).Order("tanggal_dibuat DESC").Find(&evaluasi)
+    result := lingkupEvaluasi(c, db.Preload("Jadwal.Dosen").Preload("Audio")).Where("jadwal_id = ?", jadwalID).Order("tanggal_dibuat DESC").Find(&evaluasi)
     if result.Error != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "Evaluasi tidak ditemukan untuk jadwal ini"})
         return
@@ -229,6 +264,7 @@ func UpdateEvaluasi(c *gin.Context) {
             c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
             return
         }
+        catatAuditUbah(c, audit.EntitasEvaluasi, existingEvaluasi.ID, existingEvaluasi, &models.Evaluasi{})
     }
 
     c.JSON(http.StatusOK, gin.H{"message": "Evaluasi berhasil diupdate"})
@@ -246,13 +282,9 @@ func HapusEvaluasi(c *gin.Context) {
         return
     }
 
-    // Hapus file audio jika ada
+    // Hapus file audio jika ada (folder evaluasi yang kosong ikut terhapus)
     if evaluasi.PathFileAudio != "" {
-        utils.DeleteAudioFile(evaluasi.PathFileAudio)
-        
-        // Hapus folder evaluasi jika kosong
-        evaluasiDir := filepath.Dir(evaluasi.PathFileAudio)
-        utils.DeleteFolderIfEmpty(evaluasiDir)
+        hapusFileAudio(c, evaluasi.PathFileAudio)
     }
 
     // Hapus dari database
@@ -261,14 +293,39 @@ func HapusEvaluasi(c *gin.Context) {
         c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
         return
     }
+    catatAudit(c, audit.EntitasEvaluasi, evaluasi.ID, audit.AksiHapus, evaluasi, nil)
 
     c.JSON(http.StatusOK, gin.H{"message": "Evaluasi berhasil dihapus"})
 }
 
+// resolvePertemuanID memvalidasi pertemuan_id dari request. Jika kosong,
+// pertemuan jadwal hari ini dipakai (nil jika belum di-generate).
+func resolvePertemuanID(jadwalID uuid.UUID, input string) (*uuid.UUID, error) {
+    db := database.GetDB()
+    if input == "" {
+        return models.CariPertemuanID(db, jadwalID, kalender.Default().TanggalHariIni()), nil
+    }
+
+    pertemuanID, err := uuid.Parse(input)
+    if err != nil {
+        return nil, fmt.Errorf("Format pertemuan_id tidak valid")
+    }
+
+    var pertemuan models.Pertemuan
+    if err := db.First(&pertemuan, "id = ?", pertemuanID).Error; err != nil {
+        return nil, fmt.Errorf("Pertemuan tidak ditemukan")
+    }
+    if pertemuan.JadwalID != jadwalID {
+        return nil, fmt.Errorf("Pertemuan bukan milik jadwal ini")
+    }
+    return &pertemuanID, nil
+}
+
 // Handler untuk proses rekaman otomatis dari service
 func SimpanHasilAnalisis(c *gin.Context) {
     var analysisInput struct {
         JadwalID             string   `json:"jadwal_id" binding:"required"`
+        PertemuanID          string   `json:"pertemuan_id"`
         KepercayaanPembicara float64  `json:"kepercayaan_pembicara"`
         TeksTranskripsi      string   `json:"teks_transkripsi"`
         Rangkuman            string   `json:"rangkuman"`
@@ -302,9 +359,16 @@ func SimpanHasilAnalisis(c *gin.Context) {
         return
     }
 
+    pertemuanID, err := resolvePertemuanID(jadwalID, analysisInput.PertemuanID)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
     // Buat objek evaluasi dari hasil analisis
     evaluasi := models.Evaluasi{
         JadwalID:             jadwalID,
+        PertemuanID:          pertemuanID,
         KepercayaanPembicara: analysisInput.KepercayaanPembicara,
         TeksTranskripsi:      analysisInput.TeksTranskripsi,
         Rangkuman:            analysisInput.Rangkuman,
@@ -318,9 +382,10 @@ func SimpanHasilAnalisis(c *gin.Context) {
         c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
         return
     }
+    catatAudit(c, audit.EntitasEvaluasi, evaluasi.ID, audit.AksiBuat, nil, evaluasi)
 
     // Load relasi untuk response
-    db.Preload("Jadwal.Dosen").First(&evaluasi, evaluasi.ID)
+    db.Preload("Jadwal.Dosen").Preload("Pertemuan").Preload("Audio").First(&evaluasi, "id = ?", evaluasi.ID)
 
     c.JSON(http.StatusCreated, gin.H{
         "message": "Hasil analisis berhasil disimpan",
@@ -339,23 +404,25 @@ func ServeAudioEvaluasi(c *gin.Context) {
         return
     }
 
-    filePath := filepath.Join(AudioUploadDir, folderName, filename)
-
     // Validasi untuk mencegah directory traversal
-    cleanPath := filepath.Clean(filePath)
-    if !strings.HasPrefix(cleanPath, AudioUploadDir) {
+    if strings.ContainsAny(folderName+filename, "/\\") || folderName == ".." || filename == ".." {
         c.JSON(http.StatusForbidden, gin.H{"error": "Akses file tidak diizinkan"})
         return
     }
+    key := storage.Key(AudioUploadDir, folderName, filename)
 
-    // Cek apakah file exists
-    if _, err := os.Stat(filePath); os.IsNotExist(err) {
-        c.JSON(http.StatusNotFound, gin.H{"error": "File audio tidak ditemukan"})
-        return
+    // Dosen hanya boleh memutar audio evaluasi miliknya sendiri
+    if _, ok := dosenPengguna(c); ok {
+        var jumlah int64
+        lingkupEvaluasi(c, database.GetDB().Model(&models.Evaluasi{})).
+            Where("path_file_audio = ?", key).Count(&jumlah)
+        if jumlah == 0 {
+            c.JSON(http.StatusNotFound, gin.H{"error": "File audio tidak ditemukan"})
+            return
+        }
     }
 
-    // Serve file
-    c.File(filePath)
+    kirimAudio(c, key)
 }
 
 // Helper function untuk generate folder name evaluasi
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"CLAIRE/auth"
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler untuk membuat token feed kalender. Token berlaku tanpa batas waktu
+// untuk satu path .ics sampai dicabut, sehingga bisa dipasang di aplikasi
+// kalender. Token asli hanya dikirim di response ini.
+func BuatFeedKalender(c *gin.Context) {
+	var input struct {
+		Path string `json:"path" binding:"required"`
+		Nama string `json:"nama"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !pathFeedKalender(input.Path) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token feed hanya tersedia untuk feed kalender (.ics)"})
+		return
+	}
+
+	token, prefix, err := auth.BuatTokenFeed()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := auth.Pengguna(c)
+	feed := models.FeedKalender{
+		UserID:    user.ID,
+		Nama:      strings.TrimSpace(input.Nama),
+		Path:      input.Path,
+		Prefix:    prefix,
+		HashToken: auth.HashAPIKey(token),
+	}
+	if err := database.GetDB().Create(&feed).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"feed":    feed,
+		"token":   token,
+		"url":     input.Path + "?token=" + url.QueryEscape(token),
+		"message": "Simpan alamat feed ini sekarang; token tidak akan ditampilkan lagi",
+	})
+}
+
+// Handler untuk daftar token feed kalender milik akun yang sedang login.
+// Gunakan ?aktif=true untuk hanya menampilkan token yang belum dicabut.
+func DapatkanSemuaFeedKalender(c *gin.Context) {
+	query := database.GetDB().Where("user_id = ?", auth.Pengguna(c).ID)
+	if c.Query("aktif") == "true" {
+		query = query.Where("dicabut_pada IS NULL")
+	}
+
+	var feeds []models.FeedKalender
+	result := query.Order("tanggal_dibuat DESC").Find(&feeds)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+// Handler untuk mencabut token feed kalender. Pengguna hanya bisa mencabut
+// token miliknya sendiri, kecuali pengelola pengguna.
+func CabutFeedKalender(c *gin.Context) {
+	id := c.Param("id")
+
+	var feed models.FeedKalender
+	db := database.GetDB()
+	user := auth.Pengguna(c)
+	if err := db.First(&feed, "id = ?", id).Error; err != nil ||
+		(feed.UserID != user.ID && !auth.Punya(user.Role, auth.IzinKelolaPengguna)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token feed kalender tidak ditemukan"})
+		return
+	}
+	if feed.DicabutPada != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Token feed kalender sudah dicabut"})
+		return
+	}
+
+	now := time.Now()
+	result := db.Model(&models.FeedKalender{}).Where("id = ?", feed.ID).Updates(map[string]interface{}{
+		"dicabut_pada":     now,
+		"tanggal_diupdate": now,
+	})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	feed.DicabutPada = &now
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token feed kalender berhasil dicabut", "feed": feed})
+}
+
+// pathFeedKalender mengecek apakah path termasuk route feed kalender
+// (lihat auth.FeedKalender di main.go)
+func pathFeedKalender(p string) bool {
+	return p == path.Clean(p) && strings.HasPrefix(p, "/api/v1/") && strings.HasSuffix(p, "/kalender.ics")
+}
@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"CLAIRE/audit"
+	"CLAIRE/database"
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+	"CLAIRE/spreadsheet"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Ukuran maksimal file jadwal yang diimport
+const MaxImportSize = 5 << 20 // 5MB
+
+// Status hasil import per baris
+const (
+	ImportDibuat     = "dibuat"
+	ImportAkanDibuat = "akan_dibuat" // mode dry-run
+	ImportGagal      = "gagal"
+)
+
+// Format jam dengan titik seperti "08.00"
+var formatJamTitik = regexp.MustCompile(`^\d{1,2}\.\d{2}$`)
+
+// errDryRun dipakai untuk membatalkan transaksi pada mode dry-run
+var errDryRun = errors.New("dry run")
+
+// errBarisGagal dipakai untuk membatalkan savepoint baris import yang gagal
+var errBarisGagal = errors.New("baris import gagal")
+
+// Nama kolom yang dikenali di baris header, sudah dinormalisasi
+var kolomImport = map[string][]string{
+	"matkul":  {"mata kuliah", "matkul", "nama matkul", "nama mata kuliah", "course"},
+	"dosen":   {"dosen", "nama dosen", "pengajar", "lecturer"},
+	"hari":    {"hari", "day"},
+	"mulai":   {"mulai", "waktu mulai", "jam mulai", "start"},
+	"selesai": {"selesai", "waktu selesai", "jam selesai", "end"},
+	"ruangan": {"ruangan", "ruang", "room"},
+}
+
+// HasilImportBaris adalah laporan import untuk satu baris file
+type HasilImportBaris struct {
+	Baris        int             `json:"baris"`
+	Status       string          `json:"status"`
+	NamaMatkul   string          `json:"nama_matkul"`
+	Dosen        string          `json:"dosen"`
+	DosenID      *uuid.UUID      `json:"dosen_id,omitempty"`
+	Hari         string          `json:"hari"`
+	WaktuMulai   string          `json:"waktu_mulai"`
+	WaktuSelesai string          `json:"waktu_selesai"`
+	Ruangan      string          `json:"ruangan"`
+	JadwalID     *uuid.UUID      `json:"jadwal_id,omitempty"`
+	Error        []string        `json:"error,omitempty"`
+	Konflik      []KonflikJadwal `json:"konflik,omitempty"`
+}
+
+// Handler untuk import jadwal dari file CSV/XLSX. Kolom yang dibutuhkan:
+// mata kuliah, dosen, hari, mulai, selesai dan (opsional) ruangan.
+// Gunakan ?dry_run=true untuk melihat hasil tanpa menyimpan apa pun.
+func ImportJadwal(c *gin.Context) {
+	dryRun := strings.EqualFold(c.Query("dry_run"), "true") || c.Query("dry_run") == "1"
+	override := izinkanKonflik(c)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File jadwal wajib diupload"})
+		return
+	}
+	if file.Size > MaxImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Ukuran file terlalu besar. Maksimal %d MB", MaxImportSize>>20)})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := spreadsheet.Read(file.Filename, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak berisi data jadwal"})
+		return
+	}
+
+	kolom, err := petakanKolomImport(rows[0])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var semesterID *uuid.UUID
+	if value := c.PostForm("semester_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format semester_id tidak valid"})
+			return
+		}
+		semesterID = &id
+	}
+
+	db := database.GetDB()
+	var daftarDosen []models.Dosen
+	if err := db.Find(&daftarDosen).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Semua baris diproses dalam satu transaksi supaya pengecekan bentrok
+	// juga melihat baris sebelumnya. Pada dry-run transaksi di-rollback.
+	// Setiap baris berjalan di savepoint sendiri, sehingga baris yang gagal
+	// ikut membatalkan mata kuliah, ruangan dan jadwal yang sempat dibuatnya.
+	hasil := make([]HasilImportBaris, 0, len(rows)-1)
+	var dibuat []uuid.UUID
+	sumber := sumberAudit(c)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for i, row := range rows[1:] {
+			var laporan HasilImportBaris
+			err := tx.Transaction(func(baris *gorm.DB) error {
+				laporan = importBarisJadwal(baris, row, kolom, daftarDosen, semesterID, override, dryRun, sumber)
+				if laporan.Status == ImportGagal {
+					return errBarisGagal
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errBarisGagal) {
+				return err
+			}
+			laporan.Baris = i + 2 // nomor baris di file, header di baris 1
+			if laporan.JadwalID != nil && !dryRun {
+				dibuat = append(dibuat, *laporan.JadwalID)
+			}
+			hasil = append(hasil, laporan)
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, id := range dibuat {
+		notifyScheduler(id)
+	}
+
+	gagal := 0
+	for _, h := range hasil {
+		if h.Status == ImportGagal {
+			gagal++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run":     dryRun,
+		"total_baris": len(hasil),
+		"berhasil":    len(hasil) - gagal,
+		"gagal":       gagal,
+		"hasil":       hasil,
+	})
+}
+
+// importBarisJadwal memvalidasi satu baris dan membuat jadwalnya di tx
+func importBarisJadwal(tx *gorm.DB, row []string, kolom map[string]int, daftarDosen []models.Dosen, semesterID *uuid.UUID, override bool, dryRun bool, sumber audit.Sumber) HasilImportBaris {
+	sel := func(nama string) string {
+		idx, ok := kolom[nama]
+		if !ok || idx >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[idx])
+	}
+
+	laporan := HasilImportBaris{
+		NamaMatkul:   sel("matkul"),
+		Dosen:        sel("dosen"),
+		Hari:         normalisasiHariImport(sel("hari")),
+		WaktuMulai:   normalisasiJamImport(sel("mulai")),
+		WaktuSelesai: normalisasiJamImport(sel("selesai")),
+		Ruangan:      sel("ruangan"),
+	}
+	gagal := func(pesan ...string) HasilImportBaris {
+		laporan.Status = ImportGagal
+		laporan.Error = append(laporan.Error, pesan...)
+		return laporan
+	}
+
+	if laporan.NamaMatkul == "" {
+		laporan.Error = append(laporan.Error, "Mata kuliah wajib diisi")
+	}
+	if !kalender.HariValid(laporan.Hari) {
+		laporan.Error = append(laporan.Error, "Hari tidak valid. Gunakan: SENIN, SELASA, RABU, KAMIS, JUMAT, SABTU, MINGGU")
+	}
+	if !isValidTime(laporan.WaktuMulai) || !isValidTime(laporan.WaktuSelesai) {
+		laporan.Error = append(laporan.Error, "Format waktu tidak valid. Gunakan format HH:MM")
+	} else if laporan.WaktuSelesai <= laporan.WaktuMulai {
+		laporan.Error = append(laporan.Error, "Waktu selesai harus setelah waktu mulai")
+	}
+
+	dosen, err := cocokkanDosen(daftarDosen, laporan.Dosen)
+	if err != nil {
+		laporan.Error = append(laporan.Error, err.Error())
+	} else {
+		laporan.DosenID = &dosen.ID
+	}
+	if len(laporan.Error) > 0 {
+		return gagal()
+	}
+
+	jadwal := models.Jadwal{
+		NamaMatkul:   laporan.NamaMatkul,
+		DosenID:      dosen.ID,
+		Hari:         laporan.Hari,
+		WaktuMulai:   laporan.WaktuMulai,
+		WaktuSelesai: laporan.WaktuSelesai,
+		Ruangan:      laporan.Ruangan,
+		SemesterID:   semesterID,
+	}
+	if err := jadwal.KebijakanRekaman().Validate(jadwal.WaktuMulai, jadwal.WaktuSelesai); err != nil {
+		return gagal("Kebijakan rekaman tidak valid: " + err.Error())
+	}
+	if err := terapkanKatalog(tx, &jadwal, true); err != nil {
+		return gagal(err.Error())
+	}
+	laporan.NamaMatkul = jadwal.NamaMatkul
+	laporan.Ruangan = jadwal.Ruangan
+
+	if !override {
+		konflik, err := cariKonflikJadwal(tx, jadwal)
+		if err != nil {
+			return gagal(err.Error())
+		}
+		if len(konflik) > 0 {
+			laporan.Konflik = konflik
+			return gagal("Jadwal bentrok dengan jadwal lain")
+		}
+	}
+
+	jadwal.Status = statusAwalJadwal(jadwal)
+	if err := tx.Create(&jadwal).Error; err != nil {
+		return gagal(err.Error())
+	}
+	if err := models.CatatStatusAwalJadwal(tx, &jadwal, sumber.Aktor, "jadwal diimpor"); err != nil {
+		return gagal(err.Error())
+	}
+	if err := audit.Catat(tx, sumber, audit.EntitasJadwal, jadwal.ID.String(), audit.AksiBuat, nil, jadwal); err != nil {
+		return gagal(err.Error())
+	}
+
+	laporan.Status = ImportDibuat
+	if dryRun {
+		laporan.Status = ImportAkanDibuat
+	} else {
+		laporan.JadwalID = &jadwal.ID
+	}
+	return laporan
+}
+
+// petakanKolomImport mencari indeks kolom dari baris header
+func petakanKolomImport(header []string) (map[string]int, error) {
+	kolom := make(map[string]int)
+	for i, judul := range header {
+		judul = normalisasiTeksImport(judul)
+		for nama, alias := range kolomImport {
+			for _, a := range alias {
+				if judul == a {
+					if _, ada := kolom[nama]; !ada {
+						kolom[nama] = i
+					}
+				}
+			}
+		}
+	}
+
+	var kurang []string
+	for _, nama := range []string{"matkul", "dosen", "hari", "mulai", "selesai"} {
+		if _, ok := kolom[nama]; !ok {
+			kurang = append(kurang, kolomImport[nama][0])
+		}
+	}
+	if len(kurang) > 0 {
+		return nil, fmt.Errorf("Kolom wajib tidak ditemukan di header: %s", strings.Join(kurang, ", "))
+	}
+	return kolom, nil
+}
+
+// cocokkanDosen mencari dosen berdasarkan nama, dengan atau tanpa gelar.
+// Perbandingan mengabaikan huruf besar/kecil dan tanda baca.
+func cocokkanDosen(daftar []models.Dosen, teks string) (*models.Dosen, error) {
+	kunci := normalisasiTeksImport(teks)
+	if kunci == "" {
+		return nil, errors.New("Nama dosen wajib diisi")
+	}
+
+	var cocok []*models.Dosen
+	for i := range daftar {
+		d := &daftar[i]
+		kandidat := []string{
+			d.Nama,
+			d.Nama + " " + d.Gelar,
+			d.Gelar + " " + d.Nama,
+		}
+		for _, k := range kandidat {
+			if normalisasiTeksImport(k) == kunci {
+				cocok = append(cocok, d)
+				break
+			}
+		}
+	}
+
+	switch len(cocok) {
+	case 0:
+		return nil, fmt.Errorf("Dosen tidak ditemukan: %s", teks)
+	case 1:
+		return cocok[0], nil
+	default:
+		return nil, fmt.Errorf("Nama dosen ambigu (%d dosen cocok), tambahkan gelar: %s", len(cocok), teks)
+	}
+}
+
+// normalisasiTeksImport mengubah teks menjadi huruf kecil tanpa tanda baca
+// dan spasi berlebih
+func normalisasiTeksImport(teks string) string {
+	teks = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, teks)
+	return strings.Join(strings.Fields(teks), " ")
+}
+
+// normalisasiHariImport menyamakan penulisan hari, misalnya "Jum'at" -> "JUMAT"
+func normalisasiHariImport(hari string) string {
+	hari = strings.ToUpper(strings.TrimSpace(hari))
+	return strings.NewReplacer("'", "", "’", "", " ", "").Replace(hari)
+}
+
+// normalisasiJamImport menerima "8:00", "08.00", "08:00:00" atau pecahan
+// hari dari sel waktu Excel (0.3333 = 08:00) dan mengubahnya ke HH:MM
+func normalisasiJamImport(jam string) string {
+	jam = strings.TrimSpace(jam)
+	if !formatJamTitik.MatchString(jam) && !strings.Contains(jam, ":") {
+		if f, err := strconv.ParseFloat(jam, 64); err == nil && f >= 0 && f < 1 {
+			menit := int(f*24*60 + 0.5)
+			return fmt.Sprintf("%02d:%02d", menit/60, menit%60)
+		}
+	}
+
+	jam = strings.ReplaceAll(jam, ".", ":")
+	bagian := strings.Split(jam, ":")
+	if len(bagian) < 2 {
+		return jam
+	}
+	h, errH := strconv.Atoi(bagian[0])
+	m, errM := strconv.Atoi(bagian[1])
+	if errH != nil || errM != nil {
+		return jam
+	}
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// importCSV mengirim isi CSV ke ImportJadwal dan mengembalikan hasil per baris
+func importCSV(t *testing.T, csv string) []HasilImportBaris {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "jadwal.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte(csv))
+	form.Close()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/jadwal/import", ImportJadwal)
+	req := httptest.NewRequest(http.MethodPost, "/jadwal/import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status HTTP = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	var respon struct {
+		Hasil []HasilImportBaris `json:"hasil"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &respon); err != nil {
+		t.Fatal(err)
+	}
+	return respon.Hasil
+}
+
+// jumlah menghitung baris model di database
+func jumlah(db *gorm.DB, model interface{}, query string, args ...interface{}) int64 {
+	var n int64
+	db.Model(model).Where(query, args...).Count(&n)
+	return n
+}
+
+func TestImportJadwalBarisGagalDibatalkan(t *testing.T) {
+	db := dbUji(t)
+	if err := db.Create(&models.Dosen{Nama: "Budi"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Baris kedua bentrok dengan baris pertama setelah mata kuliah dan
+	// ruangan barunya sempat dibuat
+	hasil := importCSV(t, "matkul,dosen,hari,mulai,selesai,ruangan\n"+
+		"Algoritma,Budi,SENIN,08:00,10:00,R101\n"+
+		"Basis Data,Budi,SENIN,09:00,11:00,R202\n")
+	if len(hasil) != 2 || hasil[0].Status != ImportDibuat || hasil[1].Status != ImportGagal {
+		t.Fatalf("hasil = %+v, want baris 2 dibuat dan baris 3 gagal", hasil)
+	}
+	if n := jumlah(db, &models.MataKuliah{}, "nama = ?", "Basis Data"); n != 0 {
+		t.Errorf("mata kuliah dari baris gagal tetap tersimpan (%d)", n)
+	}
+	if n := jumlah(db, &models.Ruangan{}, "kode = ?", "R202"); n != 0 {
+		t.Errorf("ruangan dari baris gagal tetap tersimpan (%d)", n)
+	}
+	if n := jumlah(db, &models.Jadwal{}, "1 = 1"); n != 1 {
+		t.Errorf("jumlah jadwal = %d, want 1", n)
+	}
+
+	// Riwayat status gagal dicatat: jadwal yang sudah dibuat ikut dibatalkan
+	if err := db.Migrator().DropTable(&models.JadwalStatusLog{}); err != nil {
+		t.Fatal(err)
+	}
+	hasil = importCSV(t, "matkul,dosen,hari,mulai,selesai\nJaringan,Budi,RABU,08:00,10:00\n")
+	if len(hasil) != 1 || hasil[0].Status != ImportGagal {
+		t.Fatalf("hasil = %+v, want gagal", hasil)
+	}
+	if n := jumlah(db, &models.Jadwal{}, "hari = ?", "RABU"); n != 0 {
+		t.Errorf("jadwal tanpa riwayat status tetap tersimpan (%d)", n)
+	}
+	if n := jumlah(db, &models.MataKuliah{}, "nama = ?", "Jaringan"); n != 0 {
+		t.Errorf("mata kuliah dari baris gagal tetap tersimpan (%d)", n)
+	}
+}
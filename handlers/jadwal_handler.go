@@ -1,73 +1,84 @@
 package handlers
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
 
+	"CLAIRE/analysis"
+	"CLAIRE/audit"
+	"CLAIRE/config"
 	"CLAIRE/database"
+	"CLAIRE/kalender"
 	"CLAIRE/models"
+	"CLAIRE/recording"
+	"CLAIRE/scheduler"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-var validHari = map[string]bool{
-	"SENIN":   true,
-	"SELASA":  true,
-	"RABU":    true,
-	"KAMIS":   true,
-	"JUMAT":   true,
-	"SABTU":   true,
-	"MINGGU":  true,
-}
-
-// Struct untuk response analisis dari Python backend
-type AudioAnalysisResponse struct {
-	Analysis struct {
-		ClarityScore    float64  `json:"clarity_score"`
-		Effectiveness   int      `json:"effectiveness"`
-		Feedback        string   `json:"feedback"`
-		IsEffective     bool     `json:"is_effective"`
-		Redundancy      float64  `json:"redundancy"`
-		Summary         string   `json:"summary"`
-		TopicFocus      []string `json:"topic_focus"`
-	} `json:"analysis"`
-	Similarity  float64 `json:"similarity"`
-	Speaker     string  `json:"speaker"`
-	Status      string  `json:"status"`
-	Timestamp   string  `json:"timestamp"`
-	Transcript  string  `json:"transcript"`
-}
-
-// Fungsi untuk update status jadwal secara otomatis
-func UpdateJadwalStatus() {
-	db := database.GetDB()
-	var jadwals []models.Jadwal
-	
-	// Ambil semua jadwal yang tidak sedang merekam
-	db.Where("sedang_rekam = ?", false).Find(&jadwals)
-	
-	for _, jadwal := range jadwals {
-		var newStatus string
-		
-		if jadwal.IsOngoing() {
-			newStatus = "aktif"
-		} else if jadwal.IsCompleted() {
-			newStatus = "selesai"
-		} else {
-			newStatus = "terjadwal"
-		}
-		
-		// Update jika status berubah
-		if jadwal.Status != newStatus {
-			db.Model(&jadwal).Update("status", newStatus)
+// Client layanan analisis audio, dibuat dari config saat pertama dipakai.
+// Dijaga mutex karena SetAnalysisClient bisa dipanggil saat handler berjalan.
+var (
+	analysisClient   analysis.AnalysisClient
+	analysisClientMu sync.Mutex
+)
+
+// SetAnalysisClient mengganti client layanan analisis (misalnya dengan
+// analysis.FakeClient saat pengujian)
+func SetAnalysisClient(client analysis.AnalysisClient) {
+	analysisClientMu.Lock()
+	defer analysisClientMu.Unlock()
+	analysisClient = client
+}
+
+func getAnalysisClient() analysis.AnalysisClient {
+	analysisClientMu.Lock()
+	defer analysisClientMu.Unlock()
+	if analysisClient == nil {
+		analysisClient = analysis.NewFromConfig(config.LoadConfig())
+	}
+	return analysisClient
+}
+
+// Antrian job rekaman terjadwal, di-set dari main
+var recordingQueue *recording.Queue
+
+// SetRecordingQueue memasang antrian job rekaman yang dipakai handler
+func SetRecordingQueue(queue *recording.Queue) {
+	recordingQueue = queue
+}
+
+// Scheduler rekaman otomatis, nil jika scheduler dinonaktifkan
+var jadwalScheduler *scheduler.Scheduler
+
+// SetScheduler memasang scheduler agar perubahan jadwal langsung diterapkan
+func SetScheduler(s *scheduler.Scheduler) {
+	jadwalScheduler = s
+}
+
+// notifyScheduler memberi tahu scheduler bahwa sebuah jadwal berubah
+func notifyScheduler(id uuid.UUID) {
+	if jadwalScheduler != nil {
+		jadwalScheduler.Refresh(id)
+	}
+}
+
+// muatUlangScheduler menghitung ulang semua jadwal, misalnya setelah hari
+// libur berubah
+func muatUlangScheduler() {
+	if jadwalScheduler != nil {
+		if err := jadwalScheduler.Load(); err != nil {
+			log.Printf("Gagal memuat ulang scheduler: %v", err)
 		}
 	}
 }
@@ -80,7 +91,7 @@ func BuatJadwal(c *gin.Context) {
 	}
 
 	// Validasi hari
-	if !validHari[jadwal.Hari] {
+	if !kalender.HariValid(jadwal.Hari) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Hari tidak valid. Gunakan: SENIN, SELASA, RABU, KAMIS, JUMAT, SABTU, MINGGU"})
 		return
 	}
@@ -97,36 +108,73 @@ func BuatJadwal(c *gin.Context) {
 		return
 	}
 
-	// Set status awal berdasarkan waktu
-	if jadwal.IsOngoing() {
-		jadwal.Status = "aktif"
-	} else if jadwal.IsCompleted() {
-		jadwal.Status = "selesai"
-	} else {
-		jadwal.Status = "terjadwal"
+	// Validasi kebijakan rekaman
+	if err := jadwal.KebijakanRekaman().Validate(jadwal.WaktuMulai, jadwal.WaktuSelesai); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kebijakan rekaman tidak valid: " + err.Error()})
+		return
 	}
 
 	db := database.GetDB()
-	result := db.Create(&jadwal)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+
+	// Hubungkan ke katalog mata kuliah dan semester
+	if err := terapkanKatalog(db, &jadwal, true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Load data dosen
-	db.Preload("Dosen").First(&jadwal, jadwal.ID)
+	// Cek bentrok dosen dan ruangan
+	if !izinkanKonflik(c) {
+		konflik, err := cariKonflikJadwal(db, jadwal)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(konflik) > 0 {
+			c.JSON(http.StatusConflict, responKonflik(konflik))
+			return
+		}
+	}
+
+	// Set status awal berdasarkan waktu
+	jadwal.Status = statusAwalJadwal(jadwal)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&jadwal).Error; err != nil {
+			return err
+		}
+		if err := models.CatatStatusAwalJadwal(tx, &jadwal, aktorRequest(c), "jadwal dibuat"); err != nil {
+			return err
+		}
+		return audit.Catat(tx, sumberAudit(c), audit.EntitasJadwal, jadwal.ID.String(), audit.AksiBuat, nil, jadwal)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Load data dosen dan katalog
+	db.Preload("Dosen").Preload("MataKuliah").Preload("Semester").Preload("DetailRuangan").First(&jadwal, "id = ?", jadwal.ID)
+	notifyScheduler(jadwal.ID)
 
 	c.JSON(http.StatusCreated, jadwal)
 }
 
-// Di semua fungsi yang mengambil jadwal, panggil UpdateJadwalStatus terlebih dahulu
+// Status jadwal diperbarui oleh reconciler di latar belakang, sehingga
+// endpoint baca hanya membaca
 func DapatkanSemuaJadwal(c *gin.Context) {
-	// Update status sebelum mengambil data
-	UpdateJadwalStatus()
-	
 	var jadwal []models.Jadwal
 	db := database.GetDB()
-	result := db.Preload("Dosen").Find(&jadwal)
+	query := lingkupJadwal(c, db.Preload("Dosen").Preload("MataKuliah").Preload("Semester").Preload("DetailRuangan"))
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("semester_id = ?", semesterID)
+	}
+	if matkulID := c.Query("mata_kuliah_id"); matkulID != "" {
+		query = query.Where("mata_kuliah_id = ?", matkulID)
+	}
+	if ruanganID := c.Query("ruangan_id"); ruanganID != "" {
+		query = query.Where("ruangan_id = ?", ruanganID)
+	}
+	result := query.Find(&jadwal)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
@@ -136,12 +184,10 @@ func DapatkanSemuaJadwal(c *gin.Context) {
 }
 
 func DapatkanJadwal(c *gin.Context) {
-	UpdateJadwalStatus() // Update status
-	
 	id := c.Param("id")
 	var jadwal models.Jadwal
 	db := database.GetDB()
-	result := db.Preload("Dosen").First(&jadwal, "id = ?", id)
+	result := lingkupJadwal(c, db.Preload("Dosen").Preload("MataKuliah").Preload("Semester").Preload("DetailRuangan")).First(&jadwal, "id = ?", id)
 	if result.Error != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
 		return
@@ -155,16 +201,20 @@ func MulaiRekaman(c *gin.Context) {
 	jadwalID := c.Param("id")
 	
 	db := database.GetDB()
-	result := db.Model(&models.Jadwal{}).Where("id = ?", jadwalID).Updates(map[string]interface{}{
+	var jadwal models.Jadwal
+	if err := db.First(&jadwal, "id = ?", jadwalID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
+		return
+	}
+
+	err := models.UbahStatusJadwal(db, jadwal.ID, models.JadwalMerekam, aktorRequest(c), "rekaman manual dimulai", map[string]interface{}{
 		"sedang_rekam": true,
-		"status":       "merekam",
-		"tanggal_diupdate": time.Now(),
 	})
-	
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	if err != nil {
+		responTransisiGagal(c, err, "Jadwal tidak ditemukan")
 		return
 	}
+	catatAuditUbah(c, audit.EntitasJadwal, jadwal.ID, jadwal, &models.Jadwal{})
 
 	c.JSON(http.StatusOK, gin.H{"message": "Rekaman dimulai"})
 }
@@ -172,27 +222,51 @@ func MulaiRekaman(c *gin.Context) {
 func HentikanRekaman(c *gin.Context) {
 	jadwalID := c.Param("id")
 	
+	// Hentikan job rekaman otomatis jika ada, termasuk proses ffmpeg-nya
+	job, err := stopRecordingJob(jadwalID)
+	if err == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Rekaman dihentikan",
+			"job":     job,
+			"ringkasan": ringkasanJob(job),
+		})
+		return
+	}
+	if !errors.Is(err, recording.ErrTidakAdaJob) {
+		c.JSON(stopErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
 	db := database.GetDB()
 	
 	// Set status berdasarkan waktu setelah rekaman selesai
 	var jadwal models.Jadwal
-	db.First(&jadwal, "id = ?", jadwalID)
+	if err := db.First(&jadwal, "id = ?", jadwalID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
+		return
+	}
 	
-	newStatus := "selesai"
+	if jadwal.Status != models.JadwalMerekam {
+		// Tidak ada rekaman manual yang berjalan, cukup pastikan flag-nya bersih
+		db.Model(&models.Jadwal{}).Where("id = ?", jadwal.ID).Update("sedang_rekam", false)
+		catatAuditUbah(c, audit.EntitasJadwal, jadwal.ID, jadwal, &models.Jadwal{})
+		c.JSON(http.StatusOK, gin.H{"message": "Rekaman dihentikan"})
+		return
+	}
+
+	newStatus := models.JadwalSelesai
 	if jadwal.IsOngoing() {
-		newStatus = "aktif"
+		newStatus = models.JadwalAktif
 	}
 	
-	result := db.Model(&models.Jadwal{}).Where("id = ?", jadwalID).Updates(map[string]interface{}{
+	err = models.UbahStatusJadwal(db, jadwal.ID, newStatus, aktorRequest(c), "rekaman manual dihentikan", map[string]interface{}{
 		"sedang_rekam": false,
-		"status":       newStatus,
-		"tanggal_diupdate": time.Now(),
 	})
-	
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	if err != nil {
+		responTransisiGagal(c, err, "Jadwal tidak ditemukan")
 		return
 	}
+	catatAuditUbah(c, audit.EntitasJadwal, jadwal.ID, jadwal, &models.Jadwal{})
 
 	c.JSON(http.StatusOK, gin.H{"message": "Rekaman dihentikan"})
 }
@@ -216,30 +290,237 @@ func UpdateJadwal(c *gin.Context) {
 	id := c.Param("id")
 	
 	var jadwal models.Jadwal
-	if err := c.ShouldBindJSON(&jadwal); err != nil {
+	if err := c.ShouldBindBodyWith(&jadwal, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Pengaturan rekaman yang dikirim null dikembalikan ke default global
+	kebijakan, err := kebijakanDikirim(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	db := database.GetDB()
-	result := db.Model(&models.Jadwal{}).Where("id = ?", id).Updates(jadwal)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+
+	var existing models.Jadwal
+	if err := db.First(&existing, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
+		return
+	}
+	if err := terapkanKatalog(db, &jadwal, false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	merged := mergeJadwalUpdate(existing, jadwal, kebijakan)
+
+	// Validasi data jadwal setelah diupdate
+	if !kalender.HariValid(merged.Hari) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hari tidak valid. Gunakan: SENIN, SELASA, RABU, KAMIS, JUMAT, SABTU, MINGGU"})
+		return
+	}
+	if !isValidTime(merged.WaktuMulai) || !isValidTime(merged.WaktuSelesai) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format waktu tidak valid. Gunakan format HH:MM"})
+		return
+	}
+	if merged.WaktuSelesai <= merged.WaktuMulai {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Waktu selesai harus setelah waktu mulai"})
+		return
+	}
+	if err := merged.KebijakanRekaman().Validate(merged.WaktuMulai, merged.WaktuSelesai); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kebijakan rekaman tidak valid: " + err.Error()})
+		return
+	}
+
+	// Cek bentrok dosen dan ruangan
+	if !izinkanKonflik(c) {
+		konflik, err := cariKonflikJadwal(db, merged)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(konflik) > 0 {
+			c.JSON(http.StatusConflict, responKonflik(konflik))
+			return
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Status hanya boleh berubah lewat UbahStatusJadwal supaya state
+		// machine dan riwayat status tetap berlaku
+		if err := tx.Model(&models.Jadwal{}).Where("id = ?", id).Omit("status", "sedang_rekam").Updates(jadwal).Error; err != nil {
+			return err
+		}
+		// Updates(struct) melewati pointer nil, jadi kolom pengaturan
+		// rekaman yang dikirim diupdate lewat Select agar bisa dikosongkan
+		var kolom []string
+		for field := range kebijakan {
+			kolom = append(kolom, field)
+		}
+		if len(kolom) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Jadwal{}).Where("id = ?", id).Select(kolom).Updates(jadwal).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	catatAuditUbah(c, audit.EntitasJadwal, existing.ID, existing, &models.Jadwal{})
+
+	if jadwalID, err := uuid.Parse(id); err == nil {
+		notifyScheduler(jadwalID)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Jadwal berhasil diupdate"})
 }
 
+// mergeJadwalUpdate menerapkan field yang diisi pada update ke jadwal lama,
+// sama seperti Updates() gorm yang mengabaikan field kosong. Pengaturan
+// rekaman di kebijakan ikut diterapkan walaupun dikirim null.
+func mergeJadwalUpdate(existing models.Jadwal, update models.Jadwal, kebijakan map[string]bool) models.Jadwal {
+	merged := existing
+	if update.NamaMatkul != "" {
+		merged.NamaMatkul = update.NamaMatkul
+	}
+	if update.MataKuliahID != nil {
+		merged.MataKuliahID = update.MataKuliahID
+	}
+	if update.SemesterID != nil {
+		merged.SemesterID = update.SemesterID
+	}
+	if update.RuanganID != nil {
+		merged.RuanganID = update.RuanganID
+	}
+	if update.DosenID != uuid.Nil {
+		merged.DosenID = update.DosenID
+	}
+	if update.Hari != "" {
+		merged.Hari = update.Hari
+	}
+	if update.WaktuMulai != "" {
+		merged.WaktuMulai = update.WaktuMulai
+	}
+	if update.WaktuSelesai != "" {
+		merged.WaktuSelesai = update.WaktuSelesai
+	}
+	if update.Ruangan != "" {
+		merged.Ruangan = update.Ruangan
+	}
+	if update.RekamJumlahSesi != nil || kebijakan["rekam_jumlah_sesi"] {
+		merged.RekamJumlahSesi = update.RekamJumlahSesi
+	}
+	if update.RekamDurasiSesi != nil || kebijakan["rekam_durasi_sesi"] {
+		merged.RekamDurasiSesi = update.RekamDurasiSesi
+	}
+	if update.RekamOffsetMulai != nil || kebijakan["rekam_offset_mulai"] {
+		merged.RekamOffsetMulai = update.RekamOffsetMulai
+	}
+	if update.RekamJedaSesi != nil || kebijakan["rekam_jeda_sesi"] {
+		merged.RekamJedaSesi = update.RekamJedaSesi
+	}
+	if update.RekamPenuh != nil || kebijakan["rekam_penuh"] {
+		merged.RekamPenuh = update.RekamPenuh
+	}
+	return merged
+}
+
+// kebijakanDikirim mengembalikan field pengaturan rekaman yang ada di body
+// update, termasuk yang dikirim null
+func kebijakanDikirim(c *gin.Context) (map[string]bool, error) {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(c.MustGet(gin.BodyBytesKey).([]byte), &body); err != nil {
+		return nil, err
+	}
+
+	dikirim := make(map[string]bool)
+	for _, field := range []string{"rekam_jumlah_sesi", "rekam_durasi_sesi", "rekam_offset_mulai", "rekam_jeda_sesi", "rekam_penuh"} {
+		if _, ok := body[field]; ok {
+			dikirim[field] = true
+		}
+	}
+	return dikirim, nil
+}
+
+// statusAwalJadwal menentukan status jadwal baru berdasarkan waktu sekarang
+func statusAwalJadwal(jadwal models.Jadwal) string {
+	return jadwal.StatusPada(kalender.Default().Sekarang())
+}
+
+// terapkanKatalog menghubungkan jadwal ke katalog mata kuliah, ruangan dan
+// semester. Jika hanya nama_matkul atau ruangan yang dikirim, entri katalog
+// dicari berdasarkan nama/kode atau dibuat baru. Jadwal baru tanpa
+// semester_id masuk ke semester aktif.
+func terapkanKatalog(db *gorm.DB, jadwal *models.Jadwal, baru bool) error {
+	// Relasi dari body request tidak ikut disimpan
+	jadwal.MataKuliah = nil
+	jadwal.Semester = nil
+	jadwal.DetailRuangan = nil
+
+	if jadwal.MataKuliahID != nil {
+		var matkul models.MataKuliah
+		if err := db.First(&matkul, "id = ?", *jadwal.MataKuliahID).Error; err != nil {
+			return errors.New("Mata kuliah tidak ditemukan")
+		}
+		jadwal.NamaMatkul = matkul.Nama
+	} else if jadwal.NamaMatkul != "" {
+		matkul, err := models.CariAtauBuatMataKuliah(db, jadwal.NamaMatkul)
+		if err != nil {
+			return err
+		}
+		jadwal.MataKuliahID = &matkul.ID
+		jadwal.NamaMatkul = matkul.Nama
+	} else if baru {
+		return errors.New("nama_matkul atau mata_kuliah_id wajib diisi")
+	}
+
+	if jadwal.RuanganID != nil {
+		var ruangan models.Ruangan
+		if err := db.First(&ruangan, "id = ?", *jadwal.RuanganID).Error; err != nil {
+			return errors.New("Ruangan tidak ditemukan")
+		}
+		jadwal.Ruangan = ruangan.Kode
+	} else if jadwal.Ruangan != "" {
+		ruangan, err := models.CariAtauBuatRuangan(db, jadwal.Ruangan)
+		if err != nil {
+			return err
+		}
+		jadwal.RuanganID = &ruangan.ID
+		jadwal.Ruangan = ruangan.Kode
+	}
+
+	if jadwal.SemesterID != nil {
+		var semester models.Semester
+		if err := db.First(&semester, "id = ?", *jadwal.SemesterID).Error; err != nil {
+			return errors.New("Semester tidak ditemukan")
+		}
+	} else if baru {
+		if semester := models.SemesterAktif(db); semester != nil {
+			jadwal.SemesterID = &semester.ID
+		}
+	}
+	return nil
+}
+
 func HapusJadwal(c *gin.Context) {
 	id := c.Param("id")
 	
 	db := database.GetDB()
+	var sebelum models.Jadwal
+	ditemukan := db.First(&sebelum, "id = ?", id).Error == nil
+
 	result := db.Delete(&models.Jadwal{}, "id = ?", id)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
 	}
+	if ditemukan {
+		catatAudit(c, audit.EntitasJadwal, sebelum.ID, audit.AksiHapus, sebelum, nil)
+	}
+
+	if jadwalID, err := uuid.Parse(id); err == nil {
+		notifyScheduler(jadwalID)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Jadwal berhasil dihapus"})
 }
@@ -248,7 +529,7 @@ func DapatkanJadwalSedangRekam(c *gin.Context) {
 	var jadwal []models.Jadwal
 	
 	db := database.GetDB()
-	result := db.Preload("Dosen").Where("sedang_rekam = ?", true).Find(&jadwal)
+	result := lingkupJadwal(c, db.Preload("Dosen")).Where("sedang_rekam = ?", true).Find(&jadwal)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
@@ -269,22 +550,32 @@ func MulaiRekamanOtomatis(c *gin.Context) {
         return
     }
 
-    // Validasi apakah jadwal aktif dan sesuai waktu
-    now := time.Now()
-    currentDay := strings.ToUpper(now.Format("Monday"))
-    currentTime := now.Format("15:04")
+    // Validasi apakah jadwal aktif dan sesuai waktu di zona waktu kampus
+    kal := kalender.Default()
+    now := kal.Sekarang()
+    currentDay := kal.Hari(now)
+    currentTime := kal.Jam(now)
 
     if jadwal.Hari != currentDay {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Jadwal tidak aktif hari ini"})
         return
     }
 
-    // Mulai proses rekaman otomatis di goroutine terpisah
-    go ExecuteScheduledRecording(jadwal)
+    // Masukkan job rekaman ke antrian, worker akan mengeksekusinya
+    job, err := recordingQueue.Enqueue(jadwal)
+    if errors.Is(err, recording.ErrJobAktif) {
+        c.JSON(http.StatusConflict, gin.H{"error": "Rekaman otomatis untuk jadwal ini masih berjalan"})
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
 
     c.JSON(http.StatusOK, gin.H{
         "message": "Auto recording started",
         "jadwal_id": id,
+        "job_id": job.ID,
         "waktu": currentTime,
     })
 }
@@ -300,161 +591,54 @@ func GetRecordingSchedule(c *gin.Context) {
         return
     }
 
-    recordingTimes := calculateRecordingTimes(jadwal.WaktuMulai)
-    
-    c.JSON(http.StatusOK, gin.H{
-        "jadwal_id": id,
-        "recording_schedule": recordingTimes,
-        "duration_seconds": 60, // 1 menit
-        "total_sessions": 5,    // 5 session 
-    })
-}
-
-// Fungsi untuk eksekusi rekaman terjadwal
-func ExecuteScheduledRecording(jadwal models.Jadwal) {
-    db := database.GetDB()
-    
-    // Update status jadwal menjadi sedang merekam
-    db.Model(&jadwal).Updates(map[string]interface{}{
-        "sedang_rekam": true,
-        "status":       "merekam",
-        "tanggal_diupdate": time.Now(),
-    })
-
-    // Buat direktori recordings jika belum ada
-    os.MkdirAll("recordings", 0755)
-
-    // Lakukan 5x rekaman tanpa jeda, masing-masing 1 menit
-    for session := 1; session <= 5; session++ {
-        timestamp := time.Now().Format("20060102_150405")
-        filename := fmt.Sprintf("recording_%s_session%d_%s.wav", jadwal.ID, session, timestamp)
-        filepath := filepath.Join("recordings", filename)
-
-        fmt.Printf("Starting recording session %d for jadwal %s\n", session, jadwal.ID)
-
-        // Rekam audio menggunakan ffmpeg (1 menit = 60 detik)
-        cmd := exec.Command("ffmpeg", 
-            "-f", "dshow",               // Windows directshow
-            "-i", "audio=Microphone",    // Audio input device
-            "-t", "60",                  // Duration 60 detik (1 menit)
-            "-acodec", "pcm_s16le",      // Audio codec
-            "-ar", "16000",              // Sample rate
-            "-ac", "1",                  // Mono audio
-            filepath,                    // Output file
-            "-y",                        // Overwrite output file
-        )
-
-        if err := cmd.Run(); err != nil {
-            fmt.Printf("Error recording audio session %d: %v\n", session, err)
-            continue
-        }
-
-        fmt.Printf("Recording session %d completed: %s\n", session, filepath)
-
-        // Kirim ke Python backend untuk analisis
-        analysis, err := sendAudioForAnalysis(filepath)
-        if err != nil {
-            fmt.Printf("Error sending audio for analysis session %d: %v\n", session, err)
-            continue
-        }
-
-        // Simpan hasil analisis ke tabel Evaluasi
-        evaluasi := models.Evaluasi{
-            JadwalID:             jadwal.ID,
-            KepercayaanPembicara: analysis.Similarity,
-            TeksTranskripsi:      analysis.Transcript,
-            Rangkuman:            analysis.Analysis.Summary,
-            SkorEfektivitas:      float64(analysis.Analysis.Effectiveness) / 100.0,
-            PathFileAudio:        filepath,
-            WaktuPemrosesan:      0,
-        }
-
-        result := db.Create(&evaluasi)
-        if result.Error != nil {
-            fmt.Printf("Error saving evaluasi session %d: %v\n", session, result.Error)
-        } else {
-            fmt.Printf("Evaluation saved successfully for session %d\n", session)
-        }
-
-        // TANPA JEDA - langsung lanjut ke rekaman berikutnya
-    }
-
-    // Kembalikan status jadwal
-    newStatus := "selesai"
-    if jadwal.IsOngoing() {
-        newStatus = "aktif"
-    }
-
-    db.Model(&jadwal).Updates(map[string]interface{}{
-        "sedang_rekam": false,
-        "status":       newStatus,
-        "tanggal_diupdate": time.Now(),
-    })
-
-    fmt.Printf("Auto recording completed for jadwal %s\n", jadwal.ID)
-}
-
-// Fungsi untuk mengirim audio ke Python backend
-func sendAudioForAnalysis(filepath string) (*AudioAnalysisResponse, error) {
-    // Baca file audio
-    audioData, err := os.ReadFile(filepath)
-    if err != nil {
-        return nil, fmt.Errorf("error reading audio file: %v", err)
-    }
-
-    // Buat request ke Python backend
-    client := &http.Client{Timeout: 300 * time.Second} // 5 minutes timeout
-    req, err := http.NewRequest("POST", "http://192.168.1.75/upload-audio", bytes.NewReader(audioData))
-    if err != nil {
-        return nil, fmt.Errorf("error creating request: %v", err)
-    }
-    
-    req.Header.Set("Content-Type", "audio/wav")
-
-    resp, err := client.Do(req)
+    kebijakan := jadwal.KebijakanRekaman()
+    sesi, err := kebijakan.Jadwalkan(jadwal.WaktuMulai, jadwal.WaktuSelesai)
     if err != nil {
-        return nil, fmt.Errorf("error sending request: %v", err)
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
     }
-    defer resp.Body.Close()
 
-    if resp.StatusCode != http.StatusOK {
-        return nil, fmt.Errorf("server returned non-200 status: %d", resp.StatusCode)
+    recordingTimes := make([]string, 0, len(sesi))
+    for _, s := range sesi {
+        recordingTimes = append(recordingTimes, s.WaktuMulai)
     }
 
-    var analysisResp AudioAnalysisResponse
-    if err := json.NewDecoder(resp.Body).Decode(&analysisResp); err != nil {
-        return nil, fmt.Errorf("error decoding response: %v", err)
+    durationSeconds := kebijakan.DurasiSesi
+    if len(sesi) > 0 {
+        durationSeconds = sesi[0].DurasiSesi
     }
 
-    return &analysisResp, nil
+    c.JSON(http.StatusOK, gin.H{
+        "jadwal_id": id,
+        "recording_schedule": recordingTimes,
+        "duration_seconds": durationSeconds,
+        "total_sessions": len(sesi),
+        "gap_seconds": kebijakan.JedaSesi,
+        "offset_minutes": kebijakan.OffsetMulai,
+        "full_class": kebijakan.RekamPenuh,
+        "sessions": sesi,
+    })
 }
 
-func calculateRecordingTimes(startTime string) []string {
-    // 5x rekaman dimulai 10 menit setelah jam mulai, dengan interval 1 menit
-    return []string{
-        addMinutes(startTime, 10),  // Session 1: 10 menit setelah mulai
-        addMinutes(startTime, 11),  // Session 2: 11 menit setelah mulai  
-        addMinutes(startTime, 12),  // Session 3: 12 menit setelah mulai
-        addMinutes(startTime, 13),  // Session 4: 13 menit setelah mulai
-        addMinutes(startTime, 14),  // Session 5: 14 menit setelah mulai
-    }
-}
+// calculateRecordingTimes menghitung jam mulai setiap sesi rekaman jadwal
+// berdasarkan kebijakan rekamannya
+func calculateRecordingTimes(jadwal models.Jadwal) []string {
+    sesi, _ := jadwal.KebijakanRekaman().Jadwalkan(jadwal.WaktuMulai, jadwal.WaktuSelesai)
 
-func addMinutes(timeStr string, minutes int) string {
-    t, _ := time.Parse("15:04", timeStr)
-    t = t.Add(time.Duration(minutes) * time.Minute)
-    return t.Format("15:04")
+    times := make([]string, 0, len(sesi))
+    for _, s := range sesi {
+        times = append(times, s.WaktuMulai)
+    }
+    return times
 }
 
 // Handler untuk mendapatkan jadwal aktif hari ini
 func DapatkanJadwalAktifHariIni(c *gin.Context) {
-    UpdateJadwalStatus()
-    
-    currentDay := strings.ToUpper(time.Now().Format("Monday"))
+    currentDay := kalender.Default().HariIni()
     
     var jadwal []models.Jadwal
     db := database.GetDB()
-    result := db.Preload("Dosen").Where("hari = ? AND status = ?", currentDay, "aktif").Find(&jadwal)
+    result := lingkupJadwal(c, db.Preload("Dosen")).Where("hari = ? AND status = ?", currentDay, "aktif").Find(&jadwal)
     if result.Error != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
         return
@@ -463,12 +647,14 @@ func DapatkanJadwalAktifHariIni(c *gin.Context) {
     c.JSON(http.StatusOK, jadwal)
 }
 
-// Handler untuk update status jadwal
+// Handler untuk update status jadwal secara manual. Perubahan harus sesuai
+// state machine status jadwal dan dicatat di riwayat status.
 func UpdateStatusJadwal(c *gin.Context) {
     id := c.Param("id")
     
     var input struct {
         Status string `json:"status" binding:"required"`
+        Alasan string `json:"alasan"`
     }
     
     if err := c.ShouldBindJSON(&input); err != nil {
@@ -477,10 +663,10 @@ func UpdateStatusJadwal(c *gin.Context) {
     }
 
     validStatus := map[string]bool{
-        "terjadwal": true,
-        "aktif":     true,
-        "merekam":   true,
-        "selesai":   true,
+        models.JadwalTerjadwal: true,
+        models.JadwalAktif:     true,
+        models.JadwalMerekam:   true,
+        models.JadwalSelesai:   true,
     }
     
     if !validStatus[input.Status] {
@@ -489,16 +675,33 @@ func UpdateStatusJadwal(c *gin.Context) {
     }
 
     db := database.GetDB()
-    result := db.Model(&models.Jadwal{}).Where("id = ?", id).Updates(map[string]interface{}{
-        "status": input.Status,
-        "tanggal_diupdate": time.Now(),
-    })
-    
-    if result.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+    var jadwal models.Jadwal
+    if err := db.First(&jadwal, "id = ?", id).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
+        return
+    }
+
+    // Status merekam hanya diatur lewat endpoint rekaman agar sedang_rekam
+    // dan job rekaman tetap konsisten
+    if jadwal.SedangRekam && input.Status != models.JadwalMerekam {
+        c.JSON(http.StatusConflict, gin.H{"error": "Jadwal sedang merekam, hentikan rekaman terlebih dahulu"})
+        return
+    }
+    if !jadwal.SedangRekam && input.Status == models.JadwalMerekam {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Gunakan endpoint mulai-rekam untuk memulai rekaman"})
         return
     }
 
+    alasan := input.Alasan
+    if alasan == "" {
+        alasan = "status diubah manual"
+    }
+    if err := models.UbahStatusJadwal(db, jadwal.ID, input.Status, aktorRequest(c), alasan, nil); err != nil {
+        responTransisiGagal(c, err, "Jadwal tidak ditemukan")
+        return
+    }
+    catatAuditUbah(c, audit.EntitasJadwal, jadwal.ID, jadwal, &models.Jadwal{})
+
     c.JSON(http.StatusOK, gin.H{"message": "Status jadwal berhasil diupdate"})
 }
 
@@ -508,14 +711,14 @@ func DapatkanJadwalByHari(c *gin.Context) {
     hari := c.Param("hari")
     
     // Validasi hari
-    if !validHari[hari] {
+    if !kalender.HariValid(hari) {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Hari tidak valid"})
         return
     }
     
     var jadwal []models.Jadwal
     db := database.GetDB()
-    result := db.Preload("Dosen").Where("hari = ?", hari).Find(&jadwal)
+    result := lingkupJadwal(c, db.Preload("Dosen")).Where("hari = ?", hari).Find(&jadwal)
     if result.Error != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
         return
@@ -536,15 +739,21 @@ func GetStatistikEvaluasi(c *gin.Context) {
         TopDosen         string  `json:"top_dosen"`
     }
     
+    // Dosen hanya melihat statistik evaluasinya sendiri
+    evaluasi := func() *gorm.DB {
+        return lingkupEvaluasi(c, db.Model(&models.Evaluasi{}))
+    }
+
     // Total evaluasi
-    db.Model(&models.Evaluasi{}).Count(&stats.TotalEvaluasi)
+    evaluasi().Count(&stats.TotalEvaluasi)
     
     // Rata-rata skor efektivitas
-    db.Model(&models.Evaluasi{}).Select("AVG(skor_efektivitas)").Scan(&stats.RataRataSkor)
+    evaluasi().Select("AVG(skor_efektivitas)").Scan(&stats.RataRataSkor)
     
-    // Evaluasi hari ini
-    today := time.Now().Format("2006-01-02")
-    db.Model(&models.Evaluasi{}).Where("DATE(tanggal_dibuat) = ?", today).Count(&stats.EvaluasiHariIni)
+    // Evaluasi hari ini (tanggal kampus)
+    kal := kalender.Default()
+    awalHari := kal.AwalHari(kal.Sekarang())
+    evaluasi().Where("tanggal_dibuat >= ? AND tanggal_dibuat < ?", awalHari, awalHari.AddDate(0, 0, 1)).Count(&stats.EvaluasiHariIni)
     
     c.JSON(http.StatusOK, stats)
 }
@@ -561,7 +770,7 @@ func GetEvaluasiRecent(c *gin.Context) {
     
     var evaluasi []models.Evaluasi
     db := database.GetDB()
-    result := db.Preload("Jadwal.Dosen").
+    result := lingkupEvaluasi(c, db.Preload("Jadwal.Dosen")).
         Order("tanggal_dibuat DESC").
         Limit(limit).
         Find(&evaluasi)
@@ -579,23 +788,78 @@ func GetEvaluasiRecent(c *gin.Context) {
 func StartScheduledRecording(c *gin.Context) {
     jadwalID := c.Param("jadwal_id")
     
-    // Implementasi start scheduled recording
+    var jadwal models.Jadwal
+    db := database.GetDB()
+    result := db.Preload("Dosen").First(&jadwal, "id = ?", jadwalID)
+    if result.Error != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
+        return
+    }
+
+    job, err := recordingQueue.Enqueue(jadwal)
+    if errors.Is(err, recording.ErrJobAktif) {
+        c.JSON(http.StatusConflict, gin.H{"error": "Rekaman untuk jadwal ini masih berjalan"})
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
     c.JSON(http.StatusOK, gin.H{
         "message": "Scheduled recording started",
         "jadwal_id": jadwalID,
+        "job": job,
     })
 }
 
 func StopScheduledRecording(c *gin.Context) {
     jadwalID := c.Param("jadwal_id")
     
-    // Implementasi stop scheduled recording
+    job, err := stopRecordingJob(jadwalID)
+    if err != nil {
+        c.JSON(stopErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
     c.JSON(http.StatusOK, gin.H{
         "message": "Scheduled recording stopped",
         "jadwal_id": jadwalID,
+        "job": job,
+        "ringkasan": ringkasanJob(job),
     })
 }
 
+// stopRecordingJob menghentikan job rekaman aktif milik jadwal
+func stopRecordingJob(jadwalID string) (*models.RekamanJob, error) {
+    id, err := uuid.Parse(jadwalID)
+    if err != nil {
+        return nil, recording.ErrTidakAdaJob
+    }
+    return recordingQueue.Stop(id)
+}
+
+// stopErrorStatus memetakan error penghentian rekaman ke HTTP status
+func stopErrorStatus(err error) int {
+    switch {
+    case errors.Is(err, recording.ErrTidakAdaJob):
+        return http.StatusNotFound
+    case errors.Is(err, recording.ErrJobDiWorkerLain):
+        return http.StatusConflict
+    default:
+        return http.StatusInternalServerError
+    }
+}
+
+// ringkasanJob menghitung jumlah sesi per status untuk response
+func ringkasanJob(job *models.RekamanJob) map[string]int {
+    ringkasan := map[string]int{}
+    for _, sesi := range job.Sesi {
+        ringkasan[sesi.Status]++
+    }
+    return ringkasan
+}
+
 func GetActiveRecordings(c *gin.Context) {
     var jadwal []models.Jadwal
     db := database.GetDB()
@@ -621,11 +885,23 @@ func GetRecordingStatus(c *gin.Context) {
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{
+    response := gin.H{
         "jadwal_id": jadwalID,
         "sedang_rekam": jadwal.SedangRekam,
         "status": jadwal.Status,
-    })
+        "job": nil,
+    }
+
+    // Sertakan job rekaman terakhir beserta status tiap sesi
+    job, err := recordingQueue.LatestJob(jadwalID)
+    if err == nil {
+        response["job"] = job
+    } else if !errors.Is(err, gorm.ErrRecordNotFound) {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, response)
 }
 
 func ProcessAudioAnalysis(c *gin.Context) {
@@ -670,8 +946,17 @@ func GetDashboardStats(c *gin.Context) {
 }
 
 func GetUpcomingJadwal(c *gin.Context) {
-    // Implementasi upcoming jadwal
-    c.JSON(http.StatusOK, []interface{}{})
+    if jadwalScheduler == nil {
+        c.JSON(http.StatusOK, []interface{}{})
+        return
+    }
+
+    limit := 5
+    if parsedLimit, err := strconv.Atoi(c.DefaultQuery("limit", "5")); err == nil && parsedLimit > 0 {
+        limit = parsedLimit
+    }
+
+    c.JSON(http.StatusOK, jadwalScheduler.Upcoming(limit))
 }
 
 // handlers/system.go - Buat file baru untuk system handlers
@@ -683,20 +968,41 @@ func GetSystemHealth(c *gin.Context) {
         "services": map[string]string{
             "database": "connected",
             "api":      "running",
+            "storage":  blobStore.Name(),
         },
     })
 }
 
 func GetSystemConfig(c *gin.Context) {
+    kebijakan := models.DefaultKebijakanRekaman()
+    totalDurasi := kebijakan.DurasiSesi * kebijakan.JumlahSesi
+
+    recordingDuration := fmt.Sprintf("%ds", totalDurasi)
+    if kebijakan.RekamPenuh {
+        recordingDuration = "full_class"
+    }
+
     c.JSON(http.StatusOK, gin.H{
-        "auto_recording_enabled": true,
+        "auto_recording_enabled": jadwalScheduler != nil,
         "max_upload_size":        "10MB",
-        "recording_duration":     "150s",
+        "recording_duration":     recordingDuration,
+        "recording_policy":       kebijakan,
+        "timezone":               kalender.Default().Lokasi().String(),
     })
 }
 
 func CheckPythonBackend(c *gin.Context) {
-    // Implementasi check Python backend
+    ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+    defer cancel()
+
+    if err := getAnalysisClient().Ping(ctx); err != nil {
+        c.JSON(http.StatusServiceUnavailable, gin.H{
+            "python_backend": "unreachable",
+            "error":          err.Error(),
+        })
+        return
+    }
+
     c.JSON(http.StatusOK, gin.H{
         "python_backend": "reachable",
     })
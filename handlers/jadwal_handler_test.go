@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dbUji membuka database SQLite sementara dengan skema aplikasi dan
+// memasangnya sebagai database.DB selama pengujian berjalan
+func dbUji(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "handlers.db")), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MigrateDB(db); err != nil {
+		t.Fatal(err)
+	}
+	lama := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = lama })
+	return db
+}
+
+// kirim menjalankan request ke handler lewat router gin dengan pola route
+// dan mengembalikan responsnya
+func kirim(t *testing.T, method string, route string, path string, body interface{}, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, route, handler)
+
+	var isi bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&isi).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &isi)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUpdateJadwalTidakMengubahStatus(t *testing.T) {
+	db := dbUji(t)
+	jadwal := models.Jadwal{NamaMatkul: "Algoritma", Hari: "SENIN", WaktuMulai: "08:00", WaktuSelesai: "10:00"}
+	if err := db.Create(&jadwal).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	body := map[string]interface{}{
+		"waktu_mulai":   "09:00",
+		"waktu_selesai": "11:00",
+		"status":        models.JadwalSelesai,
+		"sedang_rekam":  true,
+	}
+	rec := kirim(t, http.MethodPut, "/jadwal/:id", "/jadwal/"+jadwal.ID.String(), body, UpdateJadwal)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status HTTP = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	var tersimpan models.Jadwal
+	db.First(&tersimpan, "id = ?", jadwal.ID)
+	if tersimpan.WaktuMulai != "09:00" || tersimpan.WaktuSelesai != "11:00" {
+		t.Errorf("waktu = %s-%s, want 09:00-11:00", tersimpan.WaktuMulai, tersimpan.WaktuSelesai)
+	}
+	if tersimpan.Status != models.JadwalTerjadwal || tersimpan.SedangRekam {
+		t.Errorf("status = %q, sedang_rekam = %v; want tidak berubah", tersimpan.Status, tersimpan.SedangRekam)
+	}
+	var jumlahLog int64
+	db.Model(&models.JadwalStatusLog{}).Count(&jumlahLog)
+	if jumlahLog != 0 {
+		t.Errorf("jumlah log status = %d, want 0", jumlahLog)
+	}
+}
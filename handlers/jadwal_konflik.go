@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"strings"
+
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Jenis bentrok jadwal
+const (
+	KonflikDosen   = "dosen"
+	KonflikRuangan = "ruangan"
+)
+
+// KonflikJadwal adalah jadwal lain yang waktunya tumpang tindih
+type KonflikJadwal struct {
+	JadwalID     uuid.UUID `json:"jadwal_id"`
+	NamaMatkul   string    `json:"nama_matkul"`
+	NamaDosen    string    `json:"nama_dosen"`
+	Hari         string    `json:"hari"`
+	WaktuMulai   string    `json:"waktu_mulai"`
+	WaktuSelesai string    `json:"waktu_selesai"`
+	Ruangan      string    `json:"ruangan"`
+	Jenis        []string  `json:"jenis"`
+}
+
+// cariKonflikJadwal mencari jadwal di hari yang sama dengan dosen atau
+// ruangan yang sama dan rentang waktu yang tumpang tindih. Jadwal dari
+// semester yang berbeda tidak dianggap bentrok.
+func cariKonflikJadwal(db *gorm.DB, jadwal models.Jadwal) ([]KonflikJadwal, error) {
+	ruangan := models.NormalisasiKodeRuangan(jadwal.Ruangan)
+
+	query := db.Preload("Dosen").
+		Where("hari = ? AND waktu_mulai < ? AND waktu_selesai > ?",
+			jadwal.Hari, jadwal.WaktuSelesai, jadwal.WaktuMulai)
+	if jadwal.ID != uuid.Nil {
+		query = query.Where("id <> ?", jadwal.ID)
+	}
+	if jadwal.SemesterID != nil {
+		query = query.Where("semester_id IS NULL OR semester_id = ?", *jadwal.SemesterID)
+	}
+	if ruangan != "" {
+		query = query.Where("dosen_id = ? OR UPPER(ruangan) = ?", jadwal.DosenID, ruangan)
+	} else {
+		query = query.Where("dosen_id = ?", jadwal.DosenID)
+	}
+
+	var bentrok []models.Jadwal
+	if err := query.Order("waktu_mulai ASC").Find(&bentrok).Error; err != nil {
+		return nil, err
+	}
+
+	konflik := make([]KonflikJadwal, 0, len(bentrok))
+	for _, j := range bentrok {
+		var jenis []string
+		if j.DosenID == jadwal.DosenID {
+			jenis = append(jenis, KonflikDosen)
+		}
+		if ruangan != "" && strings.EqualFold(strings.TrimSpace(j.Ruangan), ruangan) {
+			jenis = append(jenis, KonflikRuangan)
+		}
+		konflik = append(konflik, KonflikJadwal{
+			JadwalID:     j.ID,
+			NamaMatkul:   j.NamaMatkul,
+			NamaDosen:    j.Dosen.Nama,
+			Hari:         j.Hari,
+			WaktuMulai:   j.WaktuMulai,
+			WaktuSelesai: j.WaktuSelesai,
+			Ruangan:      j.Ruangan,
+			Jenis:        jenis,
+		})
+	}
+	return konflik, nil
+}
+
+// izinkanKonflik membaca flag ?override=true untuk jadwal yang sengaja
+// dibuat bersamaan, misalnya team-teaching
+func izinkanKonflik(c *gin.Context) bool {
+	override := strings.ToLower(c.Query("override"))
+	return override == "true" || override == "1"
+}
+
+// responKonflik membuat body 409 untuk jadwal yang bentrok
+func responKonflik(konflik []KonflikJadwal) gin.H {
+	return gin.H{
+		"error":    "Jadwal bentrok dengan jadwal lain",
+		"konflik":  konflik,
+		"petunjuk": "Kirim ulang dengan ?override=true jika jadwal memang sengaja bersamaan (team-teaching)",
+	}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"CLAIRE/database"
+	"CLAIRE/ical"
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const prodIDKalender = "-//CLAIRE//Jadwal Kuliah//ID"
+
+// Handler untuk feed kalender seluruh jadwal
+func KalenderSemuaJadwal(c *gin.Context) {
+	db := database.GetDB()
+	query := db.Model(&models.Jadwal{})
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("semester_id = ?", semesterID)
+	}
+	kirimKalender(c, query, "Jadwal Kuliah", "jadwal.ics")
+}
+
+// Handler untuk feed kalender mengajar seorang dosen
+func KalenderDosen(c *gin.Context) {
+	id := c.Param("id")
+
+	var dosen models.Dosen
+	db := database.GetDB()
+	if err := db.First(&dosen, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dosen tidak ditemukan"})
+		return
+	}
+
+	nama := strings.TrimSpace(dosen.Nama + " " + dosen.Gelar)
+	kirimKalender(c, db.Where("dosen_id = ?", dosen.ID), "Jadwal Mengajar "+nama, "jadwal-dosen.ics")
+}
+
+// Handler untuk feed kalender pemakaian sebuah ruangan
+func KalenderRuangan(c *gin.Context) {
+	id := c.Param("id")
+
+	var ruangan models.Ruangan
+	db := database.GetDB()
+	if err := db.First(&ruangan, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ruangan tidak ditemukan"})
+		return
+	}
+
+	kirimKalender(c, db.Where("ruangan_id = ?", ruangan.ID), "Jadwal Ruangan "+ruangan.Kode, "jadwal-ruangan.ics")
+}
+
+// kirimKalender mengambil jadwal dari query dan mengirimnya sebagai .ics
+func kirimKalender(c *gin.Context, query *gorm.DB, nama string, filename string) {
+	var jadwals []models.Jadwal
+	result := lingkupJadwal(c, query).Preload("Dosen").Preload("Semester").Preload("DetailRuangan").
+		Order("hari ASC, waktu_mulai ASC").Find(&jadwals)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	var libur []models.HariLibur
+	database.GetDB().Find(&libur)
+
+	kal := kalender.Default()
+	loc := kal.Lokasi()
+	feed := ical.Calendar{
+		ProdID:   prodIDKalender,
+		Name:     nama,
+		Location: loc,
+	}
+	now := kal.Sekarang()
+	for _, jadwal := range jadwals {
+		if event, ok := eventJadwal(jadwal, loc, libur, now); ok {
+			feed.Events = append(feed.Events, event)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := feed.Write(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// eventJadwal mengubah jadwal mingguan menjadi event berulang. Jadwal yang
+// punya semester berulang sampai akhir semester; tanggal libur dilewati.
+func eventJadwal(jadwal models.Jadwal, loc *time.Location, libur []models.HariLibur, now time.Time) (ical.Event, bool) {
+	weekday, ok := kalender.Weekday(jadwal.Hari)
+	if !ok {
+		return ical.Event{}, false
+	}
+
+	anchor := jadwal.TanggalDibuat
+	var until time.Time
+	if jadwal.Semester != nil {
+		anchor = jadwal.Semester.TanggalMulai
+		s := jadwal.Semester.TanggalSelesai
+		until = time.Date(s.Year(), s.Month(), s.Day(), 23, 59, 59, 0, loc)
+	}
+	if anchor.IsZero() {
+		anchor = now
+	}
+
+	// Kejadian pertama: hari jadwal pada atau setelah tanggal anchor
+	tanggal := time.Date(anchor.Year(), anchor.Month(), anchor.Day(), 0, 0, 0, 0, loc)
+	for tanggal.Weekday() != weekday {
+		tanggal = tanggal.AddDate(0, 0, 1)
+	}
+	start, errMulai := jamPada(tanggal, jadwal.WaktuMulai)
+	end, errSelesai := jamPada(tanggal, jadwal.WaktuSelesai)
+	if errMulai != nil || errSelesai != nil {
+		return ical.Event{}, false
+	}
+
+	event := ical.Event{
+		UID:         jadwal.ID.String() + "@claire",
+		Summary:     jadwal.NamaMatkul,
+		Location:    lokasiRuangan(jadwal),
+		Description: deskripsiJadwal(jadwal),
+		Start:       start,
+		End:         end,
+		RRule:       ical.WeeklyRule(weekday, until),
+		Stamp:       jadwal.TanggalDiupdate,
+	}
+
+	for _, l := range libur {
+		hari := time.Date(l.Tanggal.Year(), l.Tanggal.Month(), l.Tanggal.Day(), 0, 0, 0, 0, loc)
+		if hari.Weekday() != weekday || hari.Before(tanggal) || (!until.IsZero() && hari.After(until)) {
+			continue
+		}
+		if ex, err := jamPada(hari, jadwal.WaktuMulai); err == nil {
+			event.ExDates = append(event.ExDates, ex)
+		}
+	}
+	return event, true
+}
+
+// jamPada menggabungkan tanggal dengan jam HH:MM di zona tanggal
+func jamPada(tanggal time.Time, jam string) (time.Time, error) {
+	t, err := time.Parse("15:04", jam)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), t.Hour(), t.Minute(), 0, 0, tanggal.Location()), nil
+}
+
+func lokasiRuangan(jadwal models.Jadwal) string {
+	if jadwal.DetailRuangan == nil {
+		return jadwal.Ruangan
+	}
+	lokasi := jadwal.DetailRuangan.Kode
+	if jadwal.DetailRuangan.Nama != "" && jadwal.DetailRuangan.Nama != jadwal.DetailRuangan.Kode {
+		lokasi += " - " + jadwal.DetailRuangan.Nama
+	}
+	if jadwal.DetailRuangan.Gedung != "" {
+		lokasi += ", " + jadwal.DetailRuangan.Gedung
+	}
+	return lokasi
+}
+
+func deskripsiJadwal(jadwal models.Jadwal) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dosen: %s", strings.TrimSpace(jadwal.Dosen.Nama+" "+jadwal.Dosen.Gelar))
+	if jadwal.Ruangan != "" {
+		fmt.Fprintf(&b, "\nRuangan: %s", jadwal.Ruangan)
+	}
+	if jadwal.Semester != nil {
+		fmt.Fprintf(&b, "\nSemester: %s", jadwal.Semester.Nama)
+	}
+	if waktu := calculateRecordingTimes(jadwal); len(waktu) > 0 {
+		fmt.Fprintf(&b, "\nRekaman otomatis: %s", strings.Join(waktu, ", "))
+	}
+	return b.String()
+}
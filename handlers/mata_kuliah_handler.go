@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func BuatMataKuliah(c *gin.Context) {
+	var input struct {
+		Kode         string `json:"kode" binding:"required"`
+		Nama         string `json:"nama" binding:"required"`
+		SKS          int    `json:"sks"`
+		ProgramStudi string `json:"program_studi"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.SKS < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "SKS tidak boleh negatif"})
+		return
+	}
+
+	db := database.GetDB()
+	if pesan := cekDuplikatMataKuliah(db, "", input.Kode, input.Nama); pesan != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": pesan})
+		return
+	}
+
+	matkul := models.MataKuliah{
+		Kode:         strings.ToUpper(strings.TrimSpace(input.Kode)),
+		Nama:         strings.Join(strings.Fields(input.Nama), " "),
+		SKS:          input.SKS,
+		ProgramStudi: input.ProgramStudi,
+	}
+	// Kode milik mata kuliah yang sudah dihapus dipakai ulang
+	err := models.BuatAtauPulihkan(db, &matkul, "kode = ?", matkul.Kode)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kode mata kuliah sudah dipakai"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, matkul)
+}
+
+func DapatkanSemuaMataKuliah(c *gin.Context) {
+	db := database.GetDB()
+	query := db.Order("kode ASC")
+	if programStudi := c.Query("program_studi"); programStudi != "" {
+		query = query.Where("program_studi = ?", programStudi)
+	}
+	if cari := c.Query("cari"); cari != "" {
+		query = query.Where("nama LIKE ? OR kode LIKE ?", "%"+cari+"%", "%"+cari+"%")
+	}
+
+	var matkul []models.MataKuliah
+	result := query.Find(&matkul)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, matkul)
+}
+
+func DapatkanMataKuliah(c *gin.Context) {
+	id := c.Param("id")
+
+	var matkul models.MataKuliah
+	db := database.GetDB()
+	result := db.First(&matkul, "id = ?", id)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mata kuliah tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, matkul)
+}
+
+func UpdateMataKuliah(c *gin.Context) {
+	id := c.Param("id")
+
+	var input struct {
+		Kode         string `json:"kode"`
+		Nama         string `json:"nama"`
+		SKS          *int   `json:"sks"`
+		ProgramStudi string `json:"program_studi"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var matkul models.MataKuliah
+	db := database.GetDB()
+	if err := db.First(&matkul, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mata kuliah tidak ditemukan"})
+		return
+	}
+	if pesan := cekDuplikatMataKuliah(db, id, input.Kode, input.Nama); pesan != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": pesan})
+		return
+	}
+
+	updateData := make(map[string]interface{})
+	if input.Kode != "" {
+		updateData["kode"] = strings.ToUpper(strings.TrimSpace(input.Kode))
+	}
+	nama := ""
+	if input.Nama != "" {
+		nama = strings.Join(strings.Fields(input.Nama), " ")
+		updateData["nama"] = nama
+	}
+	if input.SKS != nil {
+		if *input.SKS < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "SKS tidak boleh negatif"})
+			return
+		}
+		updateData["sks"] = *input.SKS
+	}
+	if input.ProgramStudi != "" {
+		updateData["program_studi"] = input.ProgramStudi
+	}
+	if len(updateData) == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Mata kuliah berhasil diupdate"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.MataKuliah{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
+			return err
+		}
+		// Salinan nama di jadwal ikut diperbarui
+		if nama != "" {
+			return tx.Model(&models.Jadwal{}).Where("mata_kuliah_id = ?", id).Update("nama_matkul", nama).Error
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kode mata kuliah sudah dipakai"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Mata kuliah berhasil diupdate"})
+}
+
+func HapusMataKuliah(c *gin.Context) {
+	id := c.Param("id")
+
+	db := database.GetDB()
+	var dipakai int64
+	db.Model(&models.Jadwal{}).Where("mata_kuliah_id = ?", id).Count(&dipakai)
+	if dipakai > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mata kuliah masih dipakai oleh jadwal"})
+		return
+	}
+
+	result := db.Delete(&models.MataKuliah{}, "id = ?", id)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mata kuliah tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Mata kuliah berhasil dihapus"})
+}
+
+// Handler untuk laporan evaluasi per mata kuliah dan semester
+func GetStatistikMataKuliah(c *gin.Context) {
+	db := database.GetDB()
+
+	var stats []struct {
+		MataKuliahID  string  `json:"mata_kuliah_id"`
+		Kode          string  `json:"kode"`
+		NamaMatkul    string  `json:"nama_matkul"`
+		SemesterID    *string `json:"semester_id"`
+		NamaSemester  *string `json:"nama_semester"`
+		JumlahJadwal  int64   `json:"jumlah_jadwal"`
+		TotalEvaluasi int64   `json:"total_evaluasi"`
+		RataRataSkor  float64 `json:"rata_rata_skor"`
+	}
+
+	query := db.Table("evaluasis").
+		Select(`mata_kuliahs.id AS mata_kuliah_id, mata_kuliahs.kode, mata_kuliahs.nama AS nama_matkul,
+			semesters.id AS semester_id, semesters.nama AS nama_semester,
+			COUNT(DISTINCT jadwals.id) AS jumlah_jadwal, COUNT(evaluasis.id) AS total_evaluasi,
+			COALESCE(AVG(evaluasis.skor_efektivitas), 0) AS rata_rata_skor`).
+		Joins("JOIN jadwals ON jadwals.id = evaluasis.jadwal_id").
+		Joins("JOIN mata_kuliahs ON mata_kuliahs.id = jadwals.mata_kuliah_id").
+		Joins("LEFT JOIN semesters ON semesters.id = jadwals.semester_id").
+		Where("evaluasis.deleted_at IS NULL")
+
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("jadwals.semester_id = ?", semesterID)
+	}
+	if matkulID := c.Query("mata_kuliah_id"); matkulID != "" {
+		query = query.Where("jadwals.mata_kuliah_id = ?", matkulID)
+	}
+
+	result := query.Group("mata_kuliahs.id, mata_kuliahs.kode, mata_kuliahs.nama, semesters.id, semesters.nama").
+		Order("mata_kuliahs.kode ASC").
+		Scan(&stats)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// cekDuplikatMataKuliah mengembalikan pesan error jika kode atau nama
+// (setelah dinormalisasi) sudah dipakai mata kuliah lain
+func cekDuplikatMataKuliah(db *gorm.DB, id string, kode string, nama string) string {
+	lain := func() *gorm.DB {
+		query := db.Model(&models.MataKuliah{})
+		if id != "" {
+			query = query.Where("id <> ?", id)
+		}
+		return query
+	}
+
+	if kode = strings.ToUpper(strings.TrimSpace(kode)); kode != "" {
+		var ada int64
+		lain().Where("kode = ?", kode).Count(&ada)
+		if ada > 0 {
+			return "Kode mata kuliah sudah dipakai"
+		}
+	}
+	if namaNormal := models.NormalisasiNamaMatkul(nama); namaNormal != "" {
+		var m models.MataKuliah
+		if lain().Where("LOWER(nama) = ?", namaNormal).First(&m).Error == nil {
+			return "Mata kuliah dengan nama yang sama sudah ada: " + m.Kode
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"CLAIRE/models"
+)
+
+func TestBuatMataKuliahMemulihkanKodeTerhapus(t *testing.T) {
+	db := dbUji(t)
+	lama := models.MataKuliah{Kode: "IF101", Nama: "Algoritma"}
+	if err := db.Create(&lama).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&lama).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	body := map[string]interface{}{"kode": "if101", "nama": "Algoritma Lanjut", "sks": 3}
+	rec := kirim(t, http.MethodPost, "/mata-kuliah", "/mata-kuliah", body, BuatMataKuliah)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status HTTP = %d: %s", rec.Code, rec.Body)
+	}
+	var matkul models.MataKuliah
+	if err := json.Unmarshal(rec.Body.Bytes(), &matkul); err != nil {
+		t.Fatal(err)
+	}
+	if matkul.ID != lama.ID {
+		t.Errorf("ID = %s, want ID lama %s", matkul.ID, lama.ID)
+	}
+	if matkul.Nama != "Algoritma Lanjut" || matkul.SKS != 3 {
+		t.Errorf("mata kuliah = %+v, want isi baru", matkul)
+	}
+
+	var aktif models.MataKuliah
+	if err := db.First(&aktif, "id = ?", lama.ID).Error; err != nil {
+		t.Fatalf("mata kuliah tidak aktif kembali: %v", err)
+	}
+}
+
+func TestUpdateMataKuliahKodeTerhapusConflict(t *testing.T) {
+	db := dbUji(t)
+	terhapus := models.MataKuliah{Kode: "IF101", Nama: "Algoritma"}
+	matkul := models.MataKuliah{Kode: "IF102", Nama: "Basis Data"}
+	for _, m := range []*models.MataKuliah{&terhapus, &matkul} {
+		if err := db.Create(m).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Delete(&terhapus).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rec := kirim(t, http.MethodPut, "/mata-kuliah/:id", "/mata-kuliah/"+matkul.ID.String(), map[string]interface{}{"kode": "IF101"}, UpdateMataKuliah)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status HTTP = %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body)
+	}
+}
+
+func TestBuatMataKuliahNamaDuplikat(t *testing.T) {
+	db := dbUji(t)
+	if err := db.Create(&models.MataKuliah{Kode: "IF101", Nama: "Struktur Data"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	body := map[string]interface{}{"kode": "IF201", "nama": " struktur  DATA"}
+	rec := kirim(t, http.MethodPost, "/mata-kuliah", "/mata-kuliah", body, BuatMataKuliah)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status HTTP = %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body)
+	}
+}
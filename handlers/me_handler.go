@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"CLAIRE/auth"
+	"CLAIRE/database"
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dosenSaya mengembalikan data dosen yang terhubung ke akun yang login.
+// Jika akun belum terhubung, response 404 sudah dikirim.
+func dosenSaya(c *gin.Context) (*models.Dosen, bool) {
+	user := auth.Pengguna(c)
+	if user == nil || user.DosenID == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Akun belum terhubung ke data dosen"})
+		return nil, false
+	}
+
+	var dosen models.Dosen
+	if err := database.GetDB().Preload("Audio").First(&dosen, "id = ?", *user.DosenID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dosen tidak ditemukan"})
+		return nil, false
+	}
+	return &dosen, true
+}
+
+// Handler untuk profil akun yang sedang login
+func DapatkanProfilSaya(c *gin.Context) {
+	user := auth.Pengguna(c)
+
+	var profil models.User
+	if err := database.GetDB().Preload("Dosen").First(&profil, "id = ?", user.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pengguna tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": profil,
+		"izin": auth.IzinRole(profil.Role),
+	})
+}
+
+// Handler untuk jadwal mengajar dosen yang sedang login
+func DapatkanJadwalSaya(c *gin.Context) {
+	dosen, ok := dosenSaya(c)
+	if !ok {
+		return
+	}
+
+	query := database.GetDB().Preload("MataKuliah").Preload("Semester").Preload("DetailRuangan").
+		Where("dosen_id = ?", dosen.ID)
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("semester_id = ?", semesterID)
+	}
+	if hari := c.Query("hari"); hari != "" {
+		query = query.Where("hari = ?", hari)
+	}
+
+	var jadwal []models.Jadwal
+	if err := query.Order("waktu_mulai ASC").Find(&jadwal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	sort.SliceStable(jadwal, func(i, j int) bool {
+		return urutanHariJadwal(jadwal[i].Hari) < urutanHariJadwal(jadwal[j].Hari)
+	})
+
+	c.JSON(http.StatusOK, jadwal)
+}
+
+func urutanHariJadwal(hari string) int {
+	weekday, ok := kalender.Weekday(hari)
+	if !ok {
+		return 7
+	}
+	return kalender.UrutanHari(weekday)
+}
+
+// Handler untuk evaluasi (termasuk transkrip dan rangkuman) milik dosen yang
+// sedang login
+func DapatkanEvaluasiSaya(c *gin.Context) {
+	dosen, ok := dosenSaya(c)
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+	jadwalDosen := db.Model(&models.Jadwal{}).Select("id").Where("dosen_id = ?", dosen.ID)
+	query := db.Preload("Jadwal").Preload("Pertemuan").Preload("Audio").Where("jadwal_id IN (?)", jadwalDosen)
+	if jadwalID := c.Query("jadwal_id"); jadwalID != "" {
+		query = query.Where("jadwal_id = ?", jadwalID)
+	}
+	if dari := c.Query("dari"); dari != "" {
+		tanggal, err := kalender.ParseTanggal(dari)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format tanggal dari tidak valid"})
+			return
+		}
+		query = query.Where("tanggal_dibuat >= ?", tanggal)
+	}
+	if sampai := c.Query("sampai"); sampai != "" {
+		tanggal, err := kalender.ParseTanggal(sampai)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format tanggal sampai tidak valid"})
+			return
+		}
+		query = query.Where("tanggal_dibuat < ?", tanggal.AddDate(0, 0, 1))
+	}
+
+	var evaluasi []models.Evaluasi
+	if err := query.Order("tanggal_dibuat DESC").Find(&evaluasi).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, evaluasi)
+}
+
+type titikTren struct {
+	Tanggal              string  `json:"tanggal"`
+	JadwalID             string  `json:"jadwal_id"`
+	NamaMatkul           string  `json:"nama_matkul"`
+	PertemuanKe          int     `json:"pertemuan_ke,omitempty"`
+	SkorEfektivitas      float64 `json:"skor_efektivitas"`
+	KepercayaanPembicara float64 `json:"kepercayaan_pembicara"`
+}
+
+type ringkasanTren struct {
+	JadwalID            string  `json:"jadwal_id"`
+	NamaMatkul          string  `json:"nama_matkul"`
+	Jumlah              int     `json:"jumlah"`
+	RataRataSkor        float64 `json:"rata_rata_skor"`
+	RataRataKepercayaan float64 `json:"rata_rata_kepercayaan"`
+}
+
+// Handler untuk data grafik tren skor evaluasi dosen yang sedang login.
+// Titik diurutkan berdasarkan tanggal pertemuan (atau tanggal evaluasi jika
+// evaluasi tidak terhubung ke pertemuan).
+func DapatkanTrenEvaluasiSaya(c *gin.Context) {
+	dosen, ok := dosenSaya(c)
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+	jadwalDosen := db.Model(&models.Jadwal{}).Select("id").Where("dosen_id = ?", dosen.ID)
+	query := db.Preload("Jadwal").Preload("Pertemuan").Where("jadwal_id IN (?)", jadwalDosen)
+	if jadwalID := c.Query("jadwal_id"); jadwalID != "" {
+		query = query.Where("jadwal_id = ?", jadwalID)
+	}
+
+	var evaluasi []models.Evaluasi
+	if err := query.Find(&evaluasi).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	kal := kalender.Default()
+	titik := make([]titikTren, 0, len(evaluasi))
+	ringkasan := map[string]*ringkasanTren{}
+	urutan := []string{}
+	for _, e := range evaluasi {
+		t := titikTren{
+			Tanggal:              kal.Tanggal(e.TanggalDibuat).Format("2006-01-02"),
+			JadwalID:             e.JadwalID.String(),
+			NamaMatkul:           e.Jadwal.NamaMatkul,
+			SkorEfektivitas:      e.SkorEfektivitas,
+			KepercayaanPembicara: e.KepercayaanPembicara,
+		}
+		if e.Pertemuan != nil {
+			t.Tanggal = e.Pertemuan.Tanggal.Format("2006-01-02")
+			t.PertemuanKe = e.Pertemuan.PertemuanKe
+		}
+		titik = append(titik, t)
+
+		r, ada := ringkasan[t.JadwalID]
+		if !ada {
+			r = &ringkasanTren{JadwalID: t.JadwalID, NamaMatkul: t.NamaMatkul}
+			ringkasan[t.JadwalID] = r
+			urutan = append(urutan, t.JadwalID)
+		}
+		r.Jumlah++
+		r.RataRataSkor += e.SkorEfektivitas
+		r.RataRataKepercayaan += e.KepercayaanPembicara
+	}
+	sort.SliceStable(titik, func(i, j int) bool {
+		if titik[i].Tanggal != titik[j].Tanggal {
+			return titik[i].Tanggal < titik[j].Tanggal
+		}
+		return titik[i].PertemuanKe < titik[j].PertemuanKe
+	})
+
+	perJadwal := make([]ringkasanTren, 0, len(urutan))
+	for _, id := range urutan {
+		r := ringkasan[id]
+		r.RataRataSkor /= float64(r.Jumlah)
+		r.RataRataKepercayaan /= float64(r.Jumlah)
+		perJadwal = append(perJadwal, *r)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dosen_id":   dosen.ID,
+		"titik":      titik,
+		"per_jadwal": perJadwal,
+	})
+}
+
+// Handler untuk merekam ulang sampel suara dosen yang sedang login
+func RekamSuaraSaya(c *gin.Context) {
+	dosen, ok := dosenSaya(c)
+	if !ok {
+		return
+	}
+
+	c.Params = append(c.Params, gin.Param{Key: "id", Value: dosen.ID.String()})
+	RekamSuaraDosen(c)
+}
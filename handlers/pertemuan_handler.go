@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"CLAIRE/database"
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Batas rentang tanggal untuk generate pertemuan (satu tahun)
+const maxRentangPertemuan = 366 * 24 * time.Hour
+
+func GeneratePertemuan(c *gin.Context) {
+	id := c.Param("id")
+
+	var input struct {
+		TanggalMulai   string   `json:"tanggal_mulai" binding:"required"`
+		TanggalSelesai string   `json:"tanggal_selesai" binding:"required"`
+		Libur          []string `json:"libur"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mulai, errMulai := parseTanggal(input.TanggalMulai)
+	selesai, errSelesai := parseTanggal(input.TanggalSelesai)
+	if errMulai != nil || errSelesai != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format tanggal tidak valid. Gunakan format YYYY-MM-DD"})
+		return
+	}
+	if selesai.Before(mulai) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tanggal selesai harus setelah tanggal mulai"})
+		return
+	}
+	if selesai.Sub(mulai) > maxRentangPertemuan {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rentang tanggal maksimal satu tahun"})
+		return
+	}
+
+	var jadwal models.Jadwal
+	db := database.GetDB()
+	if err := db.First(&jadwal, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
+		return
+	}
+
+	weekday, ok := kalender.Weekday(jadwal.Hari)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hari jadwal tidak valid"})
+		return
+	}
+
+	// Kumpulkan tanggal libur dari tabel hari_liburs dan dari request
+	libur := make(map[string]bool)
+	var hariLibur []models.HariLibur
+	db.Where("tanggal BETWEEN ? AND ?", mulai.Format("2006-01-02"), selesai.Format("2006-01-02")).Find(&hariLibur)
+	for _, h := range hariLibur {
+		libur[h.Tanggal.Format("2006-01-02")] = true
+	}
+	for _, tanggal := range input.Libur {
+		t, err := parseTanggal(tanggal)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format tanggal libur tidak valid: " + tanggal})
+			return
+		}
+		libur[t.Format("2006-01-02")] = true
+	}
+
+	// Tanggal yang sudah punya pertemuan tidak dibuat ulang
+	var existing []models.Pertemuan
+	db.Where("jadwal_id = ?", jadwal.ID).Find(&existing)
+	sudahAda := make(map[string]bool)
+	for _, p := range existing {
+		sudahAda[p.TanggalAsli.Format("2006-01-02")] = true
+	}
+
+	var baru []models.Pertemuan
+	var dilewati []string
+	for tanggal := mulai; !tanggal.After(selesai); tanggal = tanggal.AddDate(0, 0, 1) {
+		if tanggal.Weekday() != weekday {
+			continue
+		}
+		key := tanggal.Format("2006-01-02")
+		if libur[key] {
+			dilewati = append(dilewati, key)
+			continue
+		}
+		if sudahAda[key] {
+			continue
+		}
+		baru = append(baru, models.Pertemuan{
+			JadwalID:     jadwal.ID,
+			Tanggal:      tanggal,
+			TanggalAsli:  tanggal,
+			WaktuMulai:   jadwal.WaktuMulai,
+			WaktuSelesai: jadwal.WaktuSelesai,
+			Ruangan:      jadwal.Ruangan,
+			Status:       models.PertemuanTerjadwal,
+		})
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if len(baru) > 0 {
+			if err := tx.Create(&baru).Error; err != nil {
+				return err
+			}
+		}
+		return nomoriPertemuan(tx, jadwal.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	notifyScheduler(jadwal.ID)
+
+	var pertemuan []models.Pertemuan
+	db.Where("jadwal_id = ?", jadwal.ID).Order("pertemuan_ke ASC").Find(&pertemuan)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":         "Pertemuan berhasil dibuat",
+		"jumlah_dibuat":   len(baru),
+		"tanggal_libur":   dilewati,
+		"total_pertemuan": len(pertemuan),
+		"pertemuan":       pertemuan,
+	})
+}
+
+// nomoriPertemuan mengurutkan ulang nomor pertemuan sebuah jadwal
+// berdasarkan tanggal aslinya
+func nomoriPertemuan(tx *gorm.DB, jadwalID interface{}) error {
+	var pertemuan []models.Pertemuan
+	if err := tx.Where("jadwal_id = ?", jadwalID).Find(&pertemuan).Error; err != nil {
+		return err
+	}
+
+	sort.Slice(pertemuan, func(i, j int) bool {
+		return pertemuan[i].TanggalAsli.Before(pertemuan[j].TanggalAsli)
+	})
+
+	for i, p := range pertemuan {
+		if p.PertemuanKe == i+1 {
+			continue
+		}
+		if err := tx.Model(&models.Pertemuan{}).Where("id = ?", p.ID).Update("pertemuan_ke", i+1).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func DapatkanSemuaPertemuan(c *gin.Context) {
+	db := database.GetDB()
+	query := db.Preload("Jadwal.Dosen").
+		Joins("JOIN jadwals ON jadwals.id = pertemuans.jadwal_id AND jadwals.deleted_at IS NULL")
+	query = lingkupKolomJadwal(c, query, "pertemuans.jadwal_id")
+
+	if jadwalID := c.Query("jadwal_id"); jadwalID != "" {
+		query = query.Where("pertemuans.jadwal_id = ?", jadwalID)
+	}
+	if namaMatkul := c.Query("nama_matkul"); namaMatkul != "" {
+		query = query.Where("jadwals.nama_matkul LIKE ?", "%"+namaMatkul+"%")
+	}
+	if ke := c.Query("pertemuan_ke"); ke != "" {
+		nomor, err := strconv.Atoi(ke)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "pertemuan_ke harus berupa angka"})
+			return
+		}
+		query = query.Where("pertemuans.pertemuan_ke = ?", nomor)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("pertemuans.status = ?", status)
+	}
+	if dari := c.Query("dari"); dari != "" {
+		query = query.Where("pertemuans.tanggal >= ?", dari)
+	}
+	if sampai := c.Query("sampai"); sampai != "" {
+		query = query.Where("pertemuans.tanggal <= ?", sampai)
+	}
+
+	var pertemuan []models.Pertemuan
+	result := query.Order("pertemuans.tanggal ASC, pertemuans.waktu_mulai ASC").Find(&pertemuan)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pertemuan)
+}
+
+func DapatkanPertemuanByJadwal(c *gin.Context) {
+	jadwalID := c.Param("id")
+
+	var pertemuan []models.Pertemuan
+	db := database.GetDB()
+	result := lingkupKolomJadwal(c, db, "jadwal_id").Where("jadwal_id = ?", jadwalID).Order("pertemuan_ke ASC").Find(&pertemuan)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pertemuan)
+}
+
+func DapatkanPertemuan(c *gin.Context) {
+	id := c.Param("id")
+
+	var pertemuan models.Pertemuan
+	db := database.GetDB()
+	result := lingkupKolomJadwal(c, db.Preload("Jadwal.Dosen"), "jadwal_id").First(&pertemuan, "id = ?", id)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pertemuan tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pertemuan)
+}
+
+// Handler untuk menjadwal ulang satu pertemuan tanpa mengubah jadwal mingguan
+func JadwalUlangPertemuan(c *gin.Context) {
+	id := c.Param("id")
+
+	var input struct {
+		Tanggal      string `json:"tanggal" binding:"required"`
+		WaktuMulai   string `json:"waktu_mulai"`
+		WaktuSelesai string `json:"waktu_selesai"`
+		Ruangan      string `json:"ruangan"`
+		Keterangan   string `json:"keterangan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var pertemuan models.Pertemuan
+	db := database.GetDB()
+	if err := db.First(&pertemuan, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pertemuan tidak ditemukan"})
+		return
+	}
+	if pertemuan.Status == models.PertemuanSelesai || pertemuan.Status == models.PertemuanDibatalkan {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pertemuan yang sudah selesai atau dibatalkan tidak bisa dijadwal ulang"})
+		return
+	}
+
+	tanggal, err := parseTanggal(input.Tanggal)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format tanggal tidak valid. Gunakan format YYYY-MM-DD"})
+		return
+	}
+
+	waktuMulai := pertemuan.WaktuMulai
+	if input.WaktuMulai != "" {
+		waktuMulai = input.WaktuMulai
+	}
+	waktuSelesai := pertemuan.WaktuSelesai
+	if input.WaktuSelesai != "" {
+		waktuSelesai = input.WaktuSelesai
+	}
+	if !isValidTime(waktuMulai) || !isValidTime(waktuSelesai) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format waktu tidak valid. Gunakan format HH:MM"})
+		return
+	}
+	if waktuSelesai <= waktuMulai {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Waktu selesai harus setelah waktu mulai"})
+		return
+	}
+
+	updateData := map[string]interface{}{
+		"tanggal":        tanggal,
+		"waktu_mulai":    waktuMulai,
+		"waktu_selesai":  waktuSelesai,
+		"dijadwal_ulang": true,
+	}
+	if input.Ruangan != "" {
+		updateData["ruangan"] = input.Ruangan
+	}
+	if input.Keterangan != "" {
+		updateData["keterangan"] = input.Keterangan
+	}
+
+	// Pertemuan yang dijadwal ulang kembali ke status terjadwal
+	err = models.UbahStatusPertemuan(db, pertemuan.ID, models.PertemuanTerjadwal, aktorRequest(c), "pertemuan dijadwal ulang ke "+input.Tanggal, updateData)
+	if err != nil {
+		responTransisiGagal(c, err, "Pertemuan tidak ditemukan")
+		return
+	}
+	notifyScheduler(pertemuan.JadwalID)
+
+	db.Preload("Jadwal.Dosen").First(&pertemuan, "id = ?", id)
+	c.JSON(http.StatusOK, pertemuan)
+}
+
+func UpdateStatusPertemuan(c *gin.Context) {
+	id := c.Param("id")
+
+	var input struct {
+		Status     string `json:"status" binding:"required"`
+		Keterangan string `json:"keterangan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validStatus := map[string]bool{
+		models.PertemuanTerjadwal:  true,
+		models.PertemuanAktif:      true,
+		models.PertemuanSelesai:    true,
+		models.PertemuanDibatalkan: true,
+	}
+	if !validStatus[input.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status tidak valid"})
+		return
+	}
+
+	var pertemuan models.Pertemuan
+	db := database.GetDB()
+	if err := db.First(&pertemuan, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pertemuan tidak ditemukan"})
+		return
+	}
+
+	var updateData map[string]interface{}
+	alasan := "status diubah manual"
+	if input.Keterangan != "" {
+		updateData = map[string]interface{}{"keterangan": input.Keterangan}
+		alasan = input.Keterangan
+	}
+
+	err := models.UbahStatusPertemuan(db, pertemuan.ID, input.Status, aktorRequest(c), alasan, updateData)
+	if err != nil {
+		responTransisiGagal(c, err, "Pertemuan tidak ditemukan")
+		return
+	}
+	notifyScheduler(pertemuan.JadwalID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Status pertemuan berhasil diupdate"})
+}
+
+func DapatkanEvaluasiByPertemuan(c *gin.Context) {
+	pertemuanID := c.Param("id")
+
+	var evaluasi []models.Evaluasi
+	db := database.GetDB()
+	result := lingkupEvaluasi(c, db.Preload("Jadwal.Dosen").Preload("Pertemuan").Preload("Audio")).
+		Where("pertemuan_id = ?", pertemuanID).
+		Order("tanggal_dibuat DESC").
+		Find(&evaluasi)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, evaluasi)
+}
+
+func BuatHariLibur(c *gin.Context) {
+	var input struct {
+		Tanggal    string `json:"tanggal" binding:"required"`
+		Keterangan string `json:"keterangan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tanggal, err := parseTanggal(input.Tanggal)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format tanggal tidak valid. Gunakan format YYYY-MM-DD"})
+		return
+	}
+
+	libur := models.HariLibur{Tanggal: tanggal, Keterangan: input.Keterangan}
+	db := database.GetDB()
+	err = db.Create(&libur).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tanggal tersebut sudah terdaftar sebagai hari libur"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	muatUlangScheduler()
+
+	c.JSON(http.StatusCreated, libur)
+}
+
+func DapatkanSemuaHariLibur(c *gin.Context) {
+	var libur []models.HariLibur
+	db := database.GetDB()
+	result := db.Order("tanggal ASC").Find(&libur)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, libur)
+}
+
+func HapusHariLibur(c *gin.Context) {
+	id := c.Param("id")
+
+	db := database.GetDB()
+	result := db.Delete(&models.HariLibur{}, "id = ?", id)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hari libur tidak ditemukan"})
+		return
+	}
+	muatUlangScheduler()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hari libur berhasil dihapus"})
+}
+
+var errFormatTanggal = errors.New("Format tanggal tidak valid. Gunakan format YYYY-MM-DD")
+
+// parseTanggal membaca tanggal kalender format YYYY-MM-DD
+func parseTanggal(value string) (time.Time, error) {
+	return kalender.ParseTanggal(value)
+}
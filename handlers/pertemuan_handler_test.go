@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBuatHariLiburTanggalDuplikat(t *testing.T) {
+	dbUji(t)
+	body := map[string]interface{}{"tanggal": "2025-12-25", "keterangan": "Natal"}
+	if rec := kirim(t, http.MethodPost, "/hari-libur", "/hari-libur", body, BuatHariLibur); rec.Code != http.StatusCreated {
+		t.Fatalf("status HTTP = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec := kirim(t, http.MethodPost, "/hari-libur", "/hari-libur", body, BuatHariLibur)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status HTTP = %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body)
+	}
+}
+
+func TestHapusHariLiburTidakAda(t *testing.T) {
+	dbUji(t)
+	rec := kirim(t, http.MethodDelete, "/hari-libur/:id", "/hari-libur/"+uuid.New().String(), nil, HapusHariLibur)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status HTTP = %d, want %d: %s", rec.Code, http.StatusNotFound, rec.Body)
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"CLAIRE/reconciler"
+
+	"github.com/gin-gonic/gin"
+)
+
+var statusReconciler *reconciler.Reconciler
+
+// SetReconciler memasang reconciler status jadwal untuk endpoint manual
+func SetReconciler(r *reconciler.Reconciler) {
+	statusReconciler = r
+}
+
+// Handler untuk menjalankan rekonsiliasi status secara manual
+func TriggerRekonsiliasiStatus(c *gin.Context) {
+	if statusReconciler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Rekonsiliasi status tidak aktif"})
+		return
+	}
+
+	hasil, err := statusReconciler.Reconcile(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Rekonsiliasi status selesai",
+		"jumlah_transisi": len(hasil.Transisi),
+		"hasil":           hasil,
+	})
+}
+
+// Handler untuk melihat hasil rekonsiliasi status terakhir
+func GetStatusRekonsiliasi(c *gin.Context) {
+	if statusReconciler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Rekonsiliasi status tidak aktif"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"terakhir": statusReconciler.Last(),
+	})
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"CLAIRE/config"
+	"CLAIRE/database"
+	"CLAIRE/models"
+	"CLAIRE/recorder"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ruanganInput struct {
+	Kode            string `json:"kode"`
+	Nama            string `json:"nama"`
+	Gedung          string `json:"gedung"`
+	Kapasitas       *int   `json:"kapasitas"`
+	RecorderBackend string `json:"recorder_backend"`
+	RecorderDevice  string `json:"recorder_device"`
+	AgentID         string `json:"agent_id"`
+	Aktif           *bool  `json:"aktif"`
+}
+
+func BuatRuangan(c *gin.Context) {
+	var input ruanganInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if models.NormalisasiKodeRuangan(input.Kode) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kode ruangan wajib diisi"})
+		return
+	}
+
+	ruangan := models.Ruangan{Aktif: true}
+	if pesan := terapkanRuanganInput(&ruangan, input); pesan != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": pesan})
+		return
+	}
+
+	db := database.GetDB()
+	var ada int64
+	db.Model(&models.Ruangan{}).Where("UPPER(kode) = ?", ruangan.Kode).Count(&ada)
+	if ada > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kode ruangan sudah dipakai"})
+		return
+	}
+
+	// Kode milik ruangan yang sudah dihapus dipakai ulang
+	err := models.BuatAtauPulihkan(db, &ruangan, "UPPER(kode) = ?", ruangan.Kode)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kode ruangan sudah dipakai"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ruangan)
+}
+
+func DapatkanSemuaRuangan(c *gin.Context) {
+	db := database.GetDB()
+	query := db.Order("kode ASC")
+	if gedung := c.Query("gedung"); gedung != "" {
+		query = query.Where("gedung = ?", gedung)
+	}
+
+	var ruangan []models.Ruangan
+	result := query.Find(&ruangan)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	isiStatusAgenRuangan(db, ruangan)
+
+	c.JSON(http.StatusOK, ruangan)
+}
+
+func DapatkanRuangan(c *gin.Context) {
+	id := c.Param("id")
+
+	var ruangan models.Ruangan
+	db := database.GetDB()
+	result := db.First(&ruangan, "id = ?", id)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ruangan tidak ditemukan"})
+		return
+	}
+	daftar := []models.Ruangan{ruangan}
+	isiStatusAgenRuangan(db, daftar)
+
+	c.JSON(http.StatusOK, daftar[0])
+}
+
+func UpdateRuangan(c *gin.Context) {
+	id := c.Param("id")
+
+	var input ruanganInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var ruangan models.Ruangan
+	db := database.GetDB()
+	if err := db.First(&ruangan, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ruangan tidak ditemukan"})
+		return
+	}
+	kodeLama := ruangan.Kode
+	if pesan := terapkanRuanganInput(&ruangan, input); pesan != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": pesan})
+		return
+	}
+
+	if ruangan.Kode != kodeLama {
+		var ada int64
+		db.Model(&models.Ruangan{}).Where("UPPER(kode) = ? AND id <> ?", ruangan.Kode, id).Count(&ada)
+		if ada > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Kode ruangan sudah dipakai"})
+			return
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Ruangan{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"kode":             ruangan.Kode,
+			"nama":             ruangan.Nama,
+			"gedung":           ruangan.Gedung,
+			"kapasitas":        ruangan.Kapasitas,
+			"recorder_backend": ruangan.RecorderBackend,
+			"recorder_device":  ruangan.RecorderDevice,
+			"agent_id":         ruangan.AgentID,
+			"aktif":            ruangan.Aktif,
+		}).Error
+		if err != nil {
+			return err
+		}
+		// Salinan kode ruangan di jadwal ikut diperbarui
+		if ruangan.Kode != kodeLama {
+			return tx.Model(&models.Jadwal{}).Where("ruangan_id = ?", id).Update("ruangan", ruangan.Kode).Error
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kode ruangan sudah dipakai"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ruangan berhasil diupdate"})
+}
+
+func HapusRuangan(c *gin.Context) {
+	id := c.Param("id")
+
+	db := database.GetDB()
+	var dipakai int64
+	db.Model(&models.Jadwal{}).Where("ruangan_id = ?", id).Count(&dipakai)
+	if dipakai > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ruangan masih dipakai oleh jadwal"})
+		return
+	}
+
+	result := db.Delete(&models.Ruangan{}, "id = ?", id)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ruangan tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ruangan berhasil dihapus"})
+}
+
+// DapatkanJadwalByRuangan menampilkan jadwal mingguan sebuah ruangan
+func DapatkanJadwalByRuangan(c *gin.Context) {
+	id := c.Param("id")
+
+	var jadwal []models.Jadwal
+	db := database.GetDB()
+	result := lingkupJadwal(c, db.Preload("Dosen")).Where("ruangan_id = ?", id).
+		Order("waktu_mulai ASC").Find(&jadwal)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	// Urutkan Senin sampai Minggu, bukan urutan abjad nama hari
+	sort.SliceStable(jadwal, func(i, j int) bool {
+		return urutanHariJadwal(jadwal[i].Hari) < urutanHariJadwal(jadwal[j].Hari)
+	})
+
+	c.JSON(http.StatusOK, jadwal)
+}
+
+// terapkanRuanganInput mengisi field ruangan yang dikirim lalu memvalidasi
+// perangkat perekamnya. Mengembalikan pesan error jika tidak valid.
+func terapkanRuanganInput(ruangan *models.Ruangan, input ruanganInput) string {
+	if kode := models.NormalisasiKodeRuangan(input.Kode); kode != "" {
+		ruangan.Kode = kode
+	}
+	if input.Nama != "" {
+		ruangan.Nama = input.Nama
+	}
+	if input.Gedung != "" {
+		ruangan.Gedung = input.Gedung
+	}
+	if input.Kapasitas != nil {
+		if *input.Kapasitas < 0 {
+			return "Kapasitas tidak boleh negatif"
+		}
+		ruangan.Kapasitas = *input.Kapasitas
+	}
+	if input.RecorderBackend != "" {
+		ruangan.RecorderBackend = input.RecorderBackend
+	}
+	if input.RecorderDevice != "" {
+		ruangan.RecorderDevice = input.RecorderDevice
+	}
+	if input.AgentID != "" {
+		var ada int64
+		database.GetDB().Model(&models.RecordingAgent{}).Where("id = ?", input.AgentID).Count(&ada)
+		if ada == 0 {
+			return "Agen perekam tidak ditemukan"
+		}
+		ruangan.AgentID = input.AgentID
+	}
+	if input.Aktif != nil {
+		ruangan.Aktif = *input.Aktif
+	}
+	if ruangan.Nama == "" {
+		ruangan.Nama = ruangan.Kode
+	}
+
+	if ruangan.RecorderBackend != "" {
+		if _, err := recorder.FromRegistry(config.LoadConfig(), ruangan.RecorderBackend, ruangan.RecorderDevice); err != nil {
+			return "Perangkat perekam tidak valid: " + err.Error()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"CLAIRE/models"
+)
+
+func TestDapatkanJadwalByRuanganUrutHari(t *testing.T) {
+	db := dbUji(t)
+	ruangan := models.Ruangan{Kode: "R101", Nama: "R101"}
+	if err := db.Create(&ruangan).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, j := range []struct{ hari, mulai string }{
+		{"SABTU", "08:00"}, {"KAMIS", "08:00"}, {"SENIN", "13:00"}, {"JUMAT", "08:00"}, {"SENIN", "08:00"}, {"RABU", "08:00"},
+	} {
+		jadwal := models.Jadwal{NamaMatkul: "Algoritma", Hari: j.hari, WaktuMulai: j.mulai, WaktuSelesai: "23:00", RuanganID: &ruangan.ID, Ruangan: ruangan.Kode}
+		if err := db.Create(&jadwal).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := kirim(t, http.MethodGet, "/ruangan/:id/jadwal", "/ruangan/"+ruangan.ID.String()+"/jadwal", nil, DapatkanJadwalByRuangan)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status HTTP = %d: %s", rec.Code, rec.Body)
+	}
+	var jadwal []models.Jadwal
+	if err := json.Unmarshal(rec.Body.Bytes(), &jadwal); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"SENIN 08:00", "SENIN 13:00", "RABU 08:00", "KAMIS 08:00", "JUMAT 08:00", "SABTU 08:00"}
+	if len(jadwal) != len(want) {
+		t.Fatalf("jumlah jadwal = %d, want %d", len(jadwal), len(want))
+	}
+	for i, j := range jadwal {
+		if got := j.Hari + " " + j.WaktuMulai; got != want[i] {
+			t.Errorf("jadwal[%d] = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestBuatRuanganMemulihkanKodeTerhapus(t *testing.T) {
+	db := dbUji(t)
+	lama := models.Ruangan{Kode: "R101", Nama: "Lama", Aktif: true}
+	if err := db.Create(&lama).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&lama).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	body := map[string]interface{}{"kode": "r101", "nama": "Lab Komputer", "aktif": false}
+	rec := kirim(t, http.MethodPost, "/ruangan", "/ruangan", body, BuatRuangan)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status HTTP = %d: %s", rec.Code, rec.Body)
+	}
+
+	var ruangan models.Ruangan
+	if err := db.First(&ruangan, "id = ?", lama.ID).Error; err != nil {
+		t.Fatalf("ruangan tidak aktif kembali: %v", err)
+	}
+	if ruangan.Nama != "Lab Komputer" || ruangan.Aktif {
+		t.Errorf("ruangan = %+v, want isi baru", ruangan)
+	}
+
+	// Jadwal yang membuat ruangan otomatis juga memakai baris yang sama
+	if err := db.Delete(&ruangan).Error; err != nil {
+		t.Fatal(err)
+	}
+	otomatis, err := models.CariAtauBuatRuangan(db, "R101")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otomatis.ID != lama.ID {
+		t.Errorf("ID = %s, want ID lama %s", otomatis.ID, lama.ID)
+	}
+}
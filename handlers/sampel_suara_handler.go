@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"CLAIRE/audio"
+	"CLAIRE/models"
+	"CLAIRE/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Syarat kualitas sampel suara pendaftaran dosen
+var syaratSampelSuara = audio.DefaultSyaratKualitas()
+
+// SetSyaratSampelSuara mengatur syarat kualitas sampel suara dosen
+func SetSyaratSampelSuara(syarat audio.SyaratKualitas) {
+	syaratSampelSuara = syarat
+}
+
+// simpanSampelSuara memeriksa kualitas sampel suara lalu menyimpannya ke
+// folder dosen. Sampel yang tidak lolos tidak disimpan dan dikembalikan
+// sebagai *audio.ErrKualitasSampel.
+func simpanSampelSuara(c *gin.Context, src io.Reader, size int64, namaFile string, dosenFolder string) (*models.AudioFile, error) {
+	ctx := c.Request.Context()
+	siap, err := utils.SiapkanAudio(ctx, src, size, namaFile)
+	if err != nil {
+		return nil, err
+	}
+	defer siap.Hapus()
+
+	if laporan := siap.Kualitas(syaratSampelSuara); !laporan.Lolos {
+		return nil, &audio.ErrKualitasSampel{Laporan: laporan}
+	}
+	return siap.Simpan(ctx, blobStore, AudioUploadDirdosen, dosenFolder)
+}
+
+// simpanFileSampelSuara seperti simpanSampelSuara untuk file dari form
+func simpanFileSampelSuara(c *gin.Context, file *multipart.FileHeader, dosenFolder string) (*models.AudioFile, error) {
+	// Validasi ekstensi sebelum membuka file
+	if err := utils.ValidateAudioExtension(file.Filename); err != nil {
+		return nil, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuka file: %v", err)
+	}
+	defer src.Close()
+
+	return simpanSampelSuara(c, src, file.Size, file.Filename, dosenFolder)
+}
+
+// kirimGagalSampelSuara mengirim error penyimpanan sampel suara. Sampel yang
+// tidak memenuhi syarat kualitas disertai laporan setiap cek agar pengguna
+// tahu apa yang harus diperbaiki.
+func kirimGagalSampelSuara(c *gin.Context, err error) {
+	var errKualitas *audio.ErrKualitasSampel
+	if errors.As(err, &errKualitas) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":    "Sampel suara tidak memenuhi syarat kualitas",
+			"kualitas": errKualitas.Laporan,
+		})
+		return
+	}
+	c.JSON(statusSimpanAudio(err), gin.H{"error": fmt.Sprintf("Gagal menyimpan file audio: %v", err)})
+}
+
+// Handler untuk memeriksa kualitas sampel suara tanpa menyimpannya. Dipakai
+// form rekam suara untuk menampilkan laporan sebelum sampel dikirim.
+func CekSampelSuara(c *gin.Context) {
+	file, err := c.FormFile("audio_data")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data audio wajib diupload"})
+		return
+	}
+
+	// Validasi size file
+	if err := utils.CheckAudioFileSize(file, MaxUploadSizedosen); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := utils.ValidateAudioExtension(file.Filename); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Gagal membuka file: %v", err)})
+		return
+	}
+	defer src.Close()
+
+	siap, err := utils.SiapkanAudio(c.Request.Context(), src, file.Size, file.Filename)
+	if err != nil {
+		c.JSON(statusSimpanAudio(err), gin.H{"error": fmt.Sprintf("Gagal membaca file audio: %v", err)})
+		return
+	}
+	defer siap.Hapus()
+
+	c.JSON(http.StatusOK, gin.H{
+		"kualitas": siap.Kualitas(syaratSampelSuara),
+		"audio":    siap.File,
+		"syarat":   syaratSampelSuara,
+	})
+}
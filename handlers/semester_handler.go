@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errRentangSemester = errors.New("Tanggal selesai harus setelah tanggal mulai")
+
+type semesterInput struct {
+	Nama           string `json:"nama"`
+	TanggalMulai   string `json:"tanggal_mulai"`
+	TanggalSelesai string `json:"tanggal_selesai"`
+	Aktif          *bool  `json:"aktif"`
+}
+
+func BuatSemester(c *gin.Context) {
+	var input semesterInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Nama == "" || input.TanggalMulai == "" || input.TanggalSelesai == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nama, tanggal_mulai dan tanggal_selesai wajib diisi"})
+		return
+	}
+
+	var semester models.Semester
+	if err := terapkanSemesterInput(&semester, input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if semester.Aktif {
+			if err := nonaktifkanSemester(tx); err != nil {
+				return err
+			}
+		}
+		// Nama milik semester yang sudah dihapus dipakai ulang
+		return models.BuatAtauPulihkan(tx, &semester, "nama = ?", semester.Nama)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Nama semester sudah dipakai"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, semester)
+}
+
+func DapatkanSemuaSemester(c *gin.Context) {
+	var semester []models.Semester
+	db := database.GetDB()
+	result := db.Order("tanggal_mulai DESC").Find(&semester)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, semester)
+}
+
+func DapatkanSemester(c *gin.Context) {
+	id := c.Param("id")
+
+	var semester models.Semester
+	db := database.GetDB()
+	result := db.First(&semester, "id = ?", id)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Semester tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, semester)
+}
+
+func UpdateSemester(c *gin.Context) {
+	id := c.Param("id")
+
+	var input semesterInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var semester models.Semester
+	db := database.GetDB()
+	if err := db.First(&semester, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Semester tidak ditemukan"})
+		return
+	}
+	if err := terapkanSemesterInput(&semester, input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if semester.Aktif {
+			if err := nonaktifkanSemester(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Semester{}).Where("id = ?", semester.ID).
+			Updates(map[string]interface{}{
+				"nama":            semester.Nama,
+				"tanggal_mulai":   semester.TanggalMulai,
+				"tanggal_selesai": semester.TanggalSelesai,
+				"aktif":           semester.Aktif,
+			}).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Nama semester sudah dipakai"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Semester berhasil diupdate"})
+}
+
+func HapusSemester(c *gin.Context) {
+	id := c.Param("id")
+
+	db := database.GetDB()
+	var dipakai int64
+	db.Model(&models.Jadwal{}).Where("semester_id = ?", id).Count(&dipakai)
+	if dipakai > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Semester masih dipakai oleh jadwal"})
+		return
+	}
+
+	result := db.Delete(&models.Semester{}, "id = ?", id)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Semester tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Semester berhasil dihapus"})
+}
+
+// terapkanSemesterInput mengisi field semester yang dikirim dan memvalidasi
+// rentang tanggalnya
+func terapkanSemesterInput(semester *models.Semester, input semesterInput) error {
+	if input.Nama != "" {
+		semester.Nama = input.Nama
+	}
+	if input.TanggalMulai != "" {
+		t, err := parseTanggal(input.TanggalMulai)
+		if err != nil {
+			return errFormatTanggal
+		}
+		semester.TanggalMulai = t
+	}
+	if input.TanggalSelesai != "" {
+		t, err := parseTanggal(input.TanggalSelesai)
+		if err != nil {
+			return errFormatTanggal
+		}
+		semester.TanggalSelesai = t
+	}
+	if input.Aktif != nil {
+		semester.Aktif = *input.Aktif
+	}
+	if !semester.TanggalSelesai.After(semester.TanggalMulai) {
+		return errRentangSemester
+	}
+	return nil
+}
+
+// nonaktifkanSemester menonaktifkan semua semester sebelum satu semester
+// diaktifkan
+func nonaktifkanSemester(tx *gorm.DB) error {
+	return tx.Model(&models.Semester{}).Where("aktif = ?", true).Update("aktif", false).Error
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"CLAIRE/models"
+)
+
+func TestBuatSemesterMemulihkanNamaTerhapus(t *testing.T) {
+	db := dbUji(t)
+	lama := models.Semester{
+		Nama:           "Ganjil 2025/2026",
+		TanggalMulai:   time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+		TanggalSelesai: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	if err := db.Create(&lama).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&lama).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	body := map[string]interface{}{
+		"nama":            "Ganjil 2025/2026",
+		"tanggal_mulai":   "2025-08-25",
+		"tanggal_selesai": "2026-01-31",
+	}
+	rec := kirim(t, http.MethodPost, "/semester", "/semester", body, BuatSemester)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status HTTP = %d: %s", rec.Code, rec.Body)
+	}
+	var semester models.Semester
+	if err := json.Unmarshal(rec.Body.Bytes(), &semester); err != nil {
+		t.Fatal(err)
+	}
+	if semester.ID != lama.ID {
+		t.Errorf("ID = %s, want ID lama %s", semester.ID, lama.ID)
+	}
+	if got := semester.TanggalMulai.Format("2006-01-02"); got != "2025-08-25" {
+		t.Errorf("tanggal_mulai = %s, want 2025-08-25", got)
+	}
+}
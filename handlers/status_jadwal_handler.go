@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// responTransisiGagal mengirim error dari UbahStatusJadwal/UbahStatusPertemuan
+// dengan status HTTP yang sesuai
+func responTransisiGagal(c *gin.Context, err error, pesanTidakDitemukan string) {
+	var transisi *models.TransisiError
+	switch {
+	case errors.As(err, &transisi):
+		diizinkan := models.TransisiJadwalDiizinkan(transisi.Dari)
+		if transisi.Jenis == models.LogJenisPertemuan {
+			diizinkan = models.TransisiPertemuanDiizinkan(transisi.Dari)
+		}
+		c.JSON(http.StatusConflict, gin.H{
+			"error":              err.Error(),
+			"status_sekarang":    transisi.Dari,
+			"status_diminta":     transisi.Ke,
+			"transisi_diizinkan": diizinkan,
+		})
+	case errors.Is(err, models.ErrStatusBerubah):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": pesanTidakDitemukan})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Handler untuk riwayat perubahan status jadwal beserta pertemuannya
+func DapatkanRiwayatStatusJadwal(c *gin.Context) {
+	id := c.Param("id")
+
+	db := database.GetDB()
+	var jadwal models.Jadwal
+	if err := lingkupJadwal(c, db.Unscoped().Select("id")).First(&jadwal, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
+		return
+	}
+
+	query := db.Where("jadwal_id = ?", jadwal.ID)
+	if jenis := c.Query("jenis"); jenis != "" {
+		query = query.Where("jenis = ?", jenis)
+	}
+	if pertemuanID := c.Query("pertemuan_id"); pertemuanID != "" {
+		query = query.Where("pertemuan_id = ?", pertemuanID)
+	}
+
+	limit := 100
+	if value := c.Query("limit"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	var riwayat []models.JadwalStatusLog
+	result := query.Order("tanggal_dibuat DESC").Limit(limit).Find(&riwayat)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, riwayat)
+}
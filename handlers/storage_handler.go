@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+
+	"CLAIRE/audio"
+	"CLAIRE/database"
+	"CLAIRE/models"
+	"CLAIRE/storage"
+	"CLAIRE/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Penyimpanan file audio. Default-nya direktori kerja aplikasi agar path
+// lama (sampel_suara/..., uploads/audio_evaluasi/...) tetap terbaca.
+var (
+	blobStore       storage.BlobStore = storage.NewLocal("")
+	presignAudioTTL time.Duration
+)
+
+// SetBlobStore memasang penyimpanan file audio. presignTTL > 0 membuat
+// endpoint audio mengarahkan klien ke URL presigned jika store mendukungnya;
+// 0 berarti audio selalu di-stream lewat API.
+func SetBlobStore(store storage.BlobStore, presignTTL time.Duration) {
+	blobStore = store
+	presignAudioTTL = presignTTL
+}
+
+// kirimAudio mengirim file audio dari store: redirect ke URL presigned jika
+// aktif, selain itu di-stream. File dari store lokal dilayani dengan
+// dukungan Range agar audio bisa di-seek di browser.
+func kirimAudio(c *gin.Context, key string) {
+	ctx := c.Request.Context()
+
+	if presignAudioTTL > 0 {
+		url, err := blobStore.PresignGet(ctx, key, presignAudioTTL)
+		if err == nil {
+			c.Redirect(http.StatusTemporaryRedirect, url)
+			return
+		}
+		if !errors.Is(err, storage.ErrPresignTidakDidukung) {
+			log.Printf("Gagal membuat URL presigned %s: %v", key, err)
+		}
+	}
+
+	rc, info, err := blobStore.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrKeyTidakValid) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File audio tidak ditemukan"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rc.Close()
+
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	if seeker, ok := rc.(io.ReadSeeker); ok {
+		c.Header("Content-Type", contentType)
+		http.ServeContent(c.Writer, c.Request, path.Base(key), info.ModTime, seeker)
+		return
+	}
+	c.DataFromReader(http.StatusOK, info.Size, contentType, rc, nil)
+}
+
+// statusSimpanAudio memilih status HTTP untuk error penyimpanan audio. Isi
+// file yang bukan audio adalah kesalahan klien, bukan server.
+func statusSimpanAudio(err error) int {
+	if errors.Is(err, audio.ErrBukanAudio) {
+		return http.StatusBadRequest
+	}
+	var errKualitas *audio.ErrKualitasSampel
+	if errors.As(err, &errKualitas) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// catatMetadataAudio menyimpan metadata file audio yang baru dilampirkan
+func catatMetadataAudio(file *models.AudioFile) {
+	if err := models.SimpanAudioFile(database.GetDB(), file); err != nil {
+		log.Printf("Gagal menyimpan metadata audio %s: %v", file.Path, err)
+	}
+}
+
+// hapusFileAudio menghapus file audio dari store beserta metadatanya
+func hapusFileAudio(c *gin.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := utils.DeleteAudioFile(c.Request.Context(), blobStore, key); err != nil {
+		log.Printf("Gagal menghapus file audio %s: %v", key, err)
+	}
+	models.HapusAudioFile(database.GetDB(), key)
+}
@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"CLAIRE/audio"
+	"CLAIRE/auth"
+	"CLAIRE/database"
+	"CLAIRE/models"
+	"CLAIRE/storage"
+	"CLAIRE/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Upload bertahap: klien membuat sesi upload, mengirim potongan byte dengan
+// PUT + Content-Range, menanyakan offset dengan HEAD jika koneksi putus, lalu
+// menutup sesi. File dipindah ke penyimpanan evaluasi/dosen hanya jika
+// checksum SHA-256 cocok. Potongan disimpan di storage dan offset dicatat di
+// database sehingga potongan boleh diterima replika API mana pun.
+const (
+	UploadSesiDir     = "uploads/sesi"
+	UploadSesiTTL     = 24 * time.Hour
+	MaxUploadBertahap = 2 << 30 // 2GB, cukup untuk rekaman kuliah penuh
+	batasChunkUpload  = 32 << 20
+)
+
+var formatChecksum = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// batasUkuranUpload mengembalikan ukuran file maksimal per tujuan. Sampel
+// suara dosen tetap memakai batas upload langsung.
+func batasUkuranUpload(tujuan string) int64 {
+	if tujuan == models.UploadTujuanDosen {
+		return MaxUploadSizedosen
+	}
+	return MaxUploadBertahap
+}
+
+// bersihkanUploadKedaluwarsa menandai sesi aktif yang sudah lewat batas
+// waktu sebagai gagal dan menghapus potongannya
+func bersihkanUploadKedaluwarsa(ctx context.Context) {
+	db := database.GetDB()
+	var daftar []models.UploadSesi
+	if err := db.Where("status = ? AND kedaluwarsa_pada < ?", models.UploadStatusAktif, time.Now()).Find(&daftar).Error; err != nil {
+		return
+	}
+	for _, sesi := range daftar {
+		result := db.Model(&models.UploadSesi{}).Where("id = ? AND status = ?", sesi.ID, models.UploadStatusAktif).Updates(map[string]interface{}{
+			"status":      models.UploadStatusGagal,
+			"pesan_error": "sesi upload kedaluwarsa",
+		})
+		if result.RowsAffected > 0 {
+			blobStore.DeletePrefix(ctx, sesi.PrefixPotongan)
+		}
+	}
+}
+
+// sesiUploadRequest memuat sesi upload dari parameter :id dan memastikan
+// sesi milik pemanggil. Menulis response error dan mengembalikan false jika
+// tidak valid.
+func sesiUploadRequest(c *gin.Context) (*models.UploadSesi, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format ID upload tidak valid"})
+		return nil, false
+	}
+
+	var sesi models.UploadSesi
+	if err := database.GetDB().First(&sesi, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sesi upload tidak ditemukan"})
+		return nil, false
+	}
+	// Sesi upload hanya bisa dilanjutkan oleh pembuatnya
+	if sesi.DibuatOleh != aktorRequest(c) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sesi upload tidak ditemukan"})
+		return nil, false
+	}
+	return &sesi, true
+}
+
+// sesiUploadAktif memastikan sesi masih menerima data
+func sesiUploadAktif(c *gin.Context, sesi *models.UploadSesi) bool {
+	if sesi.Status != models.UploadStatusAktif {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Sesi upload sudah berstatus %s", sesi.Status)})
+		return false
+	}
+	if time.Now().After(sesi.KedaluwarsaPada) {
+		c.JSON(http.StatusGone, gin.H{"error": "Sesi upload kedaluwarsa, buat sesi baru"})
+		return false
+	}
+	return true
+}
+
+// Handler untuk membuat sesi upload audio bertahap ke evaluasi atau sampel
+// suara dosen
+func BuatUploadSesi(c *gin.Context) {
+	var input struct {
+		Tujuan   string `json:"tujuan" binding:"required"`
+		TargetID string `json:"target_id" binding:"required"`
+		NamaFile string `json:"nama_file" binding:"required"`
+		Ukuran   int64  `json:"ukuran" binding:"required"`
+		Checksum string `json:"checksum" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := uuid.Parse(input.TargetID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format target_id tidak valid"})
+		return
+	}
+	if err := utils.ValidateAudioExtension(input.NamaFile); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	checksum := strings.ToLower(strings.TrimSpace(input.Checksum))
+	if !formatChecksum.MatchString(checksum) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Checksum harus berupa SHA-256 heksadesimal (64 karakter)"})
+		return
+	}
+
+	user := auth.Pengguna(c)
+	db := database.GetDB()
+	switch input.Tujuan {
+	case models.UploadTujuanEvaluasi:
+		if user == nil || !auth.Punya(user.Role, auth.IzinKelolaEvaluasi) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak"})
+			return
+		}
+		var jumlah int64
+		db.Model(&models.Evaluasi{}).Where("id = ?", input.TargetID).Count(&jumlah)
+		if jumlah == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Evaluasi tidak ditemukan"})
+			return
+		}
+	case models.UploadTujuanDosen:
+		// Akun dosen hanya boleh mengunggah sampel suaranya sendiri
+		if !bolehAksesDosen(c, input.TargetID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak"})
+			return
+		}
+		var jumlah int64
+		db.Model(&models.Dosen{}).Where("id = ?", input.TargetID).Count(&jumlah)
+		if jumlah == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dosen tidak ditemukan"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tujuan tidak valid. Gunakan: evaluasi, dosen"})
+		return
+	}
+
+	batas := batasUkuranUpload(input.Tujuan)
+	if input.Ukuran <= 0 || input.Ukuran > batas {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("ukuran file melebihi batas maksimal %dMB", batas/(1024*1024))})
+		return
+	}
+
+	bersihkanUploadKedaluwarsa(c.Request.Context())
+
+	sesi := models.UploadSesi{
+		Tujuan:          input.Tujuan,
+		TargetID:        input.TargetID,
+		NamaFile:        path.Base(strings.ReplaceAll(input.NamaFile, "\\", "/")),
+		UkuranTotal:     input.Ukuran,
+		Checksum:        checksum,
+		Status:          models.UploadStatusAktif,
+		PrefixPotongan:  storage.Key(UploadSesiDir, uuid.New().String()),
+		DibuatOleh:      aktorRequest(c),
+		KedaluwarsaPada: time.Now().Add(UploadSesiTTL),
+	}
+	if err := db.Create(&sesi).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", "/api/v1/uploads/"+sesi.ID.String())
+	c.JSON(http.StatusCreated, gin.H{
+		"upload":      sesi,
+		"offset":      0,
+		"batas_chunk": batasChunkUpload,
+	})
+}
+
+// Handler untuk melihat sesi upload beserta jumlah byte yang sudah diterima
+func DapatkanUploadSesi(c *gin.Context) {
+	sesi, ok := sesiUploadRequest(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"upload": sesi,
+		"offset": sesi.Diterima,
+	})
+}
+
+// Handler HEAD untuk melanjutkan upload: offset berikutnya ada di header
+// Upload-Offset. Offset hanya mencakup potongan yang sudah tersimpan.
+func OffsetUploadSesi(c *gin.Context) {
+	sesi, ok := sesiUploadRequest(c)
+	if !ok {
+		return
+	}
+	c.Header("Upload-Offset", strconv.FormatInt(sesi.Diterima, 10))
+	c.Header("Upload-Length", strconv.FormatInt(sesi.UkuranTotal, 10))
+	c.Header("Upload-Complete", strconv.FormatBool(sesi.Lengkap()))
+	c.Status(http.StatusOK)
+}
+
+// Handler untuk mengirim satu potongan file. Header Content-Range
+// "bytes <awal>-<akhir>/<total>" wajib ada dan awal harus sama dengan offset
+// yang sudah diterima server.
+func UploadChunkSesi(c *gin.Context) {
+	sesi, ok := sesiUploadRequest(c)
+	if !ok {
+		return
+	}
+
+	awal, akhir, total, err := parseContentRange(c.GetHeader("Content-Range"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if total != sesi.UkuranTotal {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Total Content-Range harus %d sesuai ukuran sesi upload", sesi.UkuranTotal)})
+		return
+	}
+	if akhir-awal+1 > batasChunkUpload {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Ukuran chunk maksimal %d byte", batasChunkUpload)})
+		return
+	}
+
+	if !sesiUploadAktif(c, sesi) {
+		return
+	}
+	if awal != sesi.Diterima {
+		konflikOffsetUpload(c, sesi)
+		return
+	}
+
+	// Potongan ditulis dulu, offset baru dimajukan setelah potongan
+	// tersimpan. Request lain untuk offset yang sama (misalnya klien yang
+	// tersambung ulang sebelum server sadar koneksi lamanya putus) menimpa
+	// potongan dengan isi yang sama; update bersyarat memastikan hanya satu
+	// yang memajukan offset, walaupun datang ke replika berbeda.
+	db := database.GetDB()
+	ukuran := akhir - awal + 1
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, ukuran)
+	if err := storage.TulisPotongan(c.Request.Context(), blobStore, sesi.PrefixPotongan, awal, body, ukuran); err != nil {
+		// Potongan tidak lengkap dibuang; klien melanjutkan dari offset
+		// yang sudah tersimpan
+		db.First(sesi, "id = ?", sesi.ID)
+		c.Header("Upload-Offset", strconv.FormatInt(sesi.Diterima, 10))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Gagal menyimpan potongan file: %v", err), "offset": sesi.Diterima})
+		return
+	}
+
+	result := db.Model(&models.UploadSesi{}).
+		Where("id = ? AND status = ? AND diterima = ?", sesi.ID, models.UploadStatusAktif, awal).
+		Update("diterima", akhir+1)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		db.First(sesi, "id = ?", sesi.ID)
+		if !sesiUploadAktif(c, sesi) {
+			return
+		}
+		konflikOffsetUpload(c, sesi)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(akhir+1, 10))
+	c.JSON(http.StatusOK, gin.H{
+		"offset":  akhir + 1,
+		"lengkap": akhir+1 == sesi.UkuranTotal,
+	})
+}
+
+// konflikOffsetUpload menolak potongan yang tidak dimulai di offset yang
+// sudah diterima server
+func konflikOffsetUpload(c *gin.Context, sesi *models.UploadSesi) {
+	c.Header("Upload-Offset", strconv.FormatInt(sesi.Diterima, 10))
+	c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Offset upload tidak sesuai, lanjutkan dari byte %d", sesi.Diterima), "offset": sesi.Diterima})
+}
+
+// Handler untuk menutup sesi upload: checksum diverifikasi lalu file
+// dilampirkan ke evaluasi atau dosen tujuan. Panggilan ulang setelah berhasil
+// mengembalikan hasil yang sama.
+func SelesaikanUploadSesi(c *gin.Context) {
+	sesi, ok := sesiUploadRequest(c)
+	if !ok {
+		return
+	}
+
+	if sesi.Status == models.UploadStatusSelesai {
+		c.JSON(http.StatusOK, gin.H{"message": "File audio berhasil disimpan", "path": sesi.PathHasil, "upload": sesi})
+		return
+	}
+	if !sesiUploadAktif(c, sesi) {
+		return
+	}
+	if !sesi.Lengkap() {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Upload belum lengkap: %d dari %d byte diterima", sesi.Diterima, sesi.UkuranTotal), "offset": sesi.Diterima})
+		return
+	}
+
+	// Ambil alih sesi agar penutupan ganda tidak melampirkan file dua kali
+	db := database.GetDB()
+	result := db.Model(&models.UploadSesi{}).
+		Where("id = ? AND status = ? AND diterima = ukuran_total", sesi.ID, models.UploadStatusAktif).
+		Update("status", models.UploadStatusDiproses)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Sesi upload sedang diproses"})
+		return
+	}
+	kembalikan := func() {
+		db.Model(&models.UploadSesi{}).Where("id = ?", sesi.ID).Update("status", models.UploadStatusAktif)
+	}
+
+	ctx := c.Request.Context()
+	checksum, err := checksumPotongan(ctx, sesi)
+	if err != nil {
+		kembalikan()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if checksum != sesi.Checksum {
+		// Isi file tidak bisa dipercaya; klien harus mengunggah ulang dari awal
+		blobStore.DeletePrefix(ctx, sesi.PrefixPotongan)
+		db.Model(&models.UploadSesi{}).Where("id = ?", sesi.ID).Updates(map[string]interface{}{
+			"status":      models.UploadStatusGagal,
+			"diterima":    0,
+			"pesan_error": "checksum tidak cocok: " + checksum,
+		})
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Checksum file tidak cocok, upload ulang dengan sesi baru", "checksum": checksum})
+		return
+	}
+
+	pathHasil, status, err := lampirkanUpload(c, sesi)
+	var errKualitas *audio.ErrKualitasSampel
+	if errors.Is(err, audio.ErrBukanAudio) || errors.As(err, &errKualitas) {
+		// Isi file bukan audio atau sampel suara tidak memenuhi syarat
+		// kualitas; mengulang penutupan tidak akan berhasil
+		blobStore.DeletePrefix(ctx, sesi.PrefixPotongan)
+		db.Model(&models.UploadSesi{}).Where("id = ?", sesi.ID).Updates(map[string]interface{}{
+			"status":      models.UploadStatusGagal,
+			"diterima":    0,
+			"pesan_error": err.Error(),
+		})
+		if errKualitas != nil {
+			kirimGagalSampelSuara(c, errKualitas)
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		kembalikan()
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	blobStore.DeletePrefix(ctx, sesi.PrefixPotongan)
+	db.Model(&models.UploadSesi{}).Where("id = ?", sesi.ID).Updates(map[string]interface{}{
+		"status":     models.UploadStatusSelesai,
+		"path_hasil": pathHasil,
+	})
+	sesi.Status = models.UploadStatusSelesai
+	sesi.PathHasil = pathHasil
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "File audio berhasil disimpan",
+		"path":    pathHasil,
+		"upload":  sesi,
+	})
+}
+
+// lampirkanUpload menggabungkan potongan sesi upload ke penyimpanan tujuan
+func lampirkanUpload(c *gin.Context, sesi *models.UploadSesi) (string, int, error) {
+	src := storage.BacaPotongan(c.Request.Context(), blobStore, sesi.PrefixPotongan, sesi.UkuranTotal)
+	defer src.Close()
+
+	db := database.GetDB()
+	switch sesi.Tujuan {
+	case models.UploadTujuanEvaluasi:
+		var evaluasi models.Evaluasi
+		if err := db.Preload("Jadwal.Dosen").First(&evaluasi, "id = ?", sesi.TargetID).Error; err != nil {
+			return "", http.StatusNotFound, errors.New("Evaluasi tidak ditemukan")
+		}
+		pathHasil, err := lampirkanAudioEvaluasi(c, evaluasi, src, sesi.UkuranTotal, sesi.NamaFile)
+		if err != nil {
+			return "", statusSimpanAudio(err), fmt.Errorf("Gagal menyimpan file audio: %w", err)
+		}
+		return pathHasil, http.StatusOK, nil
+	case models.UploadTujuanDosen:
+		var dosen models.Dosen
+		if err := db.First(&dosen, "id = ?", sesi.TargetID).Error; err != nil {
+			return "", http.StatusNotFound, errors.New("Dosen tidak ditemukan")
+		}
+		pathHasil, err := lampirkanSampelSuara(c, dosen, src, sesi.UkuranTotal, sesi.NamaFile)
+		if err != nil {
+			return "", statusSimpanAudio(err), fmt.Errorf("Gagal menyimpan file audio: %w", err)
+		}
+		return pathHasil, http.StatusOK, nil
+	}
+	return "", http.StatusBadRequest, errors.New("Tujuan upload tidak valid")
+}
+
+// checksumPotongan menghitung SHA-256 gabungan potongan sesi dalam heksadesimal
+func checksumPotongan(ctx context.Context, sesi *models.UploadSesi) (string, error) {
+	src := storage.BacaPotongan(ctx, blobStore, sesi.PrefixPotongan, sesi.UkuranTotal)
+	defer src.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, src)
+	if err != nil {
+		return "", err
+	}
+	if n != sesi.UkuranTotal {
+		return "", fmt.Errorf("potongan upload hanya berisi %d dari %d byte", n, sesi.UkuranTotal)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Handler untuk membatalkan sesi upload dan menghapus data yang sudah diterima
+func BatalkanUploadSesi(c *gin.Context) {
+	sesi, ok := sesiUploadRequest(c)
+	if !ok {
+		return
+	}
+
+	result := database.GetDB().Model(&models.UploadSesi{}).
+		Where("id = ? AND status = ?", sesi.ID, models.UploadStatusAktif).
+		Update("status", models.UploadStatusBatal)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Sesi upload sudah berstatus %s", sesi.Status)})
+		return
+	}
+	blobStore.DeletePrefix(c.Request.Context(), sesi.PrefixPotongan)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sesi upload dibatalkan"})
+}
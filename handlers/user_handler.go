@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"CLAIRE/auth"
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errFormatDosenUser   = errors.New("Format dosen_id tidak valid")
+	errDosenUserTidakAda = errors.New("Dosen tidak ditemukan")
+	errDosenSudahTertaut = errors.New("Dosen sudah terhubung ke akun lain")
+)
+
+type userInput struct {
+	Username string  `json:"username"`
+	Nama     string  `json:"nama"`
+	Email    string  `json:"email"`
+	Password string  `json:"password"`
+	Role     string  `json:"role"`
+	DosenID  *string `json:"dosen_id"`
+	Aktif    *bool   `json:"aktif"`
+}
+
+// parseDosenUser memvalidasi dosen_id untuk akun dosen. String kosong
+// berarti melepas hubungan ke dosen. Satu dosen hanya boleh terhubung ke satu
+// akun selain akun userID sendiri.
+func parseDosenUser(db *gorm.DB, value *string, userID uuid.UUID) (*uuid.UUID, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*value)
+	if err != nil {
+		return nil, errFormatDosenUser
+	}
+	var dosen models.Dosen
+	if err := db.First(&dosen, "id = ?", id).Error; err != nil {
+		return nil, errDosenUserTidakAda
+	}
+	var jumlah int64
+	db.Unscoped().Model(&models.User{}).Where("dosen_id = ? AND id <> ?", id, userID).Count(&jumlah)
+	if jumlah > 0 {
+		return nil, errDosenSudahTertaut
+	}
+	return &id, nil
+}
+
+func statusDosenUser(err error) int {
+	if errors.Is(err, errDosenSudahTertaut) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// Handler untuk membuat akun pengguna baru
+func BuatUser(c *gin.Context) {
+	var input userInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input.Username = strings.TrimSpace(input.Username)
+	if input.Username == "" || input.Password == "" || input.Role == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Username, password dan role wajib diisi"})
+		return
+	}
+	if !models.RoleValid(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role tidak valid. Gunakan: admin, operator, dosen, viewer"})
+		return
+	}
+
+	db := database.GetDB()
+	var jumlah int64
+	db.Unscoped().Model(&models.User{}).Where("username = ?", input.Username).Count(&jumlah)
+	if jumlah > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Username sudah dipakai"})
+		return
+	}
+
+	dosenID, err := parseDosenUser(db, input.DosenID, uuid.Nil)
+	if err != nil {
+		c.JSON(statusDosenUser(err), gin.H{"error": err.Error()})
+		return
+	}
+	if input.Role == models.RoleDosen && dosenID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Akun dosen harus dihubungkan ke data dosen (dosen_id)"})
+		return
+	}
+
+	hash, err := auth.HashPassword(input.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := models.User{
+		Username:     input.Username,
+		Nama:         input.Nama,
+		Email:        input.Email,
+		PasswordHash: hash,
+		Role:         input.Role,
+		DosenID:      dosenID,
+		Aktif:        true,
+	}
+	if input.Aktif != nil {
+		user.Aktif = *input.Aktif
+	}
+
+	if err := db.Create(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Kolom default:true tidak ikut disimpan jika bernilai false
+	if !user.Aktif {
+		db.Model(&user).Update("aktif", false)
+	}
+
+	db.Preload("Dosen").First(&user, "id = ?", user.ID)
+	c.JSON(http.StatusCreated, user)
+}
+
+// Handler untuk daftar pengguna
+func DapatkanSemuaUser(c *gin.Context) {
+	var users []models.User
+	query := database.GetDB().Preload("Dosen")
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	result := query.Order("username ASC").Find(&users)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func DapatkanUser(c *gin.Context) {
+	id := c.Param("id")
+
+	var user models.User
+	result := database.GetDB().Preload("Dosen").First(&user, "id = ?", id)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pengguna tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Handler untuk mengubah akun pengguna. Mengganti password atau
+// menonaktifkan akun mencabut semua token lama pengguna itu.
+func UpdateUser(c *gin.Context) {
+	id := c.Param("id")
+
+	var user models.User
+	db := database.GetDB()
+	if err := db.First(&user, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pengguna tidak ditemukan"})
+		return
+	}
+
+	var input userInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{"tanggal_diupdate": time.Now()}
+	cabutToken := false
+
+	if username := strings.TrimSpace(input.Username); username != "" && username != user.Username {
+		var jumlah int64
+		db.Unscoped().Model(&models.User{}).Where("username = ? AND id <> ?", username, user.ID).Count(&jumlah)
+		if jumlah > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username sudah dipakai"})
+			return
+		}
+		updates["username"] = username
+	}
+	if input.Nama != "" {
+		updates["nama"] = input.Nama
+	}
+	if input.Email != "" {
+		updates["email"] = input.Email
+	}
+
+	role := user.Role
+	if input.Role != "" && input.Role != user.Role {
+		if !models.RoleValid(input.Role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Role tidak valid. Gunakan: admin, operator, dosen, viewer"})
+			return
+		}
+		role = input.Role
+		updates["role"] = role
+	}
+
+	dosenID := user.DosenID
+	if input.DosenID != nil {
+		parsed, err := parseDosenUser(db, input.DosenID, user.ID)
+		if err != nil {
+			c.JSON(statusDosenUser(err), gin.H{"error": err.Error()})
+			return
+		}
+		dosenID = parsed
+		updates["dosen_id"] = parsed
+	}
+	if role == models.RoleDosen && dosenID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Akun dosen harus dihubungkan ke data dosen (dosen_id)"})
+		return
+	}
+
+	if input.Password != "" {
+		hash, err := auth.HashPassword(input.Password)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["password_hash"] = hash
+		cabutToken = true
+	}
+	if input.Aktif != nil {
+		updates["aktif"] = *input.Aktif
+		if !*input.Aktif {
+			cabutToken = true
+		}
+	}
+
+	// Admin tidak boleh mengunci dirinya sendiri
+	if self := auth.Pengguna(c); self != nil && self.ID == user.ID {
+		if role != models.RoleAdmin || (input.Aktif != nil && !*input.Aktif) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tidak bisa menurunkan role atau menonaktifkan akun sendiri"})
+			return
+		}
+	}
+
+	if cabutToken {
+		updates["versi_token"] = gorm.Expr("versi_token + 1")
+	}
+
+	if err := db.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	db.Preload("Dosen").First(&user, "id = ?", user.ID)
+	c.JSON(http.StatusOK, user)
+}
+
+// Handler untuk menghapus akun pengguna
+func HapusUser(c *gin.Context) {
+	id := c.Param("id")
+
+	var user models.User
+	db := database.GetDB()
+	if err := db.First(&user, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pengguna tidak ditemukan"})
+		return
+	}
+	if self := auth.Pengguna(c); self != nil && self.ID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tidak bisa menghapus akun sendiri"})
+		return
+	}
+
+	// Lepas hubungan ke dosen supaya dosen itu bisa dihubungkan ke akun baru
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("dosen_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, "id = ?", user.ID).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pengguna berhasil dihapus"})
+}
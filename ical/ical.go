@@ -0,0 +1,232 @@
+// Package ical menulis kalender iCalendar (RFC 5545) sederhana untuk
+// jadwal mingguan: event berulang dengan RRULE dan komponen VTIMEZONE.
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	localFormat = "20060102T150405"
+	utcFormat   = "20060102T150405Z"
+)
+
+// Calendar adalah satu file .ics
+type Calendar struct {
+	ProdID   string
+	Name     string
+	Location *time.Location
+	Events   []Event
+}
+
+// Event adalah satu VEVENT. Start dan End diinterpretasikan di zona waktu
+// kalender.
+type Event struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+	RRule       string      // tanpa prefix "RRULE:", kosong jika tidak berulang
+	ExDates     []time.Time // tanggal kejadian yang dilewati
+	Stamp       time.Time
+}
+
+// WeeklyRule membuat RRULE mingguan pada hari tertentu. until nol berarti
+// berulang tanpa batas.
+func WeeklyRule(day time.Weekday, until time.Time) string {
+	rule := "FREQ=WEEKLY;BYDAY=" + byDay[day]
+	if !until.IsZero() {
+		rule += ";UNTIL=" + until.UTC().Format(utcFormat)
+	}
+	return rule
+}
+
+var byDay = map[time.Weekday]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
+
+// Write menulis kalender ke w
+func (c *Calendar) Write(w io.Writer) error {
+	loc := c.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	tzid := loc.String()
+
+	lw := &lineWriter{w: w}
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + c.ProdID)
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		lw.line("X-WR-CALNAME:" + Escape(c.Name))
+	}
+	lw.line("X-WR-TIMEZONE:" + tzid)
+
+	writeTimezone(lw, loc, c.firstYear())
+
+	for _, e := range c.Events {
+		stamp := e.Stamp
+		if stamp.IsZero() {
+			stamp = time.Now()
+		}
+
+		lw.line("BEGIN:VEVENT")
+		lw.line("UID:" + e.UID)
+		lw.line("DTSTAMP:" + stamp.UTC().Format(utcFormat))
+		lw.line("DTSTART;TZID=" + tzid + ":" + e.Start.In(loc).Format(localFormat))
+		lw.line("DTEND;TZID=" + tzid + ":" + e.End.In(loc).Format(localFormat))
+		if e.RRule != "" {
+			lw.line("RRULE:" + e.RRule)
+		}
+		for _, ex := range e.ExDates {
+			lw.line("EXDATE;TZID=" + tzid + ":" + ex.In(loc).Format(localFormat))
+		}
+		lw.line("SUMMARY:" + Escape(e.Summary))
+		if e.Location != "" {
+			lw.line("LOCATION:" + Escape(e.Location))
+		}
+		if e.Description != "" {
+			lw.line("DESCRIPTION:" + Escape(e.Description))
+		}
+		lw.line("END:VEVENT")
+	}
+
+	lw.line("END:VCALENDAR")
+	return lw.err
+}
+
+func (c *Calendar) firstYear() int {
+	year := time.Now().Year()
+	for _, e := range c.Events {
+		if !e.Start.IsZero() && e.Start.Year() < year {
+			year = e.Start.Year()
+		}
+	}
+	return year
+}
+
+// Escape meng-escape teks sesuai RFC 5545 bagian 3.3.11
+func Escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// lineWriter menulis baris dengan akhiran CRLF dan memotong baris yang
+// lebih dari 75 oktet tanpa memotong karakter UTF-8
+type lineWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (lw *lineWriter) line(s string) {
+	if lw.err != nil {
+		return
+	}
+
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // spasi lipatan ikut dihitung
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+
+	_, lw.err = io.WriteString(lw.w, b.String())
+}
+
+// writeTimezone menulis VTIMEZONE untuk loc. Zona tanpa DST (seperti
+// WIB/WITA/WIT) cukup satu komponen STANDARD; zona dengan DST ditulis
+// dengan transisi pada tahun fromYear.
+func writeTimezone(lw *lineWriter, loc *time.Location, fromYear int) {
+	lw.line("BEGIN:VTIMEZONE")
+	lw.line("TZID:" + loc.String())
+
+	start := time.Date(fromYear, time.January, 1, 0, 0, 0, 0, loc)
+	transitions := findTransitions(start, start.AddDate(1, 0, 0))
+	if len(transitions) == 0 {
+		name, offset := start.Zone()
+		writeObservance(lw, "STANDARD", time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), name, offset, offset)
+	} else {
+		for _, t := range transitions {
+			_, before := t.Add(-time.Second).Zone()
+			name, after := t.Zone()
+			kind := "STANDARD"
+			if after > before {
+				kind = "DAYLIGHT"
+			}
+			// DTSTART observance memakai waktu lokal sebelum transisi
+			local := t.UTC().Add(time.Duration(before) * time.Second)
+			writeObservance(lw, kind, local, name, before, after)
+		}
+	}
+
+	lw.line("END:VTIMEZONE")
+}
+
+func writeObservance(lw *lineWriter, kind string, start time.Time, name string, from int, to int) {
+	lw.line("BEGIN:" + kind)
+	lw.line("DTSTART:" + start.Format(localFormat))
+	lw.line("TZOFFSETFROM:" + formatOffset(from))
+	lw.line("TZOFFSETTO:" + formatOffset(to))
+	lw.line("TZNAME:" + name)
+	lw.line("END:" + kind)
+}
+
+// findTransitions mencari waktu perubahan offset zona dalam rentang
+func findTransitions(from time.Time, to time.Time) []time.Time {
+	var result []time.Time
+	_, prev := from.Zone()
+	for t := from; t.Before(to); t = t.Add(24 * time.Hour) {
+		next := t.Add(24 * time.Hour)
+		if _, offset := next.Zone(); offset != prev {
+			// Persempit ke menit transisi
+			lo, hi := t, next
+			for hi.Sub(lo) > time.Minute {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.Zone(); o == prev {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			result = append(result, hi.Truncate(time.Minute))
+			prev = offset
+		}
+	}
+	return result
+}
+
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, (seconds%3600)/60)
+}
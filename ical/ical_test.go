@@ -0,0 +1,131 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+	"unicode/utf8"
+)
+
+func TestWrite(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mulai := time.Date(2026, 9, 7, 8, 0, 0, 0, jakarta)
+	cal := Calendar{
+		ProdID:   "-//CLAIRE//Uji//ID",
+		Name:     "Jadwal Mengajar Budi, M.T.",
+		Location: jakarta,
+		Events: []Event{{
+			UID:         "jadwal-1@claire",
+			Summary:     "Algoritma; kelas A",
+			Location:    "R101, Gedung B",
+			Description: "Baris satu\nBaris dua",
+			Start:       mulai,
+			End:         mulai.Add(100 * time.Minute),
+			RRule:       WeeklyRule(time.Monday, time.Date(2026, 12, 21, 0, 0, 0, 0, time.UTC)),
+			ExDates:     []time.Time{mulai.AddDate(0, 0, 7)},
+			Stamp:       time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := cal.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	want := []string{
+		"BEGIN:VCALENDAR\r\n",
+		"PRODID:-//CLAIRE//Uji//ID\r\n",
+		"X-WR-CALNAME:Jadwal Mengajar Budi\\, M.T.\r\n",
+		"X-WR-TIMEZONE:Asia/Jakarta\r\n",
+		"BEGIN:VTIMEZONE\r\nTZID:Asia/Jakarta\r\nBEGIN:STANDARD\r\nDTSTART:19700101T000000\r\nTZOFFSETFROM:+0700\r\nTZOFFSETTO:+0700\r\nTZNAME:WIB\r\nEND:STANDARD\r\nEND:VTIMEZONE\r\n",
+		"UID:jadwal-1@claire\r\n",
+		"DTSTAMP:20260901T000000Z\r\n",
+		"DTSTART;TZID=Asia/Jakarta:20260907T080000\r\n",
+		"DTEND;TZID=Asia/Jakarta:20260907T094000\r\n",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=20261221T000000Z\r\n",
+		"EXDATE;TZID=Asia/Jakarta:20260914T080000\r\n",
+		"SUMMARY:Algoritma\\; kelas A\r\n",
+		"LOCATION:R101\\, Gedung B\r\n",
+		"DESCRIPTION:Baris satu\\nBaris dua\r\n",
+		"END:VEVENT\r\nEND:VCALENDAR\r\n",
+	}
+	for _, bagian := range want {
+		if !strings.Contains(out, bagian) {
+			t.Errorf("output tidak memuat %q\n%s", bagian, out)
+		}
+	}
+}
+
+func TestWriteZonaDST(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, amsterdam)
+	cal := Calendar{ProdID: "-//uji//", Location: amsterdam, Events: []Event{{UID: "a", Start: start, End: start.Add(time.Hour)}}}
+
+	var buf bytes.Buffer
+	if err := cal.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, bagian := range []string{
+		"BEGIN:DAYLIGHT\r\nDTSTART:20260329T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\n",
+		"BEGIN:STANDARD\r\nDTSTART:20261025T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\n",
+	} {
+		if !strings.Contains(out, bagian) {
+			t.Errorf("VTIMEZONE tidak memuat %q\n%s", bagian, out)
+		}
+	}
+}
+
+func TestLipatBaris(t *testing.T) {
+	var buf bytes.Buffer
+	lw := &lineWriter{w: &buf}
+	panjang := "DESCRIPTION:" + strings.Repeat("é", 100)
+	lw.line(panjang)
+
+	baris := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	if len(baris) < 3 {
+		t.Fatalf("baris panjang tidak dilipat: %q", baris)
+	}
+	var gabung strings.Builder
+	for i, b := range baris {
+		if len(b) > 75 {
+			t.Errorf("baris %d panjangnya %d oktet, maksimal 75", i, len(b))
+		}
+		if i > 0 {
+			if !strings.HasPrefix(b, " ") {
+				t.Errorf("baris lanjutan %d tidak diawali spasi", i)
+			}
+			b = b[1:]
+		}
+		if !utf8.ValidString(b) {
+			t.Errorf("baris %d memotong karakter UTF-8", i)
+		}
+		gabung.WriteString(b)
+	}
+	if gabung.String() != panjang {
+		t.Error("isi baris berubah setelah dilipat")
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := map[string]string{
+		`a\b`:         `a\\b`,
+		"a;b,c":       `a\;b\,c`,
+		"satu\r\ndua": `satu\ndua`,
+		"tiga\nempat": `tiga\nempat`,
+	}
+	for in, want := range tests {
+		if got := Escape(in); got != want {
+			t.Errorf("Escape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package kalender
+
+import "time"
+
+// Nama hari jadwal dalam urutan Senin sampai Minggu
+var daftarHari = []string{"SENIN", "SELASA", "RABU", "KAMIS", "JUMAT", "SABTU", "MINGGU"}
+
+// Pemetaan nama hari Indonesia <-> time.Weekday
+var hariWeekday = map[string]time.Weekday{
+	"SENIN":  time.Monday,
+	"SELASA": time.Tuesday,
+	"RABU":   time.Wednesday,
+	"KAMIS":  time.Thursday,
+	"JUMAT":  time.Friday,
+	"SABTU":  time.Saturday,
+	"MINGGU": time.Sunday,
+}
+
+// Weekday mengubah nama hari jadwal (misalnya "SENIN") menjadi time.Weekday
+func Weekday(hari string) (time.Weekday, bool) {
+	w, ok := hariWeekday[hari]
+	return w, ok
+}
+
+// NamaHari mengubah time.Weekday menjadi nama hari jadwal
+func NamaHari(w time.Weekday) string {
+	return daftarHari[UrutanHari(w)]
+}
+
+// HariValid mengecek apakah hari adalah nama hari jadwal yang dikenal
+func HariValid(hari string) bool {
+	_, ok := hariWeekday[hari]
+	return ok
+}
+
+// DaftarHari mengembalikan nama hari dari Senin sampai Minggu
+func DaftarHari() []string {
+	return append([]string(nil), daftarHari...)
+}
+
+// UrutanHari mengembalikan posisi hari dalam minggu kuliah, Senin = 0
+// sampai Minggu = 6
+func UrutanHari(w time.Weekday) int {
+	return (int(w) + 6) % 7
+}
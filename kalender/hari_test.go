@@ -0,0 +1,59 @@
+package kalender
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekdayDanNamaHari(t *testing.T) {
+	tests := []struct {
+		hari    string
+		weekday time.Weekday
+	}{
+		{"SENIN", time.Monday},
+		{"SELASA", time.Tuesday},
+		{"RABU", time.Wednesday},
+		{"KAMIS", time.Thursday},
+		{"JUMAT", time.Friday},
+		{"SABTU", time.Saturday},
+		{"MINGGU", time.Sunday},
+	}
+	for _, tt := range tests {
+		got, ok := Weekday(tt.hari)
+		if !ok || got != tt.weekday {
+			t.Errorf("Weekday(%q) = %v, %v, want %v", tt.hari, got, ok, tt.weekday)
+		}
+		if got := NamaHari(tt.weekday); got != tt.hari {
+			t.Errorf("NamaHari(%v) = %q, want %q", tt.weekday, got, tt.hari)
+		}
+		if !HariValid(tt.hari) {
+			t.Errorf("HariValid(%q) = false", tt.hari)
+		}
+	}
+}
+
+func TestHariTidakValid(t *testing.T) {
+	for _, hari := range []string{"", "MONDAY", "senin", "Senin", "SENEN"} {
+		if _, ok := Weekday(hari); ok {
+			t.Errorf("Weekday(%q) harus gagal", hari)
+		}
+		if HariValid(hari) {
+			t.Errorf("HariValid(%q) = true", hari)
+		}
+	}
+}
+
+func TestUrutanHari(t *testing.T) {
+	if got := UrutanHari(time.Monday); got != 0 {
+		t.Errorf("UrutanHari(Monday) = %d, want 0", got)
+	}
+	if got := UrutanHari(time.Sunday); got != 6 {
+		t.Errorf("UrutanHari(Sunday) = %d, want 6", got)
+	}
+	for i, hari := range DaftarHari() {
+		w, _ := Weekday(hari)
+		if got := UrutanHari(w); got != i {
+			t.Errorf("UrutanHari(%s) = %d, want %d", hari, got, i)
+		}
+	}
+}
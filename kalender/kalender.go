@@ -0,0 +1,120 @@
+// Package kalender adalah satu-satunya sumber waktu untuk evaluasi jadwal
+// kuliah. Semua perbandingan hari dan jam dilakukan di zona waktu kampus
+// (TIMEZONE), bukan zona waktu server, sehingga hasilnya sama baik server
+// berjalan di container UTC maupun di host WIB.
+package kalender
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"CLAIRE/config"
+)
+
+// Kalender menghitung hari, jam dan tanggal di zona waktu kampus
+type Kalender struct {
+	lokasi *time.Location
+	now    func() time.Time
+}
+
+// New membuat Kalender untuk zona waktu lokasi
+func New(lokasi *time.Location) *Kalender {
+	if lokasi == nil {
+		lokasi = time.Local
+	}
+	return &Kalender{lokasi: lokasi, now: time.Now}
+}
+
+// WithClock mengganti sumber waktu, dipakai saat pengujian
+func (k *Kalender) WithClock(now func() time.Time) *Kalender {
+	return &Kalender{lokasi: k.lokasi, now: now}
+}
+
+var (
+	defaultKalender *Kalender
+	defaultMu       sync.Mutex
+)
+
+// Default mengembalikan Kalender dari konfigurasi TIMEZONE. Zona yang tidak
+// valid dicatat di log lalu diganti zona waktu server.
+func Default() *Kalender {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultKalender == nil {
+		cfg := config.LoadConfig()
+		lokasi, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			log.Printf("Zona waktu %q tidak valid, memakai zona waktu server: %v", cfg.Timezone, err)
+			lokasi = time.Local
+		}
+		defaultKalender = New(lokasi)
+	}
+	return defaultKalender
+}
+
+// SetDefault mengganti Kalender default
+func SetDefault(k *Kalender) {
+	defaultMu.Lock()
+	defaultKalender = k
+	defaultMu.Unlock()
+}
+
+// Lokasi mengembalikan zona waktu kampus
+func (k *Kalender) Lokasi() *time.Location {
+	return k.lokasi
+}
+
+// Sekarang mengembalikan waktu sekarang di zona waktu kampus
+func (k *Kalender) Sekarang() time.Time {
+	return k.now().In(k.lokasi)
+}
+
+// Hari mengembalikan nama hari (SENIN..MINGGU) dari t di zona waktu kampus
+func (k *Kalender) Hari(t time.Time) string {
+	return NamaHari(t.In(k.lokasi).Weekday())
+}
+
+// HariIni mengembalikan nama hari ini di zona waktu kampus
+func (k *Kalender) HariIni() string {
+	return k.Hari(k.now())
+}
+
+// Jam mengembalikan jam HH:MM dari t di zona waktu kampus
+func (k *Kalender) Jam(t time.Time) string {
+	return t.In(k.lokasi).Format("15:04")
+}
+
+// Tanggal mengembalikan tanggal kalender t di zona waktu kampus. Hasilnya
+// berupa tengah malam di zona waktu server, format yang sama dengan kolom
+// DATE yang dibaca dari database.
+func (k *Kalender) Tanggal(t time.Time) time.Time {
+	t = t.In(k.lokasi)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// TanggalHariIni mengembalikan tanggal hari ini di zona waktu kampus
+func (k *Kalender) TanggalHariIni() time.Time {
+	return k.Tanggal(k.now())
+}
+
+// Pada menggabungkan tanggal kalender dengan jam HH:MM di zona waktu kampus
+func (k *Kalender) Pada(tanggal time.Time, jam string) (time.Time, error) {
+	t, err := time.Parse("15:04", jam)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), t.Hour(), t.Minute(), 0, 0, k.lokasi), nil
+}
+
+// AwalHari mengembalikan tengah malam tanggal t di zona waktu kampus
+func (k *Kalender) AwalHari(t time.Time) time.Time {
+	t = t.In(k.lokasi)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, k.lokasi)
+}
+
+// ParseTanggal membaca tanggal kalender format YYYY-MM-DD
+func ParseTanggal(value string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
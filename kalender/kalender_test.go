@@ -0,0 +1,68 @@
+package kalender
+
+import (
+	"testing"
+	"time"
+)
+
+// wib adalah zona waktu kampus di pengujian, sengaja berbeda dari UTC
+var wib = time.FixedZone("WIB", 7*60*60)
+
+func TestHariDiZonaKampus(t *testing.T) {
+	// Minggu 20:00 UTC sudah Senin 03:00 WIB
+	now := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	k := New(wib).WithClock(func() time.Time { return now })
+
+	if got := k.HariIni(); got != "SENIN" {
+		t.Errorf("HariIni = %q, want SENIN", got)
+	}
+	if got := k.Jam(now); got != "03:00" {
+		t.Errorf("Jam = %q, want 03:00", got)
+	}
+	if got := k.Sekarang(); got.Location() != wib || !got.Equal(now) {
+		t.Errorf("Sekarang = %v, want %v di WIB", got, now)
+	}
+	if got := New(time.UTC).Hari(now); got != "MINGGU" {
+		t.Errorf("Hari di UTC = %q, want MINGGU", got)
+	}
+}
+
+func TestTanggal(t *testing.T) {
+	now := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	k := New(wib).WithClock(func() time.Time { return now })
+
+	want := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+	if got := k.TanggalHariIni(); !got.Equal(want) {
+		t.Errorf("TanggalHariIni = %v, want %v", got, want)
+	}
+	if got := k.AwalHari(now); !got.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, wib)) {
+		t.Errorf("AwalHari = %v, want 2026-10-19 00:00 WIB", got)
+	}
+
+	tgl, err := ParseTanggal("2026-10-19")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tgl.Equal(want) {
+		t.Errorf("ParseTanggal = %v, want %v", tgl, want)
+	}
+	if _, err := ParseTanggal("19-10-2026"); err == nil {
+		t.Error("format tanggal tidak valid harus gagal")
+	}
+}
+
+func TestPada(t *testing.T) {
+	k := New(wib)
+	tgl := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+
+	got, err := k.Pada(tgl, "08:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 19, 1, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Pada = %v, want %v", got, want)
+	}
+	if _, err := k.Pada(tgl, "8 pagi"); err == nil {
+		t.Error("jam tidak valid harus gagal")
+	}
+}
@@ -1,12 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"CLAIRE/analysis"
+	"CLAIRE/audio"
+	"CLAIRE/auth"
+	"CLAIRE/config"
 	"CLAIRE/database"
 	"CLAIRE/handlers"
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+	"CLAIRE/reconciler"
+	"CLAIRE/recording"
+	"CLAIRE/scheduler"
+	"CLAIRE/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -42,14 +58,105 @@ func main() {
 		log.Fatal("Gagal migrasi database:", err)
 	}
 
+	// Context yang dibatalkan saat server menerima SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.LoadConfig()
+
+	// Kebijakan rekaman global untuk jadwal yang tidak mengatur sendiri
+	models.SetDefaultKebijakanRekaman(models.KebijakanRekaman{
+		JumlahSesi:  cfg.RecordingSessionCount,
+		DurasiSesi:  int(cfg.RecordingSessionDuration.Seconds()),
+		OffsetMulai: int(cfg.RecordingOffset.Minutes()),
+		JedaSesi:    int(cfg.RecordingSessionGap.Seconds()),
+		RekamPenuh:  cfg.RecordingFullClass,
+	})
+
+	// Client layanan analisis dan antrian job rekaman
+	analyzer := analysis.NewFromConfig(cfg)
+	handlers.SetAnalysisClient(analyzer)
+
+	// Penyimpanan file audio (disk lokal atau object storage S3-compatible)
+	store, err := storage.FromConfig(cfg)
+	if err != nil {
+		log.Fatal("Gagal menyiapkan storage:", err)
+	}
+	var presignTTL time.Duration
+	if cfg.StoragePresign {
+		presignTTL = cfg.StoragePresignTTL
+	}
+	handlers.SetBlobStore(store, presignTTL)
+	log.Printf("Storage audio: %s", store.Name())
+
+	// Syarat kualitas sampel suara pendaftaran dosen
+	handlers.SetSyaratSampelSuara(audio.SyaratKualitas{
+		DurasiBicaraMin:   cfg.VoiceSampleMinSpeech.Seconds(),
+		RasioHeningMaks:   cfg.VoiceSampleMaxSilence,
+		RasioClippingMaks: cfg.VoiceSampleMaxClipping,
+		SampleRateMin:     cfg.VoiceSampleMinRate,
+	})
+
+	queue := recording.NewQueue(db, cfg, analyzer)
+	queue.SetStore(store)
+	if err := queue.Recover(); err != nil {
+		log.Println("Gagal memulihkan job rekaman:", err)
+	}
+	queue.Start(ctx)
+	handlers.SetRecordingQueue(queue)
+
+	// Scheduler yang memulai rekaman otomatis sesuai jadwal
+	if cfg.SchedulerEnabled {
+		jadwalScheduler := scheduler.New(db, scheduler.RealClock{}, func(jadwal models.Jadwal, pertemuan *models.Pertemuan) error {
+			_, err := queue.Enqueue(jadwal)
+			return err
+		})
+		if err := jadwalScheduler.Load(); err != nil {
+			log.Println("Gagal memuat jadwal ke scheduler:", err)
+		}
+		go jadwalScheduler.Run(ctx)
+		handlers.SetScheduler(jadwalScheduler)
+	}
+
+	// Reconciler yang memperbarui status jadwal dan pertemuan di latar belakang
+	statusReconciler := reconciler.New(db, cfg.StatusReconcileInterval)
+	statusReconciler.OnTransition(func(t reconciler.Transisi) {
+		log.Printf("Status %s %s berubah: %s -> %s", t.Jenis, t.ID, t.Dari, t.Ke)
+	})
+	statusReconciler.Start(ctx)
+	handlers.SetReconciler(statusReconciler)
+
+	// Token login dan akun admin pertama
+	signer := auth.NewSigner(auth.SecretDari(cfg.AuthSecret), cfg.AuthTokenTTL)
+	signer.SetTTLURL(cfg.AuthURLTokenTTL)
+	handlers.SetAuthSigner(signer)
+	if err := auth.BootstrapAdmin(db, cfg.AdminUsername, cfg.AdminPassword); err != nil {
+		log.Println("Gagal membuat akun admin pertama:", err)
+	}
+
 	// Initialize Gin router
-	router := gin.Default()
+	// Log request tanpa nilai ?token= supaya token URL tidak tersimpan di log
+	router := gin.New()
+	router.Use(gin.LoggerWithFormatter(auth.FormatLog), gin.Recovery())
 
-	// CORS middleware
+	// CORS middleware, hanya origin yang terdaftar di CORS_ALLOWED_ORIGINS
+	allowedOrigins := map[string]bool{}
+	for _, origin := range strings.Split(cfg.CORSAllowedOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			allowedOrigins[origin] = true
+		}
+	}
 	router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		origin := c.GetHeader("Origin")
+		if allowedOrigins["*"] {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else if origin != "" && allowedOrigins[origin] {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Vary", "Origin")
+		}
+		c.Header("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, DELETE, OPTIONS, PATCH")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Range, Authorization, X-API-Key")
+		c.Header("Access-Control-Expose-Headers", "Upload-Offset, Upload-Length, Upload-Complete, Location")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -61,65 +168,176 @@ func main() {
 
 	// API routes
 	api := router.Group("/api/v1")
+	api.POST("/auth/login", handlers.Login)
+
+	// Route mesin menerima API key dengan scope yang sesuai atau token login
+	// pengguna, sehingga didaftarkan sebelum middleware token
+	rekaman := func(izin string) gin.HandlerFunc { return auth.Mesin(signer, db, auth.ScopeRekaman, izin) }
+	analisis := auth.Mesin(signer, db, auth.ScopeAnalisis, auth.IzinKelolaEvaluasi)
+	api.POST("/evaluasi/analisis", analisis, handlers.SimpanHasilAnalisis)
+
+	// Recording routes (untuk service rekaman otomatis)
+	api.POST("/recording/scheduled/start/:jadwal_id", rekaman(auth.IzinRekaman), handlers.StartScheduledRecording)
+	api.POST("/recording/scheduled/stop/:jadwal_id", rekaman(auth.IzinRekaman), handlers.StopScheduledRecording)
+	api.GET("/recording/active", rekaman(auth.IzinBaca), handlers.GetActiveRecordings)
+	api.GET("/recording/status/:jadwal_id", rekaman(auth.IzinBaca), handlers.GetRecordingStatus)
+	api.POST("/recording/process-analysis", analisis, handlers.ProcessAudioAnalysis)
+
+	// Protokol agen perekam di ruang kelas, hanya dengan API key scope agen
+	agen := auth.Mesin(signer, db, auth.ScopeAgen, auth.IzinSistem)
+	api.POST("/agent/register", agen, handlers.RegisterAgen)
+	api.POST("/agent/heartbeat", agen, handlers.HeartbeatAgen)
+	api.GET("/agent/jobs/next", agen, handlers.AmbilJobAgen)
+	api.POST("/agent/jobs/:job_id/sesi/:nomor/status", agen, handlers.UpdateSesiAgen)
+	api.HEAD("/agent/jobs/:job_id/sesi/:nomor/audio", agen, handlers.OffsetAudioAgen)
+	api.PUT("/agent/jobs/:job_id/sesi/:nomor/audio", agen, handlers.UploadAudioAgen)
+	api.POST("/agent/jobs/:job_id/selesai", agen, handlers.SelesaiJobAgen)
+
+	// File audio dan feed kalender dibuka langsung dari <audio> atau aplikasi
+	// kalender, jadi selain header juga menerima token URL ?token= yang
+	// dibuat lewat /auth/token-url untuk path tersebut. Feed kalender juga
+	// menerima token feed yang dibuat lewat /kalender/feed.
+	tautan := auth.TokenURL(signer, db)
+	feed := auth.FeedKalender(signer, db)
+	api.GET("/audio/:dosenFolder/:filename", tautan, auth.Perlu(auth.IzinBaca), handlers.ServeAudioFile)
+	api.GET("/audio-evaluasi/:folderName/:filename", tautan, auth.Perlu(auth.IzinBacaEvaluasi), handlers.ServeAudioEvaluasi)
+	api.GET("/dosen/:id/kalender.ics", feed, auth.Perlu(auth.IzinBaca), handlers.KalenderDosen)
+	api.GET("/ruangan/:id/kalender.ics", feed, auth.Perlu(auth.IzinBaca), handlers.KalenderRuangan)
+	api.GET("/jadwal/kalender.ics", feed, auth.Perlu(auth.IzinBaca), handlers.KalenderSemuaJadwal)
+
+	api.Use(auth.Middleware(signer, db))
 	{
+		// Auth & pengguna routes
+		api.POST("/auth/ganti-password", handlers.GantiPassword)
+		api.POST("/auth/token-url", handlers.BuatTokenURL)
+		api.POST("/users", auth.Perlu(auth.IzinKelolaPengguna), handlers.BuatUser)
+		api.GET("/users", auth.Perlu(auth.IzinKelolaPengguna), handlers.DapatkanSemuaUser)
+		api.GET("/users/:id", auth.Perlu(auth.IzinKelolaPengguna), handlers.DapatkanUser)
+		api.PUT("/users/:id", auth.Perlu(auth.IzinKelolaPengguna), handlers.UpdateUser)
+		api.DELETE("/users/:id", auth.Perlu(auth.IzinKelolaPengguna), handlers.HapusUser)
+		api.POST("/api-keys", auth.Perlu(auth.IzinKelolaAPIKey), handlers.BuatAPIKey)
+		api.GET("/api-keys", auth.Perlu(auth.IzinKelolaAPIKey), handlers.DapatkanSemuaAPIKey)
+		api.DELETE("/api-keys/:id", auth.Perlu(auth.IzinKelolaAPIKey), handlers.CabutAPIKey)
+		api.POST("/kalender/feed", auth.Perlu(auth.IzinBaca), handlers.BuatFeedKalender)
+		api.GET("/kalender/feed", auth.Perlu(auth.IzinBaca), handlers.DapatkanSemuaFeedKalender)
+		api.DELETE("/kalender/feed/:id", auth.Perlu(auth.IzinBaca), handlers.CabutFeedKalender)
+
+		// Audit log
+		api.GET("/audit", auth.Perlu(auth.IzinAudit), handlers.DapatkanAuditLog)
+		api.GET("/audit/export", auth.Perlu(auth.IzinAudit), handlers.EksporAuditLog)
+
+		// Portal dosen: data milik akun yang sedang login
+		api.GET("/me", handlers.DapatkanProfilSaya)
+		api.GET("/me/jadwal", handlers.DapatkanJadwalSaya)
+		api.GET("/me/evaluasi", handlers.DapatkanEvaluasiSaya)
+		api.GET("/me/evaluasi/tren", handlers.DapatkanTrenEvaluasiSaya)
+		api.POST("/me/rekam-suara", auth.PerluSalahSatu(auth.IzinKelolaData, auth.IzinSuaraSendiri), handlers.RekamSuaraSaya)
+
 		// Dosen routes
-		api.POST("/dosen", handlers.BuatDosen)
-		api.GET("/dosen", handlers.DapatkanSemuaDosen)
-		api.GET("/dosen/:id", handlers.DapatkanDosen)
-		api.PUT("/dosen/:id", handlers.UpdateDosen)
-		api.DELETE("/dosen/:id", handlers.HapusDosen)
-		api.POST("/dosen/:id/rekam-suara", handlers.RekamSuaraDosen)
+		api.POST("/dosen", auth.Perlu(auth.IzinKelolaData), handlers.BuatDosen)
+		api.GET("/dosen", auth.Perlu(auth.IzinBaca), handlers.DapatkanSemuaDosen)
+		api.GET("/dosen/:id", auth.Perlu(auth.IzinBaca), handlers.DapatkanDosen)
+		api.PUT("/dosen/:id", auth.Perlu(auth.IzinKelolaData), handlers.UpdateDosen)
+		api.DELETE("/dosen/:id", auth.Perlu(auth.IzinHapus), handlers.HapusDosen)
+		api.POST("/dosen/:id/rekam-suara", auth.PerluSalahSatu(auth.IzinKelolaData, auth.IzinSuaraSendiri), handlers.RekamSuaraDosen)
+
+		// Laporan kualitas sampel suara sebelum disimpan
+		api.POST("/sampel-suara/cek", auth.PerluSalahSatu(auth.IzinKelolaData, auth.IzinSuaraSendiri), handlers.CekSampelSuara)
+
+		// Semester routes
+		api.POST("/semester", auth.Perlu(auth.IzinKelolaData), handlers.BuatSemester)
+		api.GET("/semester", auth.Perlu(auth.IzinBaca), handlers.DapatkanSemuaSemester)
+		api.GET("/semester/:id", auth.Perlu(auth.IzinBaca), handlers.DapatkanSemester)
+		api.PUT("/semester/:id", auth.Perlu(auth.IzinKelolaData), handlers.UpdateSemester)
+		api.DELETE("/semester/:id", auth.Perlu(auth.IzinHapus), handlers.HapusSemester)
+
+		// Mata kuliah routes
+		api.POST("/mata-kuliah", auth.Perlu(auth.IzinKelolaData), handlers.BuatMataKuliah)
+		api.GET("/mata-kuliah", auth.Perlu(auth.IzinBaca), handlers.DapatkanSemuaMataKuliah)
+		api.GET("/mata-kuliah/statistik", auth.Perlu(auth.IzinBaca), handlers.GetStatistikMataKuliah)
+		api.GET("/mata-kuliah/:id", auth.Perlu(auth.IzinBaca), handlers.DapatkanMataKuliah)
+		api.PUT("/mata-kuliah/:id", auth.Perlu(auth.IzinKelolaData), handlers.UpdateMataKuliah)
+		api.DELETE("/mata-kuliah/:id", auth.Perlu(auth.IzinHapus), handlers.HapusMataKuliah)
+
+		// Ruangan routes
+		api.POST("/ruangan", auth.Perlu(auth.IzinKelolaData), handlers.BuatRuangan)
+		api.GET("/ruangan", auth.Perlu(auth.IzinBaca), handlers.DapatkanSemuaRuangan)
+		api.GET("/ruangan/:id", auth.Perlu(auth.IzinBaca), handlers.DapatkanRuangan)
+		api.PUT("/ruangan/:id", auth.Perlu(auth.IzinKelolaData), handlers.UpdateRuangan)
+		api.DELETE("/ruangan/:id", auth.Perlu(auth.IzinHapus), handlers.HapusRuangan)
+		api.GET("/ruangan/:id/jadwal", auth.Perlu(auth.IzinBaca), handlers.DapatkanJadwalByRuangan)
+
+		// Agen perekam
+		api.GET("/agents", auth.Perlu(auth.IzinSistem), handlers.DapatkanSemuaAgen)
+		api.DELETE("/agents/:id", auth.Perlu(auth.IzinHapus), handlers.HapusAgen)
 
 		// Jadwal routes - Diperbarui dengan endpoint baru
-		api.POST("/jadwal", handlers.BuatJadwal)
-		api.GET("/jadwal", handlers.DapatkanSemuaJadwal)
-		api.GET("/jadwal/:id", handlers.DapatkanJadwal)
-		api.PUT("/jadwal/:id", handlers.UpdateJadwal)
-		api.DELETE("/jadwal/:id", handlers.HapusJadwal)
-		api.GET("/jadwal/sedang-rekam", handlers.DapatkanJadwalSedangRekam)
-		api.GET("/jadwal/hari-ini/aktif", handlers.DapatkanJadwalAktifHariIni)
-		api.GET("/jadwal/hari/:hari", handlers.DapatkanJadwalByHari)
-		api.POST("/jadwal/:id/mulai-rekam", handlers.MulaiRekaman)
-		api.POST("/jadwal/:id/hentikan-rekam", handlers.HentikanRekaman)
-		api.POST("/jadwal/:id/auto-record", handlers.MulaiRekamanOtomatis)
-		api.GET("/jadwal/:id/recording-schedule", handlers.GetRecordingSchedule)
-		api.PATCH("/jadwal/:id/status", handlers.UpdateStatusJadwal)
+		api.POST("/jadwal", auth.Perlu(auth.IzinKelolaData), handlers.BuatJadwal)
+		api.POST("/jadwal/import", auth.Perlu(auth.IzinKelolaData), handlers.ImportJadwal)
+		api.GET("/jadwal", auth.Perlu(auth.IzinBaca), handlers.DapatkanSemuaJadwal)
+		api.GET("/jadwal/:id", auth.Perlu(auth.IzinBaca), handlers.DapatkanJadwal)
+		api.PUT("/jadwal/:id", auth.Perlu(auth.IzinKelolaData), handlers.UpdateJadwal)
+		api.DELETE("/jadwal/:id", auth.Perlu(auth.IzinHapus), handlers.HapusJadwal)
+		api.GET("/jadwal/sedang-rekam", auth.Perlu(auth.IzinBaca), handlers.DapatkanJadwalSedangRekam)
+		api.GET("/jadwal/hari-ini/aktif", auth.Perlu(auth.IzinBaca), handlers.DapatkanJadwalAktifHariIni)
+		api.GET("/jadwal/hari/:hari", auth.Perlu(auth.IzinBaca), handlers.DapatkanJadwalByHari)
+		api.POST("/jadwal/:id/mulai-rekam", auth.Perlu(auth.IzinRekaman), handlers.MulaiRekaman)
+		api.POST("/jadwal/:id/hentikan-rekam", auth.Perlu(auth.IzinRekaman), handlers.HentikanRekaman)
+		api.POST("/jadwal/:id/auto-record", auth.Perlu(auth.IzinRekaman), handlers.MulaiRekamanOtomatis)
+		api.GET("/jadwal/:id/recording-schedule", auth.Perlu(auth.IzinBaca), handlers.GetRecordingSchedule)
+		api.PATCH("/jadwal/:id/status", auth.Perlu(auth.IzinRekaman), handlers.UpdateStatusJadwal)
+		api.GET("/jadwal/:id/history", auth.Perlu(auth.IzinBaca), handlers.DapatkanRiwayatStatusJadwal)
+		api.POST("/jadwal/:id/pertemuan/generate", auth.Perlu(auth.IzinKelolaData), handlers.GeneratePertemuan)
+		api.GET("/jadwal/:id/pertemuan", auth.Perlu(auth.IzinBaca), handlers.DapatkanPertemuanByJadwal)
+
+		// Pertemuan routes
+		api.GET("/pertemuan", auth.Perlu(auth.IzinBaca), handlers.DapatkanSemuaPertemuan)
+		api.GET("/pertemuan/:id", auth.Perlu(auth.IzinBaca), handlers.DapatkanPertemuan)
+		api.PUT("/pertemuan/:id/jadwal-ulang", auth.Perlu(auth.IzinKelolaData), handlers.JadwalUlangPertemuan)
+		api.PATCH("/pertemuan/:id/status", auth.Perlu(auth.IzinKelolaData), handlers.UpdateStatusPertemuan)
+		api.GET("/pertemuan/:id/evaluasi", auth.Perlu(auth.IzinBacaEvaluasi), handlers.DapatkanEvaluasiByPertemuan)
+
+		// Hari libur routes
+		api.POST("/hari-libur", auth.Perlu(auth.IzinKelolaData), handlers.BuatHariLibur)
+		api.GET("/hari-libur", auth.Perlu(auth.IzinBaca), handlers.DapatkanSemuaHariLibur)
+		api.DELETE("/hari-libur/:id", auth.Perlu(auth.IzinHapus), handlers.HapusHariLibur)
 
 		// Evaluasi routes - Diperbarui dengan endpoint baru
-		api.POST("/evaluasi", handlers.BuatEvaluasi)
-		api.GET("/evaluasi", handlers.DapatkanSemuaEvaluasi)
-		api.GET("/evaluasi/:id", handlers.DapatkanEvaluasi)
-		api.PUT("/evaluasi/:id", handlers.UpdateEvaluasi)
-		api.DELETE("/evaluasi/:id", handlers.HapusEvaluasi)
-		api.GET("/evaluasi/jadwal/:jadwal_id", handlers.DapatkanEvaluasiByJadwal)
-		api.POST("/evaluasi/:id/upload-audio", handlers.UploadAudioEvaluasi)
-		api.POST("/evaluasi/analisis", handlers.SimpanHasilAnalisis)
-		api.GET("/evaluasi/statistik", handlers.GetStatistikEvaluasi)
-		api.GET("/evaluasi/recent", handlers.GetEvaluasiRecent)
+		api.POST("/evaluasi", auth.Perlu(auth.IzinKelolaEvaluasi), handlers.BuatEvaluasi)
+		api.GET("/evaluasi", auth.Perlu(auth.IzinBacaEvaluasi), handlers.DapatkanSemuaEvaluasi)
+		api.GET("/evaluasi/:id", auth.Perlu(auth.IzinBacaEvaluasi), handlers.DapatkanEvaluasi)
+		api.PUT("/evaluasi/:id", auth.Perlu(auth.IzinKelolaEvaluasi), handlers.UpdateEvaluasi)
+		api.DELETE("/evaluasi/:id", auth.Perlu(auth.IzinHapus), handlers.HapusEvaluasi)
+		api.GET("/evaluasi/jadwal/:jadwal_id", auth.Perlu(auth.IzinBacaEvaluasi), handlers.DapatkanEvaluasiByJadwal)
+		api.POST("/evaluasi/:id/upload-audio", auth.Perlu(auth.IzinKelolaEvaluasi), handlers.UploadAudioEvaluasi)
+		api.GET("/evaluasi/statistik", auth.Perlu(auth.IzinBacaEvaluasi), handlers.GetStatistikEvaluasi)
+		api.GET("/evaluasi/recent", auth.Perlu(auth.IzinBacaEvaluasi), handlers.GetEvaluasiRecent)
 
 		// Audio file routes
-		api.GET("/audio/:dosenFolder/:filename", handlers.ServeAudioFile)
-		api.GET("/audio-evaluasi/:folderName/:filename", handlers.ServeAudioEvaluasi)
-		api.POST("/audio/upload", handlers.UploadAudioFile)
+		api.POST("/audio/upload", auth.Perlu(auth.IzinKelolaData), handlers.UploadAudioFile)
 
-		// Recording routes (untuk service rekaman otomatis)
-		api.POST("/recording/scheduled/start/:jadwal_id", handlers.StartScheduledRecording)
-		api.POST("/recording/scheduled/stop/:jadwal_id", handlers.StopScheduledRecording)
-		api.GET("/recording/active", handlers.GetActiveRecordings)
-		api.GET("/recording/status/:jadwal_id", handlers.GetRecordingStatus)
-		api.POST("/recording/process-analysis", handlers.ProcessAudioAnalysis)
+		// Upload audio bertahap (resumable) untuk evaluasi dan sampel suara dosen
+		upload := auth.PerluSalahSatu(auth.IzinKelolaEvaluasi, auth.IzinKelolaData, auth.IzinSuaraSendiri)
+		api.POST("/uploads", upload, handlers.BuatUploadSesi)
+		api.GET("/uploads/:id", upload, handlers.DapatkanUploadSesi)
+		api.HEAD("/uploads/:id", upload, handlers.OffsetUploadSesi)
+		api.PUT("/uploads/:id", upload, handlers.UploadChunkSesi)
+		api.POST("/uploads/:id/selesai", upload, handlers.SelesaikanUploadSesi)
+		api.DELETE("/uploads/:id", upload, handlers.BatalkanUploadSesi)
 
 		// Dashboard routes
-		api.GET("/dashboard/overview", handlers.GetDashboardOverview)
-		api.GET("/dashboard/activities", handlers.GetRecentActivities)
-		api.GET("/dashboard/stats", handlers.GetDashboardStats)
-		api.GET("/dashboard/upcoming-jadwal", handlers.GetUpcomingJadwal)
+		api.GET("/dashboard/overview", auth.Perlu(auth.IzinDashboard), handlers.GetDashboardOverview)
+		api.GET("/dashboard/activities", auth.Perlu(auth.IzinDashboard), handlers.GetRecentActivities)
+		api.GET("/dashboard/stats", auth.Perlu(auth.IzinDashboard), handlers.GetDashboardStats)
+		api.GET("/dashboard/upcoming-jadwal", auth.Perlu(auth.IzinDashboard), handlers.GetUpcomingJadwal)
 
 		// System routes
-		api.GET("/system/health", handlers.GetSystemHealth)
-		api.GET("/system/config", handlers.GetSystemConfig)
-		api.GET("/system/check-python-backend", handlers.CheckPythonBackend)
-		api.GET("/system/storage-info", handlers.GetStorageInfo)
+		api.GET("/system/health", auth.Perlu(auth.IzinBaca), handlers.GetSystemHealth)
+		api.GET("/system/config", auth.Perlu(auth.IzinBaca), handlers.GetSystemConfig)
+		api.GET("/system/check-python-backend", auth.Perlu(auth.IzinSistem), handlers.CheckPythonBackend)
+		api.GET("/system/storage-info", auth.Perlu(auth.IzinSistem), handlers.GetStorageInfo)
+		api.GET("/system/reconcile-status", auth.Perlu(auth.IzinSistem), handlers.GetStatusRekonsiliasi)
+		api.POST("/system/reconcile-status", auth.Perlu(auth.IzinSistem), handlers.TriggerRekonsiliasiStatus)
 	}
 
 	// Health check
@@ -139,12 +357,18 @@ func main() {
 			"message":   "CLAIRE Backend API",
 			"version":   "1.0.0",
 			"endpoints": map[string]string{
-				"dosen":      "/api/v1/dosen",
-				"jadwal":     "/api/v1/jadwal",
-				"evaluasi":   "/api/v1/evaluasi",
-				"dashboard":  "/api/v1/dashboard",
-				"recording":  "/api/v1/recording",
-				"system":     "/api/v1/system",
+				"dosen":       "/api/v1/dosen",
+				"semester":    "/api/v1/semester",
+				"mata_kuliah": "/api/v1/mata-kuliah",
+				"ruangan":     "/api/v1/ruangan",
+				"jadwal":      "/api/v1/jadwal",
+				"evaluasi":    "/api/v1/evaluasi",
+				"pertemuan":   "/api/v1/pertemuan",
+				"dashboard":   "/api/v1/dashboard",
+				"recording":   "/api/v1/recording",
+				"uploads":     "/api/v1/uploads",
+				"agent":       "/api/v1/agent",
+				"system":      "/api/v1/system",
 			},
 		})
 	})
@@ -155,8 +379,29 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("CLAIRE Backend Server mulai pada port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Gagal memulai server:", err)
+	log.Printf("Zona waktu jadwal: %s", kalender.Default().Lokasi())
+	srv := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
 	}
+	go func() {
+		log.Printf("CLAIRE Backend Server mulai pada port %s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Gagal memulai server:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Menghentikan server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Gagal menghentikan server dengan bersih:", err)
+	}
+
+	// Tunggu goroutine latar belakang selesai
+	statusReconciler.Wait()
+	queue.Wait()
+	log.Println("Server berhenti")
 }
\ No newline at end of file
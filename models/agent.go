@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AwalanWorkerAgen menandai job yang dijalankan agen perekam jarak jauh di
+// kolom worker_id, diikuti ID agen
+const AwalanWorkerAgen = "agent:"
+
+// RecordingAgent adalah agen perekam yang berjalan di komputer ruang kelas.
+// Setiap agen terikat ke satu API key (scope agen) yang dipakainya untuk
+// semua request; ruangan yang dilayani agen ditentukan lewat Ruangan.AgentID.
+type RecordingAgent struct {
+	ID               uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	APIKeyID         uuid.UUID      `gorm:"type:char(36);uniqueIndex;not null" json:"api_key_id"`
+	Nama             string         `gorm:"type:varchar(100);not null" json:"nama"`
+	Hostname         string         `gorm:"type:varchar(255)" json:"hostname"`
+	Versi            string         `gorm:"type:varchar(50)" json:"versi"`
+	Recorder         string         `gorm:"type:varchar(255)" json:"recorder"`
+	IP               string         `gorm:"type:varchar(45)" json:"ip"`
+	StatusKerja      string         `gorm:"type:varchar(20)" json:"status_kerja"` // idle atau recording, dilaporkan agen
+	JumlahJobAktif   int            `gorm:"default:0" json:"jumlah_job_aktif"`
+	TerakhirTerlihat *time.Time     `json:"terakhir_terlihat"`
+	TanggalDibuat    time.Time      `json:"tanggal_dibuat"`
+	TanggalDiupdate  time.Time      `json:"tanggal_diupdate"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Diisi handler untuk response, tidak disimpan
+	Online  bool     `gorm:"-" json:"online"`
+	Ruangan []string `gorm:"-" json:"ruangan"`
+}
+
+func (agent *RecordingAgent) BeforeCreate(tx *gorm.DB) error {
+	agent.ID = uuid.New()
+	agent.TanggalDibuat = time.Now()
+	agent.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (agent *RecordingAgent) BeforeUpdate(tx *gorm.DB) error {
+	agent.TanggalDiupdate = time.Now()
+	return nil
+}
+
+// OnlinePada mengecek apakah agen mengirim heartbeat dalam batas waktu timeout
+func (agent *RecordingAgent) OnlinePada(now time.Time, timeout time.Duration) bool {
+	return agent.TerakhirTerlihat != nil && now.Sub(*agent.TerakhirTerlihat) <= timeout
+}
+
+// WorkerID mengembalikan nilai worker_id untuk job yang diambil agen ini
+func (agent *RecordingAgent) WorkerID() string {
+	return AwalanWorkerAgen + agent.ID.String()
+}
+
+// AgenDariWorkerID mengembalikan ID agen jika job dijalankan agen perekam
+func AgenDariWorkerID(workerID string) (uuid.UUID, bool) {
+	if !strings.HasPrefix(workerID, AwalanWorkerAgen) {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(strings.TrimPrefix(workerID, AwalanWorkerAgen))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKey adalah kunci untuk layanan mesin (service analisis, agen perekam).
+// Kunci asli hanya ditampilkan sekali saat dibuat; database menyimpan hash
+// SHA-256 dan prefix untuk dikenali di daftar.
+type APIKey struct {
+	ID              uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	Nama            string     `gorm:"type:varchar(100);not null" json:"nama"`
+	Prefix          string     `gorm:"type:varchar(20);not null" json:"prefix"`
+	HashKunci       string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	Scope           string     `gorm:"type:varchar(255);not null" json:"scope"`
+	DibuatOleh      string     `gorm:"type:varchar(50)" json:"dibuat_oleh"`
+	KedaluwarsaPada *time.Time `json:"kedaluwarsa_pada"`
+	TerakhirDipakai *time.Time `json:"terakhir_dipakai"`
+	DicabutPada     *time.Time `gorm:"index" json:"dicabut_pada"`
+	TanggalDibuat   time.Time  `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time  `json:"tanggal_diupdate"`
+}
+
+func (key *APIKey) BeforeCreate(tx *gorm.DB) error {
+	key.ID = uuid.New()
+	key.TanggalDibuat = time.Now()
+	key.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (key *APIKey) BeforeUpdate(tx *gorm.DB) error {
+	key.TanggalDiupdate = time.Now()
+	return nil
+}
+
+// PunyaScope mengecek apakah kunci boleh dipakai untuk scope tertentu
+func (key *APIKey) PunyaScope(scope string) bool {
+	for _, s := range strings.Split(key.Scope, ",") {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Berlaku mengecek apakah kunci belum dicabut dan belum kedaluwarsa
+func (key *APIKey) Berlaku(now time.Time) bool {
+	if key.DicabutPada != nil {
+		return false
+	}
+	return key.KedaluwarsaPada == nil || now.Before(*key.KedaluwarsaPada)
+}
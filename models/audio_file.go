@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"CLAIRE/audio"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AudioFile menyimpan metadata teknis file audio di storage: durasi, format,
+// dan level audio. Dipakai operator untuk melihat rekaman yang hening atau
+// clipping sebelum dianalisis. Path sama dengan path_sample_suara dosen atau
+// path_file_audio evaluasi.
+type AudioFile struct {
+	ID              uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	Path            string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"path"`
+	Format          string    `gorm:"type:varchar(10)" json:"format"`
+	FormatAsli      string    `gorm:"type:varchar(10)" json:"format_asli"` // format upload sebelum dikonversi
+	Ukuran          int64     `json:"ukuran"`
+	DurasiDetik     float64   `json:"durasi_detik"`
+	SampleRate      int       `json:"sample_rate"`
+	SampleRateAsli  int       `json:"sample_rate_asli"` // sample rate upload sebelum dikonversi, 0 jika tidak diketahui
+	Channels        int       `json:"channels"`
+	BitsPerSample   int       `json:"bits_per_sample"`
+	RMSDB           *float64  `json:"rms_db"`         // dBFS
+	PeakDB          *float64  `json:"peak_db"`        // dBFS
+	RasioHening     *float64  `json:"rasio_hening"`   // 0..1
+	RasioClipping   *float64  `json:"rasio_clipping"` // 0..1
+	PesanError      string    `gorm:"type:text" json:"pesan_error,omitempty"`
+	TanggalDibuat   time.Time `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time `json:"tanggal_diupdate"`
+}
+
+func (file *AudioFile) BeforeCreate(tx *gorm.DB) error {
+	file.ID = uuid.New()
+	file.TanggalDibuat = time.Now()
+	file.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (file *AudioFile) BeforeUpdate(tx *gorm.DB) error {
+	file.TanggalDiupdate = time.Now()
+	return nil
+}
+
+// SimpanAudioFile mencatat metadata file, menggantikan catatan lama untuk
+// path yang sama
+func SimpanAudioFile(db *gorm.DB, file *AudioFile) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("path = ?", file.Path).Delete(&AudioFile{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(file).Error
+	})
+}
+
+// HapusAudioFile menghapus metadata file-file di paths
+func HapusAudioFile(db *gorm.DB, paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	return db.Where("path IN ?", paths).Delete(&AudioFile{}).Error
+}
+
+// AudioFileDariMetadata membuat catatan AudioFile untuk path dari hasil
+// analisis audio
+func AudioFileDariMetadata(path string, ukuran int64, meta *audio.Metadata) *AudioFile {
+	return &AudioFile{
+		Path:           path,
+		Format:         meta.Format,
+		Ukuran:         ukuran,
+		DurasiDetik:    meta.DurasiDetik,
+		SampleRate:     meta.SampleRate,
+		SampleRateAsli: meta.SampleRate,
+		Channels:       meta.Channels,
+		BitsPerSample:  meta.BitsPerSample,
+		RMSDB:          meta.RMSDB,
+		PeakDB:         meta.PeakDB,
+		RasioHening:    meta.RasioHening,
+		RasioClipping:  meta.RasioClipping,
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditLog mencatat satu operasi create, update atau delete lewat API.
+// Perubahan berisi selisih per field dalam bentuk
+// {"field": {"sebelum": ..., "sesudah": ...}}.
+type AuditLog struct {
+	ID            uuid.UUID       `gorm:"type:char(36);primary_key" json:"id"`
+	Aktor         string          `gorm:"type:varchar(100);index" json:"aktor"`
+	Metode        string          `gorm:"type:varchar(10)" json:"metode"`
+	Route         string          `gorm:"type:varchar(255)" json:"route"`
+	Path          string          `gorm:"type:varchar(255)" json:"path"`
+	IP            string          `gorm:"type:varchar(45)" json:"ip"`
+	JenisEntitas  string          `gorm:"type:varchar(20);not null;index:idx_audit_entitas" json:"jenis_entitas"`
+	EntitasID     string          `gorm:"type:char(36);not null;index:idx_audit_entitas" json:"entitas_id"`
+	Aksi          string          `gorm:"type:varchar(10);not null;index" json:"aksi"`
+	Perubahan     json.RawMessage `gorm:"type:text" json:"perubahan"`
+	TanggalDibuat time.Time       `gorm:"index" json:"tanggal_dibuat"`
+}
+
+func (auditLog *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	auditLog.ID = uuid.New()
+	if auditLog.TanggalDibuat.IsZero() {
+		auditLog.TanggalDibuat = time.Now()
+	}
+	return nil
+}
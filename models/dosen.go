@@ -13,6 +13,7 @@ type Dosen struct {
 	Gelar           string         `gorm:"type:varchar(50)" json:"gelar"`
 	PathSampleSuara string         `gorm:"type:varchar(255)" json:"path_sample_suara"`
 	FolderDosen     string `gorm:"size:255" json:"folder_dosen"`
+	Audio           *AudioFile     `gorm:"foreignKey:PathSampleSuara;references:Path;constraint:-" json:"audio,omitempty"`
 	TanggalDibuat   time.Time      `json:"tanggal_dibuat"`
 	TanggalDiupdate time.Time      `json:"tanggal_diupdate"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
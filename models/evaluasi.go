@@ -11,11 +11,14 @@ type Evaluasi struct {
 	ID                   uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
 	JadwalID             uuid.UUID      `gorm:"type:char(36);not null" json:"jadwal_id"`
 	Jadwal               Jadwal         `gorm:"foreignKey:JadwalID" json:"jadwal"`
+	PertemuanID          *uuid.UUID     `gorm:"type:char(36);index" json:"pertemuan_id"`
+	Pertemuan            *Pertemuan     `gorm:"foreignKey:PertemuanID" json:"pertemuan,omitempty"`
 	KepercayaanPembicara float64        `gorm:"type:decimal(5,4)" json:"kepercayaan_pembicara"`
 	TeksTranskripsi      string         `gorm:"type:text" json:"teks_transkripsi"`
 	Rangkuman            string         `gorm:"type:text" json:"rangkuman"`
 	SkorEfektivitas      float64        `gorm:"type:decimal(3,2)" json:"skor_efektivitas"`
 	PathFileAudio        string         `gorm:"type:varchar(255)" json:"path_file_audio"`
+	Audio                *AudioFile     `gorm:"foreignKey:PathFileAudio;references:Path;constraint:-" json:"audio,omitempty"`
 	WaktuPemrosesan      float64        `json:"waktu_pemrosesan"`
 	TanggalDibuat        time.Time      `json:"tanggal_dibuat"`
 	TanggalDiupdate      time.Time      `json:"tanggal_diupdate"`
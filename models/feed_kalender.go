@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FeedKalender adalah token langganan satu feed kalender .ics untuk aplikasi
+// kalender yang tidak bisa login. Token hanya bisa membaca feed di Path dan
+// bisa dicabut kapan saja. Token asli hanya ditampilkan sekali saat dibuat;
+// database menyimpan hash SHA-256 dan prefix untuk dikenali di daftar.
+type FeedKalender struct {
+	ID              uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	UserID          uuid.UUID  `gorm:"type:char(36);not null;index" json:"user_id"`
+	Nama            string     `gorm:"type:varchar(100)" json:"nama"`
+	Path            string     `gorm:"type:varchar(255);not null" json:"path"`
+	Prefix          string     `gorm:"type:varchar(20);not null" json:"prefix"`
+	HashToken       string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	TerakhirDipakai *time.Time `json:"terakhir_dipakai"`
+	DicabutPada     *time.Time `gorm:"index" json:"dicabut_pada"`
+	TanggalDibuat   time.Time  `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time  `json:"tanggal_diupdate"`
+}
+
+func (feed *FeedKalender) BeforeCreate(tx *gorm.DB) error {
+	feed.ID = uuid.New()
+	feed.TanggalDibuat = time.Now()
+	feed.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (feed *FeedKalender) BeforeUpdate(tx *gorm.DB) error {
+	feed.TanggalDiupdate = time.Now()
+	return nil
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"CLAIRE/kalender"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -10,14 +12,28 @@ import (
 type Jadwal struct {
 	ID              uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
 	NamaMatkul      string         `gorm:"type:varchar(100);not null" json:"nama_matkul"`
+	MataKuliahID    *uuid.UUID     `gorm:"type:char(36);index" json:"mata_kuliah_id"`
+	MataKuliah      *MataKuliah    `gorm:"foreignKey:MataKuliahID" json:"mata_kuliah,omitempty"`
+	SemesterID      *uuid.UUID     `gorm:"type:char(36);index" json:"semester_id"`
+	Semester        *Semester      `gorm:"foreignKey:SemesterID" json:"semester,omitempty"`
 	DosenID         uuid.UUID      `gorm:"type:char(36);not null" json:"dosen_id"`
 	Dosen           Dosen          `gorm:"foreignKey:DosenID" json:"dosen"`
 	Hari            string         `gorm:"type:varchar(10);not null" json:"hari"`
 	WaktuMulai      string         `gorm:"type:varchar(5);not null" json:"waktu_mulai"`
 	WaktuSelesai    string         `gorm:"type:varchar(5);not null" json:"waktu_selesai"`
 	Ruangan         string         `gorm:"type:varchar(50)" json:"ruangan"`
+	RuanganID       *uuid.UUID     `gorm:"type:char(36);index" json:"ruangan_id"`
+	DetailRuangan   *Ruangan       `gorm:"foreignKey:RuanganID" json:"detail_ruangan,omitempty"`
 	Status          string         `gorm:"type:varchar(20);default:'terjadwal'" json:"status"`
 	SedangRekam     bool           `gorm:"default:false" json:"sedang_rekam"`
+
+	// Kebijakan rekaman per jadwal, nil berarti memakai default global
+	RekamJumlahSesi  *int  `json:"rekam_jumlah_sesi"`
+	RekamDurasiSesi  *int  `json:"rekam_durasi_sesi"`
+	RekamOffsetMulai *int  `json:"rekam_offset_mulai"`
+	RekamJedaSesi    *int  `json:"rekam_jeda_sesi"`
+	RekamPenuh       *bool `json:"rekam_penuh"`
+
 	TanggalDibuat   time.Time      `json:"tanggal_dibuat"`
 	TanggalDiupdate time.Time      `json:"tanggal_diupdate"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
@@ -35,56 +51,53 @@ func (jadwal *Jadwal) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
+// StatusPada menghitung status jadwal minggu ini pada waktu now
+func (j *Jadwal) StatusPada(now time.Time) string {
+	if j.IsOngoingAt(now) {
+		return JadwalAktif
+	} else if j.IsCompletedAt(now) {
+		return JadwalSelesai
+	}
+	return JadwalTerjadwal
+}
+
 // Method untuk mengecek apakah jadwal sedang berlangsung
 func (j *Jadwal) IsOngoing() bool {
-	now := time.Now()
-	
-	// Konversi hari ke format yang sama
-	daysMap := map[string]string{
-		"Monday":    "SENIN",
-		"Tuesday":   "SELASA", 
-		"Wednesday": "RABU",
-		"Thursday":  "KAMIS",
-		"Friday":    "JUMAT",
-		"Saturday":  "SABTU",
-		"Sunday":    "MINGGU",
-	}
-	
-	currentDay := daysMap[now.Format("Monday")]
-	if currentDay != j.Hari {
+	return j.IsOngoingAt(kalender.Default().Sekarang())
+}
+
+// IsOngoingAt mengecek apakah jadwal berlangsung pada waktu now, dihitung
+// di zona waktu kampus
+func (j *Jadwal) IsOngoingAt(now time.Time) bool {
+	kal := kalender.Default()
+	if kal.Hari(now) != j.Hari {
 		return false
 	}
-	
-	currentTime := now.Format("15:04")
+
+	currentTime := kal.Jam(now)
 	return currentTime >= j.WaktuMulai && currentTime <= j.WaktuSelesai
 }
 
 // Method untuk mengecek apakah jadwal sudah selesai
 func (j *Jadwal) IsCompleted() bool {
-	now := time.Now()
-	
-	daysMap := map[string]string{
-		"Monday":    "SENIN",
-		"Tuesday":   "SELASA",
-		"Wednesday": "RABU", 
-		"Thursday":  "KAMIS",
-		"Friday":    "JUMAT",
-		"Saturday":  "SABTU",
-		"Sunday":    "MINGGU",
+	return j.IsCompletedAt(kalender.Default().Sekarang())
+}
+
+// IsCompletedAt mengecek apakah jadwal minggu ini sudah selesai pada waktu
+// now. Minggu kuliah dihitung dari Senin sampai Minggu.
+func (j *Jadwal) IsCompletedAt(now time.Time) bool {
+	kal := kalender.Default()
+	weekday, ok := kalender.Weekday(j.Hari)
+	if !ok {
+		return false
 	}
-	
-	currentDay := daysMap[now.Format("Monday")]
-	currentTime := now.Format("15:04")
-	
-	// Jika hari sudah lewat atau waktu sudah lewat
+
+	currentDay := kal.Hari(now)
 	if currentDay != j.Hari {
 		// Cek urutan hari
-		dayOrder := map[string]int{
-			"SENIN": 1, "SELASA": 2, "RABU": 3, "KAMIS": 4, 
-			"JUMAT": 5, "SABTU": 6, "MINGGU": 7,
-		}
-		return dayOrder[currentDay] > dayOrder[j.Hari]
+		today, _ := kalender.Weekday(currentDay)
+		return kalender.UrutanHari(today) > kalender.UrutanHari(weekday)
 	}
-	
-	return currentTime > j.WaktuSelesai
-}
\ No newline at end of file
+
+	return kal.Jam(now) > j.WaktuSelesai
+}
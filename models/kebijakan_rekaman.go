@@ -0,0 +1,205 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// KebijakanRekaman mengatur berapa kali dan berapa lama sebuah kelas direkam
+type KebijakanRekaman struct {
+	JumlahSesi  int  `json:"jumlah_sesi"`
+	DurasiSesi  int  `json:"durasi_sesi"`  // detik
+	OffsetMulai int  `json:"offset_mulai"` // menit setelah kelas dimulai
+	JedaSesi    int  `json:"jeda_sesi"`    // detik di antara sesi
+	RekamPenuh  bool `json:"rekam_penuh"`  // rekam dari offset sampai kelas selesai
+}
+
+// SesiTerjadwal adalah satu sesi rekaman hasil perhitungan kebijakan
+type SesiTerjadwal struct {
+	NomorSesi  int    `json:"nomor_sesi"`
+	WaktuMulai string `json:"waktu_mulai"`
+	DurasiSesi int    `json:"durasi_sesi"` // detik
+}
+
+// Batas kebijakan rekaman. Nilai di atas batas ini tidak masuk akal untuk
+// satu kelas dan bisa membuat perhitungan sesi menghabiskan memori.
+const (
+	MaksJumlahSesi  = 100
+	MaksDurasiSesi  = 4 * 60 * 60 // detik
+	MaksJedaSesi    = 4 * 60 * 60 // detik
+	MaksOffsetMulai = 24 * 60     // menit
+)
+
+// Kebijakan rekaman global, diisi dari config saat startup lewat
+// SetDefaultKebijakanRekaman. Nilai awalnya sama dengan default config.
+var (
+	kebijakanDefault = KebijakanRekaman{
+		JumlahSesi:  5,
+		DurasiSesi:  60,
+		OffsetMulai: 10,
+	}
+	kebijakanDefaultMu sync.RWMutex
+)
+
+// DefaultKebijakanRekaman mengembalikan kebijakan rekaman global
+func DefaultKebijakanRekaman() KebijakanRekaman {
+	kebijakanDefaultMu.RLock()
+	defer kebijakanDefaultMu.RUnlock()
+	return kebijakanDefault
+}
+
+// SetDefaultKebijakanRekaman mengganti kebijakan rekaman global
+func SetDefaultKebijakanRekaman(kebijakan KebijakanRekaman) {
+	kebijakanDefaultMu.Lock()
+	kebijakanDefault = kebijakan
+	kebijakanDefaultMu.Unlock()
+}
+
+// KebijakanRekaman menggabungkan pengaturan rekaman jadwal dengan default
+// global. Field jadwal yang kosong memakai nilai default.
+func (j *Jadwal) KebijakanRekaman() KebijakanRekaman {
+	kebijakan := DefaultKebijakanRekaman()
+	if j.RekamJumlahSesi != nil {
+		kebijakan.JumlahSesi = *j.RekamJumlahSesi
+	}
+	if j.RekamDurasiSesi != nil {
+		kebijakan.DurasiSesi = *j.RekamDurasiSesi
+	}
+	if j.RekamOffsetMulai != nil {
+		kebijakan.OffsetMulai = *j.RekamOffsetMulai
+	}
+	if j.RekamJedaSesi != nil {
+		kebijakan.JedaSesi = *j.RekamJedaSesi
+	}
+	if j.RekamPenuh != nil {
+		kebijakan.RekamPenuh = *j.RekamPenuh
+	}
+	return kebijakan
+}
+
+// Validate memeriksa apakah kebijakan masuk akal untuk jam kelas tertentu.
+// Semua sesi, termasuk sesi terakhir, harus selesai sebelum jam selesai
+// kelas.
+func (k KebijakanRekaman) Validate(waktuMulai string, waktuSelesai string) error {
+	if !k.RekamPenuh {
+		if k.JumlahSesi <= 0 {
+			return fmt.Errorf("jumlah sesi rekaman minimal 1")
+		}
+		if k.DurasiSesi <= 0 {
+			return fmt.Errorf("durasi sesi rekaman minimal 1 detik")
+		}
+	}
+	if err := k.cekBatas(); err != nil {
+		return err
+	}
+
+	mulai, selesai, err := parseJamKelas(waktuMulai, waktuSelesai)
+	if err != nil {
+		return err
+	}
+	awal := mulai.Add(time.Duration(k.OffsetMulai) * time.Minute)
+	if !awal.Before(selesai) {
+		return fmt.Errorf("offset rekaman melewati jam selesai kelas")
+	}
+	if k.RekamPenuh {
+		return nil
+	}
+
+	langkah := time.Duration(k.DurasiSesi+k.JedaSesi) * time.Second
+	akhir := awal.Add(time.Duration(k.JumlahSesi-1)*langkah + time.Duration(k.DurasiSesi)*time.Second)
+	if akhir.After(selesai) {
+		return fmt.Errorf("sesi rekaman terakhir baru selesai %s setelah jam selesai kelas %s", akhir.Sub(selesai), waktuSelesai)
+	}
+	return nil
+}
+
+// cekBatas memeriksa nilai kebijakan terhadap batas minimum dan maksimum
+func (k KebijakanRekaman) cekBatas() error {
+	if k.OffsetMulai < 0 {
+		return fmt.Errorf("offset mulai rekaman tidak boleh negatif")
+	}
+	if k.OffsetMulai > MaksOffsetMulai {
+		return fmt.Errorf("offset mulai rekaman maksimal %d menit", MaksOffsetMulai)
+	}
+	if k.JedaSesi < 0 {
+		return fmt.Errorf("jeda antar sesi tidak boleh negatif")
+	}
+	if k.JedaSesi > MaksJedaSesi {
+		return fmt.Errorf("jeda antar sesi maksimal %d detik", MaksJedaSesi)
+	}
+	if k.RekamPenuh {
+		return nil
+	}
+	if k.JumlahSesi > MaksJumlahSesi {
+		return fmt.Errorf("jumlah sesi rekaman maksimal %d", MaksJumlahSesi)
+	}
+	if k.DurasiSesi > MaksDurasiSesi {
+		return fmt.Errorf("durasi sesi rekaman maksimal %d detik", MaksDurasiSesi)
+	}
+	return nil
+}
+
+// parseJamKelas membaca jam mulai dan selesai kelas format HH:MM
+func parseJamKelas(waktuMulai string, waktuSelesai string) (time.Time, time.Time, error) {
+	mulai, err := time.Parse("15:04", waktuMulai)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("waktu mulai tidak valid: %s", waktuMulai)
+	}
+	selesai, err := time.Parse("15:04", waktuSelesai)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("waktu selesai tidak valid: %s", waktuSelesai)
+	}
+	return mulai, selesai, nil
+}
+
+// Jadwalkan menghitung waktu mulai dan durasi setiap sesi rekaman.
+// Kebijakan di luar batas (misalnya data lama di database) ditolak.
+func (k KebijakanRekaman) Jadwalkan(waktuMulai string, waktuSelesai string) ([]SesiTerjadwal, error) {
+	if err := k.cekBatas(); err != nil {
+		return nil, err
+	}
+	mulai, selesai, err := parseJamKelas(waktuMulai, waktuSelesai)
+	if err != nil {
+		return nil, err
+	}
+
+	awal := mulai.Add(time.Duration(k.OffsetMulai) * time.Minute)
+
+	if k.RekamPenuh {
+		durasi := int(selesai.Sub(awal).Seconds())
+		if durasi <= 0 {
+			return nil, nil
+		}
+		return []SesiTerjadwal{{NomorSesi: 1, WaktuMulai: formatJam(awal), DurasiSesi: durasi}}, nil
+	}
+
+	sesi := make([]SesiTerjadwal, 0, k.JumlahSesi)
+	langkah := time.Duration(k.DurasiSesi+k.JedaSesi) * time.Second
+	for i := 0; i < k.JumlahSesi; i++ {
+		sesi = append(sesi, SesiTerjadwal{
+			NomorSesi:  i + 1,
+			WaktuMulai: formatJam(awal.Add(time.Duration(i) * langkah)),
+			DurasiSesi: k.DurasiSesi,
+		})
+	}
+	return sesi, nil
+}
+
+// TotalDurasi mengembalikan total lama rekaman dalam detik
+func (k KebijakanRekaman) TotalDurasi(waktuMulai string, waktuSelesai string) int {
+	sesi, _ := k.Jadwalkan(waktuMulai, waktuSelesai)
+	total := 0
+	for _, s := range sesi {
+		total += s.DurasiSesi
+	}
+	return total
+}
+
+// formatJam menulis jam sebagai HH:MM, atau HH:MM:SS jika ada detiknya
+func formatJam(t time.Time) string {
+	if t.Second() != 0 {
+		return t.Format("15:04:05")
+	}
+	return t.Format("15:04")
+}
@@ -0,0 +1,63 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+
+	"CLAIRE/models"
+)
+
+func TestKebijakanRekamanValidate(t *testing.T) {
+	tests := []struct {
+		nama      string
+		kebijakan models.KebijakanRekaman
+		selesai   string
+		valid     bool
+	}{
+		{"sesi pas sampai jam selesai", models.KebijakanRekaman{JumlahSesi: 3, DurasiSesi: 20 * 60, OffsetMulai: 30, JedaSesi: 5 * 60}, "09:40", true},
+		{"sesi terakhir melewati jam selesai", models.KebijakanRekaman{JumlahSesi: 3, DurasiSesi: 20 * 60, OffsetMulai: 30, JedaSesi: 5 * 60}, "09:39", false},
+		{"sesi pertama saja masuk", models.KebijakanRekaman{JumlahSesi: 10, DurasiSesi: 60 * 60}, "10:00", false},
+		{"offset melewati jam selesai", models.KebijakanRekaman{JumlahSesi: 1, DurasiSesi: 60, OffsetMulai: 120}, "10:00", false},
+		{"offset melewati tengah malam", models.KebijakanRekaman{JumlahSesi: 1, DurasiSesi: 60, OffsetMulai: 16 * 60}, "10:00", false},
+		{"rekam penuh", models.KebijakanRekaman{RekamPenuh: true, OffsetMulai: 10}, "10:00", true},
+		{"rekam penuh offset lewat", models.KebijakanRekaman{RekamPenuh: true, OffsetMulai: 120}, "10:00", false},
+		{"jumlah sesi nol", models.KebijakanRekaman{JumlahSesi: 0, DurasiSesi: 60}, "10:00", false},
+		{"durasi nol", models.KebijakanRekaman{JumlahSesi: 1, DurasiSesi: 0}, "10:00", false},
+		{"jeda negatif", models.KebijakanRekaman{JumlahSesi: 1, DurasiSesi: 60, JedaSesi: -1}, "10:00", false},
+		{"jumlah sesi di atas batas", models.KebijakanRekaman{JumlahSesi: models.MaksJumlahSesi + 1, DurasiSesi: 1}, "23:59", false},
+		{"jumlah sesi sangat besar", models.KebijakanRekaman{JumlahSesi: 1 << 40, DurasiSesi: 1}, "23:59", false},
+		{"durasi di atas batas", models.KebijakanRekaman{JumlahSesi: 1, DurasiSesi: models.MaksDurasiSesi + 1}, "23:59", false},
+		{"offset di atas batas", models.KebijakanRekaman{JumlahSesi: 1, DurasiSesi: 60, OffsetMulai: models.MaksOffsetMulai + 1}, "23:59", false},
+	}
+	for _, tt := range tests {
+		err := tt.kebijakan.Validate("08:00", tt.selesai)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: Validate = %v, valid %v", tt.nama, err, tt.valid)
+		}
+	}
+
+	if err := (models.KebijakanRekaman{JumlahSesi: 1, DurasiSesi: 60}).Validate("8 pagi", "10:00"); err == nil {
+		t.Error("waktu mulai tidak valid harus gagal")
+	}
+}
+
+func TestKebijakanRekamanJadwalkan(t *testing.T) {
+	kebijakan := models.KebijakanRekaman{JumlahSesi: 3, DurasiSesi: 90, OffsetMulai: 10, JedaSesi: 30}
+	sesi, err := kebijakan.Jadwalkan("08:00", "10:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.SesiTerjadwal{
+		{NomorSesi: 1, WaktuMulai: "08:10", DurasiSesi: 90},
+		{NomorSesi: 2, WaktuMulai: "08:12", DurasiSesi: 90},
+		{NomorSesi: 3, WaktuMulai: "08:14", DurasiSesi: 90},
+	}
+	if !reflect.DeepEqual(sesi, want) {
+		t.Errorf("Jadwalkan = %+v, want %+v", sesi, want)
+	}
+
+	// Kebijakan di luar batas ditolak sebelum sesi dialokasikan
+	if _, err := (models.KebijakanRekaman{JumlahSesi: 1 << 40, DurasiSesi: 1}).Jadwalkan("08:00", "10:00"); err == nil {
+		t.Error("Jadwalkan dengan jumlah sesi di atas batas harus gagal")
+	}
+}
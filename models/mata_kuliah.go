@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MataKuliah adalah katalog mata kuliah. Jadwal merujuk ke katalog ini
+// sehingga statistik tidak terpecah karena penulisan nama yang berbeda.
+type MataKuliah struct {
+	ID              uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	Kode            string         `gorm:"type:varchar(20);not null;uniqueIndex" json:"kode"`
+	Nama            string         `gorm:"type:varchar(100);not null" json:"nama"`
+	SKS             int            `gorm:"default:0" json:"sks"`
+	ProgramStudi    string         `gorm:"type:varchar(100)" json:"program_studi"`
+	TanggalDibuat   time.Time      `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time      `json:"tanggal_diupdate"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (matkul *MataKuliah) BeforeCreate(tx *gorm.DB) error {
+	matkul.ID = uuid.New()
+	matkul.TanggalDibuat = time.Now()
+	matkul.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (matkul *MataKuliah) BeforeUpdate(tx *gorm.DB) error {
+	matkul.TanggalDiupdate = time.Now()
+	return nil
+}
+
+// NormalisasiNamaMatkul menyamakan penulisan nama mata kuliah: huruf kecil
+// dan spasi berlebih dihapus
+func NormalisasiNamaMatkul(nama string) string {
+	return strings.ToLower(strings.Join(strings.Fields(nama), " "))
+}
+
+// maksPercobaanKode membatasi pembuatan ulang kode otomatis saat kode yang
+// dipilih ternyata baru saja dipakai request lain
+const maksPercobaanKode = 5
+
+// CariAtauBuatMataKuliah mencari mata kuliah dengan nama yang sama setelah
+// dinormalisasi, atau membuat entri katalog baru dengan kode otomatis
+func CariAtauBuatMataKuliah(tx *gorm.DB, nama string) (*MataKuliah, error) {
+	kunci := NormalisasiNamaMatkul(nama)
+	if kunci == "" {
+		return nil, errors.New("nama mata kuliah kosong")
+	}
+
+	var matkul MataKuliah
+	err := tx.Where("LOWER(nama) = ?", kunci).First(&matkul).Error
+	if err == nil {
+		return &matkul, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Dua request bisa memilih kode yang sama bersamaan. Yang kalah mendapat
+	// duplicate key dan mencoba nomor berikutnya; nomor dinaikkan sendiri
+	// karena snapshot transaksi belum tentu melihat kode yang baru dibuat.
+	var n int64
+	for percobaan := 0; percobaan < maksPercobaanKode; percobaan++ {
+		var kode string
+		kode, n, err = kodeMataKuliahBaru(tx, n+1)
+		if err != nil {
+			return nil, err
+		}
+		matkul = MataKuliah{
+			Kode: kode,
+			Nama: strings.Join(strings.Fields(nama), " "),
+		}
+		err = tx.Transaction(func(sp *gorm.DB) error {
+			return sp.Create(&matkul).Error
+		})
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &matkul, nil
+	}
+	return nil, fmt.Errorf("gagal membuat kode mata kuliah unik setelah %d percobaan", maksPercobaanKode)
+}
+
+// kodeMataKuliahBaru membuat kode MKxxxx yang belum dipakai, mulai dari
+// nomor minimal. Nomor kode yang dipilih ikut dikembalikan.
+func kodeMataKuliahBaru(tx *gorm.DB, minimal int64) (string, int64, error) {
+	var jumlah int64
+	if err := tx.Unscoped().Model(&MataKuliah{}).Count(&jumlah).Error; err != nil {
+		return "", 0, err
+	}
+	n := jumlah + 1
+	if n < minimal {
+		n = minimal
+	}
+	for ; ; n++ {
+		kode := fmt.Sprintf("MK%04d", n)
+		var ada int64
+		if err := tx.Unscoped().Model(&MataKuliah{}).Where("kode = ?", kode).Count(&ada).Error; err != nil {
+			return "", 0, err
+		}
+		if ada == 0 {
+			return kode, n, nil
+		}
+	}
+}
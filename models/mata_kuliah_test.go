@@ -0,0 +1,58 @@
+package models_test
+
+import (
+	"testing"
+
+	"CLAIRE/models"
+
+	"gorm.io/gorm"
+)
+
+func TestCariAtauBuatMataKuliahNamaSama(t *testing.T) {
+	db := dbUji(t)
+	ada := models.MataKuliah{Kode: "IF101", Nama: "Struktur Data"}
+	if err := db.Create(&ada).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	matkul, err := models.CariAtauBuatMataKuliah(db, "  STRUKTUR   data ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matkul.ID != ada.ID {
+		t.Errorf("ID = %s, want %s", matkul.ID, ada.ID)
+	}
+}
+
+func TestCariAtauBuatMataKuliahKodeBalapan(t *testing.T) {
+	db := dbUji(t)
+
+	// Request lain memakai kode MK0001 tepat setelah kode itu diperiksa,
+	// seperti dua import yang berjalan bersamaan
+	sudah := false
+	err := db.Callback().Query().After("gorm:query").Register("uji:balapan", func(tx *gorm.DB) {
+		if sudah || len(tx.Statement.Vars) == 0 || tx.Statement.Vars[0] != "MK0001" {
+			return
+		}
+		sudah = true
+		tx.Session(&gorm.Session{NewDB: true}).Exec(
+			"INSERT INTO mata_kuliahs (id, kode, nama) VALUES (?, ?, ?)",
+			"00000000-0000-0000-0000-000000000001", "MK0001", "Basis Data")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matkul, err := models.CariAtauBuatMataKuliah(db, "Algoritma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matkul.Kode != "MK0002" {
+		t.Errorf("kode = %s, want MK0002", matkul.Kode)
+	}
+	var jumlah int64
+	db.Model(&models.MataKuliah{}).Count(&jumlah)
+	if jumlah != 2 {
+		t.Errorf("jumlah mata kuliah = %d, want 2", jumlah)
+	}
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"time"
+
+	"CLAIRE/kalender"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status pertemuan
+const (
+	PertemuanTerjadwal  = "terjadwal"
+	PertemuanAktif      = "aktif"
+	PertemuanSelesai    = "selesai"
+	PertemuanDibatalkan = "dibatalkan"
+)
+
+// Pertemuan adalah satu pertemuan kelas pada tanggal tertentu, dibuat dari
+// template mingguan Jadwal. Pertemuan bisa dijadwal ulang tanpa mengubah
+// Jadwal-nya.
+type Pertemuan struct {
+	ID              uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	JadwalID        uuid.UUID      `gorm:"type:char(36);not null;index" json:"jadwal_id"`
+	Jadwal          Jadwal         `gorm:"foreignKey:JadwalID" json:"jadwal"`
+	PertemuanKe     int            `gorm:"not null" json:"pertemuan_ke"`
+	Tanggal         time.Time      `gorm:"type:date;not null;index" json:"tanggal"`
+	TanggalAsli     time.Time      `gorm:"type:date;not null" json:"tanggal_asli"`
+	WaktuMulai      string         `gorm:"type:varchar(5);not null" json:"waktu_mulai"`
+	WaktuSelesai    string         `gorm:"type:varchar(5);not null" json:"waktu_selesai"`
+	Ruangan         string         `gorm:"type:varchar(50)" json:"ruangan"`
+	Status          string         `gorm:"type:varchar(20);default:'terjadwal'" json:"status"`
+	DijadwalUlang   bool           `gorm:"default:false" json:"dijadwal_ulang"`
+	Keterangan      string         `gorm:"type:varchar(255)" json:"keterangan"`
+	TanggalDibuat   time.Time      `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time      `json:"tanggal_diupdate"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// HariLibur adalah tanggal libur yang dilewati saat membuat pertemuan
+type HariLibur struct {
+	ID            uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	Tanggal       time.Time `gorm:"type:date;not null;uniqueIndex" json:"tanggal"`
+	Keterangan    string    `gorm:"type:varchar(100)" json:"keterangan"`
+	TanggalDibuat time.Time `json:"tanggal_dibuat"`
+}
+
+func (pertemuan *Pertemuan) BeforeCreate(tx *gorm.DB) error {
+	pertemuan.ID = uuid.New()
+	pertemuan.TanggalDibuat = time.Now()
+	pertemuan.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (pertemuan *Pertemuan) BeforeUpdate(tx *gorm.DB) error {
+	pertemuan.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (libur *HariLibur) BeforeCreate(tx *gorm.DB) error {
+	libur.ID = uuid.New()
+	libur.TanggalDibuat = time.Now()
+	return nil
+}
+
+// Mulai mengembalikan waktu mulai pertemuan sebagai time.Time
+func (p *Pertemuan) Mulai() time.Time {
+	return gabungTanggalJam(p.Tanggal, p.WaktuMulai)
+}
+
+// Selesai mengembalikan waktu selesai pertemuan sebagai time.Time
+func (p *Pertemuan) Selesai() time.Time {
+	return gabungTanggalJam(p.Tanggal, p.WaktuSelesai)
+}
+
+// StatusPada menghitung status pertemuan pada waktu now. Pertemuan yang
+// dibatalkan atau sudah selesai tidak berubah lagi.
+func (p *Pertemuan) StatusPada(now time.Time) string {
+	if p.Status == PertemuanDibatalkan || p.Status == PertemuanSelesai {
+		return p.Status
+	}
+	if now.After(p.Selesai()) {
+		return PertemuanSelesai
+	}
+	if !now.Before(p.Mulai()) {
+		return PertemuanAktif
+	}
+	return PertemuanTerjadwal
+}
+
+// CariPertemuanID mencari pertemuan jadwal pada tanggal kalender tertentu
+// (lihat kalender.Tanggal). Mengembalikan nil jika jadwal belum punya
+// pertemuan di tanggal itu.
+func CariPertemuanID(db *gorm.DB, jadwalID uuid.UUID, tanggal time.Time) *uuid.UUID {
+	awal := time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), 0, 0, 0, 0, time.Local)
+
+	var pertemuan Pertemuan
+	err := db.Where("jadwal_id = ? AND tanggal >= ? AND tanggal < ? AND status <> ?",
+		jadwalID, awal, awal.AddDate(0, 0, 1), PertemuanDibatalkan).
+		First(&pertemuan).Error
+	if err != nil {
+		return nil
+	}
+	return &pertemuan.ID
+}
+
+// HariLiburPada mengecek apakah tanggal kalender tertentu (lihat
+// kalender.Tanggal) termasuk hari libur
+func HariLiburPada(db *gorm.DB, tanggal time.Time) bool {
+	awal := time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), 0, 0, 0, 0, time.Local)
+
+	var jumlah int64
+	db.Model(&HariLibur{}).Where("tanggal >= ? AND tanggal < ?", awal, awal.AddDate(0, 0, 1)).Count(&jumlah)
+	return jumlah > 0
+}
+
+// gabungTanggalJam menggabungkan tanggal pertemuan dengan jam HH:MM di zona
+// waktu kampus
+func gabungTanggalJam(tanggal time.Time, jam string) time.Time {
+	t, err := kalender.Default().Pada(tanggal, jam)
+	if err != nil {
+		return tanggal
+	}
+	return t
+}
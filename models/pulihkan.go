@@ -0,0 +1,25 @@
+package models
+
+import "gorm.io/gorm"
+
+// BuatAtauPulihkan menyimpan baris baru. Index unik tetap berlaku untuk baris
+// yang sudah dihapus (soft delete), jadi jika kunci unik masih dipegang baris
+// terhapus, baris itu dihidupkan kembali dengan isi baru dan ID lamanya.
+func BuatAtauPulihkan(tx *gorm.DB, baris interface{}, kunci string, args ...interface{}) error {
+	var terhapus []string
+	err := tx.Unscoped().Model(baris).Where("deleted_at IS NOT NULL").Where(kunci, args...).
+		Limit(1).Pluck("id", &terhapus).Error
+	if err != nil {
+		return err
+	}
+	if len(terhapus) == 0 {
+		return tx.Create(baris).Error
+	}
+
+	err = tx.Unscoped().Model(baris).Where("id = ?", terhapus[0]).
+		Select("*").Omit("id", "tanggal_dibuat").Updates(baris).Error
+	if err != nil {
+		return err
+	}
+	return tx.First(baris, "id = ?", terhapus[0]).Error
+}
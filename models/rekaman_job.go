@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status job rekaman
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled" // dihentikan manual sebelum selesai
+)
+
+// Status sesi rekaman di dalam sebuah job
+const (
+	SesiStatusPending   = "pending"   // belum direkam
+	SesiStatusRecording = "recording" // ffmpeg sedang berjalan
+	SesiStatusUploaded  = "uploaded"  // file tersimpan, menunggu analisis
+	SesiStatusAnalyzed  = "analyzed"  // hasil analisis tersimpan di Evaluasi
+	SesiStatusFailed    = "failed"
+	SesiStatusCancelled = "cancelled" // tidak direkam karena job dihentikan
+)
+
+// RekamanJob adalah satu kali proses rekaman terjadwal untuk sebuah jadwal
+type RekamanJob struct {
+	ID              uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	JadwalID        uuid.UUID      `gorm:"type:char(36);not null;index" json:"jadwal_id"`
+	Jadwal          Jadwal         `gorm:"foreignKey:JadwalID" json:"jadwal,omitempty"`
+	Status          string         `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	TotalSesi       int            `gorm:"not null" json:"total_sesi"`
+	DurasiSesi      int            `gorm:"not null" json:"durasi_sesi"` // detik
+	JedaSesi        int            `gorm:"default:0" json:"jeda_sesi"`  // detik
+	Percobaan       int            `gorm:"default:0" json:"percobaan"`
+	WorkerID        string         `gorm:"type:varchar(100)" json:"worker_id"`
+	StopDiminta     bool           `gorm:"default:false" json:"stop_diminta"` // untuk job di agen perekam
+	PesanError      string         `gorm:"type:text" json:"pesan_error"`
+	WaktuMulai      *time.Time     `json:"waktu_mulai"`
+	WaktuSelesai    *time.Time     `json:"waktu_selesai"`
+	Sesi            []RekamanSesi  `gorm:"foreignKey:JobID" json:"sesi"`
+	TanggalDibuat   time.Time      `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time      `json:"tanggal_diupdate"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// RekamanSesi adalah satu potongan rekaman di dalam RekamanJob
+type RekamanSesi struct {
+	ID              uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	JobID           uuid.UUID  `gorm:"type:char(36);not null;index" json:"job_id"`
+	NomorSesi       int        `gorm:"not null" json:"nomor_sesi"`
+	Status          string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	PathFileAudio   string     `gorm:"type:varchar(255)" json:"path_file_audio"` // key storage
+	BytesDiterima   int64      `gorm:"default:0" json:"bytes_diterima"`          // progres upload dari agen
+	EvaluasiID      *uuid.UUID `gorm:"type:char(36)" json:"evaluasi_id"`
+	PesanError      string     `gorm:"type:text" json:"pesan_error"`
+	WaktuMulai      *time.Time `json:"waktu_mulai"`
+	WaktuSelesai    *time.Time `json:"waktu_selesai"`
+	TanggalDibuat   time.Time  `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time  `json:"tanggal_diupdate"`
+}
+
+func (job *RekamanJob) BeforeCreate(tx *gorm.DB) error {
+	job.ID = uuid.New()
+	job.TanggalDibuat = time.Now()
+	job.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (job *RekamanJob) BeforeUpdate(tx *gorm.DB) error {
+	job.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (sesi *RekamanSesi) BeforeCreate(tx *gorm.DB) error {
+	sesi.ID = uuid.New()
+	sesi.TanggalDibuat = time.Now()
+	sesi.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (sesi *RekamanSesi) BeforeUpdate(tx *gorm.DB) error {
+	sesi.TanggalDiupdate = time.Now()
+	return nil
+}
+
+// IsActive mengecek apakah job masih menunggu atau sedang berjalan
+func (job *RekamanJob) IsActive() bool {
+	return job.Status == JobStatusPending || job.Status == JobStatusRunning
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Ruangan adalah ruang kelas beserta perangkat perekamnya. Backend dan
+// device kosong berarti memakai perekam dari konfigurasi server.
+type Ruangan struct {
+	ID              uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	Kode            string         `gorm:"type:varchar(50);not null;uniqueIndex" json:"kode"`
+	Nama            string         `gorm:"type:varchar(100)" json:"nama"`
+	Gedung          string         `gorm:"type:varchar(100)" json:"gedung"`
+	Kapasitas       int            `gorm:"default:0" json:"kapasitas"`
+	RecorderBackend string         `gorm:"type:varchar(20)" json:"recorder_backend"`
+	RecorderDevice  string         `gorm:"type:varchar(255)" json:"recorder_device"`
+	AgentID         string         `gorm:"type:varchar(100)" json:"agent_id"`
+	Aktif           bool           `gorm:"default:true" json:"aktif"`
+	TanggalDibuat   time.Time      `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time      `json:"tanggal_diupdate"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Status agen perekam ruangan, diisi handler jika ruangan memakai agen
+	AgentOnline *bool `gorm:"-" json:"agent_online,omitempty"`
+}
+
+func (ruangan *Ruangan) BeforeCreate(tx *gorm.DB) error {
+	ruangan.ID = uuid.New()
+	ruangan.TanggalDibuat = time.Now()
+	ruangan.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (ruangan *Ruangan) BeforeUpdate(tx *gorm.DB) error {
+	ruangan.TanggalDiupdate = time.Now()
+	return nil
+}
+
+// NormalisasiKodeRuangan menyamakan penulisan kode ruangan: huruf besar
+// tanpa spasi di awal dan akhir
+func NormalisasiKodeRuangan(kode string) string {
+	return strings.ToUpper(strings.TrimSpace(kode))
+}
+
+// CariAtauBuatRuangan mencari ruangan berdasarkan kode, atau mendaftarkan
+// ruangan baru tanpa perangkat khusus
+func CariAtauBuatRuangan(tx *gorm.DB, kode string) (*Ruangan, error) {
+	kode = NormalisasiKodeRuangan(kode)
+	if kode == "" {
+		return nil, errors.New("kode ruangan kosong")
+	}
+
+	var ruangan Ruangan
+	err := tx.Where("UPPER(kode) = ?", kode).First(&ruangan).Error
+	if err == nil {
+		return &ruangan, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	ruangan = Ruangan{Kode: kode, Nama: kode, Aktif: true}
+	if err := BuatAtauPulihkan(tx, &ruangan, "UPPER(kode) = ?", kode); err != nil {
+		return nil, err
+	}
+	return &ruangan, nil
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Semester adalah periode akademik tempat jadwal berlaku. Hanya satu
+// semester yang boleh aktif pada satu waktu.
+type Semester struct {
+	ID              uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	Nama            string         `gorm:"type:varchar(50);not null;uniqueIndex" json:"nama"`
+	TanggalMulai    time.Time      `gorm:"type:date;not null" json:"tanggal_mulai"`
+	TanggalSelesai  time.Time      `gorm:"type:date;not null" json:"tanggal_selesai"`
+	Aktif           bool           `gorm:"default:false;index" json:"aktif"`
+	TanggalDibuat   time.Time      `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time      `json:"tanggal_diupdate"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (semester *Semester) BeforeCreate(tx *gorm.DB) error {
+	semester.ID = uuid.New()
+	semester.TanggalDibuat = time.Now()
+	semester.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (semester *Semester) BeforeUpdate(tx *gorm.DB) error {
+	semester.TanggalDiupdate = time.Now()
+	return nil
+}
+
+// SemesterAktif mengambil semester yang sedang aktif, nil jika belum ada
+func SemesterAktif(db *gorm.DB) *Semester {
+	var semester Semester
+	if err := db.Where("aktif = ?", true).First(&semester).Error; err != nil {
+		return nil
+	}
+	return &semester
+}
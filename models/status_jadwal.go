@@ -0,0 +1,261 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status jadwal
+const (
+	JadwalTerjadwal = "terjadwal"
+	JadwalAktif     = "aktif"
+	JadwalMerekam   = "merekam"
+	JadwalSelesai   = "selesai"
+)
+
+// Pelaku perubahan status yang dicatat di JadwalStatusLog
+const (
+	AktorSistem     = "sistem"
+	AktorRekaman    = "rekaman"
+	AktorPengguna   = "pengguna"
+	AktorReconciler = "reconciler"
+)
+
+// Jenis objek pada JadwalStatusLog
+const (
+	LogJenisJadwal    = "jadwal"
+	LogJenisPertemuan = "pertemuan"
+)
+
+// transisiJadwal adalah state machine status jadwal. Siklus mingguan normal
+// terjadwal -> aktif -> selesai -> terjadwal; rekaman bisa dimulai dari
+// status apa pun selain merekam dan selalu kembali ke aktif atau selesai.
+var transisiJadwal = map[string][]string{
+	JadwalTerjadwal: {JadwalAktif, JadwalMerekam, JadwalSelesai},
+	JadwalAktif:     {JadwalMerekam, JadwalSelesai},
+	JadwalMerekam:   {JadwalAktif, JadwalSelesai},
+	JadwalSelesai:   {JadwalTerjadwal, JadwalMerekam},
+}
+
+// transisiPertemuan adalah state machine status pertemuan. Pertemuan yang
+// selesai bersifat final; pertemuan yang dibatalkan bisa dijadwalkan lagi.
+var transisiPertemuan = map[string][]string{
+	PertemuanTerjadwal:  {PertemuanAktif, PertemuanSelesai, PertemuanDibatalkan},
+	PertemuanAktif:      {PertemuanTerjadwal, PertemuanSelesai, PertemuanDibatalkan},
+	PertemuanSelesai:    {},
+	PertemuanDibatalkan: {PertemuanTerjadwal},
+}
+
+// ErrTransisiTidakValid dikembalikan untuk perubahan status yang tidak
+// diizinkan state machine
+var ErrTransisiTidakValid = errors.New("transisi status tidak diizinkan")
+
+// ErrStatusBerubah dikembalikan jika status berubah oleh proses lain saat
+// transisi sedang dijalankan
+var ErrStatusBerubah = errors.New("status sudah diubah oleh proses lain, silakan coba lagi")
+
+// TransisiError menjelaskan transisi yang ditolak
+type TransisiError struct {
+	Jenis string
+	Dari  string
+	Ke    string
+}
+
+func (e *TransisiError) Error() string {
+	return fmt.Sprintf("transisi status %s dari %q ke %q tidak diizinkan", e.Jenis, e.Dari, e.Ke)
+}
+
+func (e *TransisiError) Unwrap() error {
+	return ErrTransisiTidakValid
+}
+
+// JadwalStatusLog mencatat setiap perubahan status jadwal dan pertemuannya
+type JadwalStatusLog struct {
+	ID            uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	JadwalID      uuid.UUID  `gorm:"type:char(36);not null;index" json:"jadwal_id"`
+	PertemuanID   *uuid.UUID `gorm:"type:char(36);index" json:"pertemuan_id"`
+	Jenis         string     `gorm:"type:varchar(20);not null" json:"jenis"`
+	DariStatus    string     `gorm:"type:varchar(20)" json:"dari_status"`
+	KeStatus      string     `gorm:"type:varchar(20);not null" json:"ke_status"`
+	Aktor         string     `gorm:"type:varchar(100);not null" json:"aktor"`
+	Alasan        string     `gorm:"type:varchar(255)" json:"alasan"`
+	TanggalDibuat time.Time  `gorm:"index" json:"tanggal_dibuat"`
+}
+
+func (statusLog *JadwalStatusLog) BeforeCreate(tx *gorm.DB) error {
+	if statusLog.ID == uuid.Nil {
+		statusLog.ID = uuid.New()
+	}
+	if statusLog.TanggalDibuat.IsZero() {
+		statusLog.TanggalDibuat = time.Now()
+	}
+	return nil
+}
+
+// TransisiJadwalDiizinkan mengembalikan status tujuan yang sah dari status dari
+func TransisiJadwalDiizinkan(dari string) []string {
+	return transisiJadwal[dari]
+}
+
+// TransisiPertemuanDiizinkan mengembalikan status tujuan yang sah dari status dari
+func TransisiPertemuanDiizinkan(dari string) []string {
+	return transisiPertemuan[dari]
+}
+
+// JalurStatusJadwal mencari urutan transisi terpendek dari status dari ke
+// status ke, tanpa status awal. Mengembalikan nil jika tidak ada jalur.
+// Status yang tidak dikenal (data lama) langsung menuju status ke. Dipakai
+// reconciler ketika status tertinggal lebih dari satu langkah.
+func JalurStatusJadwal(dari, ke string) []string {
+	return cariJalur(transisiJadwal, dari, ke)
+}
+
+// JalurStatusPertemuan seperti JalurStatusJadwal untuk pertemuan
+func JalurStatusPertemuan(dari, ke string) []string {
+	return cariJalur(transisiPertemuan, dari, ke)
+}
+
+func cariJalur(mesin map[string][]string, dari, ke string) []string {
+	if dari == ke {
+		return []string{}
+	}
+	if _, dikenal := mesin[dari]; !dikenal {
+		return []string{ke}
+	}
+	asal := map[string]string{dari: ""}
+	antrian := []string{dari}
+	for len(antrian) > 0 {
+		status := antrian[0]
+		antrian = antrian[1:]
+		for _, berikut := range mesin[status] {
+			if _, ok := asal[berikut]; ok {
+				continue
+			}
+			asal[berikut] = status
+			if berikut == ke {
+				var jalur []string
+				for s := ke; s != dari; s = asal[s] {
+					jalur = append([]string{s}, jalur...)
+				}
+				return jalur
+			}
+			antrian = append(antrian, berikut)
+		}
+	}
+	return nil
+}
+
+func bolehTransisi(mesin map[string][]string, dari, ke string) bool {
+	for _, s := range mesin[dari] {
+		if s == ke {
+			return true
+		}
+	}
+	return false
+}
+
+// CatatStatusAwalJadwal mencatat status jadwal yang baru dibuat sebagai
+// entri pertama riwayat statusnya
+func CatatStatusAwalJadwal(db *gorm.DB, jadwal *Jadwal, aktor string, alasan string) error {
+	return db.Create(&JadwalStatusLog{
+		JadwalID: jadwal.ID,
+		Jenis:    LogJenisJadwal,
+		KeStatus: jadwal.Status,
+		Aktor:    aktor,
+		Alasan:   alasan,
+	}).Error
+}
+
+// UbahStatusJadwal memindahkan jadwal ke status ke lewat state machine dan
+// mencatatnya di JadwalStatusLog. Kolom tambahan di extra ikut diperbarui.
+// Jika status sudah sama, hanya extra yang diterapkan tanpa catatan.
+func UbahStatusJadwal(db *gorm.DB, jadwalID uuid.UUID, ke string, aktor string, alasan string, extra map[string]interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var jadwal Jadwal
+		if err := tx.Select("id", "status").First(&jadwal, "id = ?", jadwalID).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"tanggal_diupdate": time.Now()}
+		for k, v := range extra {
+			updates[k] = v
+		}
+
+		if jadwal.Status == ke {
+			if len(extra) == 0 {
+				return nil
+			}
+			return tx.Model(&Jadwal{}).Where("id = ?", jadwalID).Updates(updates).Error
+		}
+		if !bolehTransisi(transisiJadwal, jadwal.Status, ke) {
+			return &TransisiError{Jenis: LogJenisJadwal, Dari: jadwal.Status, Ke: ke}
+		}
+
+		updates["status"] = ke
+		result := tx.Model(&Jadwal{}).Where("id = ? AND status = ?", jadwalID, jadwal.Status).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusBerubah
+		}
+
+		return tx.Create(&JadwalStatusLog{
+			JadwalID:   jadwalID,
+			Jenis:      LogJenisJadwal,
+			DariStatus: jadwal.Status,
+			KeStatus:   ke,
+			Aktor:      aktor,
+			Alasan:     alasan,
+		}).Error
+	})
+}
+
+// UbahStatusPertemuan memindahkan pertemuan ke status ke lewat state
+// machine dan mencatatnya di JadwalStatusLog milik jadwalnya
+func UbahStatusPertemuan(db *gorm.DB, pertemuanID uuid.UUID, ke string, aktor string, alasan string, extra map[string]interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var pertemuan Pertemuan
+		if err := tx.Select("id", "jadwal_id", "status").First(&pertemuan, "id = ?", pertemuanID).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"tanggal_diupdate": time.Now()}
+		for k, v := range extra {
+			updates[k] = v
+		}
+
+		if pertemuan.Status == ke {
+			if len(extra) == 0 {
+				return nil
+			}
+			return tx.Model(&Pertemuan{}).Where("id = ?", pertemuanID).Updates(updates).Error
+		}
+		if !bolehTransisi(transisiPertemuan, pertemuan.Status, ke) {
+			return &TransisiError{Jenis: LogJenisPertemuan, Dari: pertemuan.Status, Ke: ke}
+		}
+
+		updates["status"] = ke
+		result := tx.Model(&Pertemuan{}).Where("id = ? AND status = ?", pertemuanID, pertemuan.Status).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusBerubah
+		}
+
+		return tx.Create(&JadwalStatusLog{
+			JadwalID:    pertemuan.JadwalID,
+			PertemuanID: &pertemuan.ID,
+			Jenis:       LogJenisPertemuan,
+			DariStatus:  pertemuan.Status,
+			KeStatus:    ke,
+			Aktor:       aktor,
+			Alasan:      alasan,
+		}).Error
+	})
+}
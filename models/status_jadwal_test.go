@@ -0,0 +1,180 @@
+package models_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dbUji membuka database SQLite sementara dengan skema aplikasi
+func dbUji(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "models.db")), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MigrateDB(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestJalurStatus(t *testing.T) {
+	tests := []struct {
+		nama  string
+		jalur func(dari, ke string) []string
+		dari  string
+		ke    string
+		want  []string
+	}{
+		{"jadwal sama", models.JalurStatusJadwal, models.JadwalAktif, models.JadwalAktif, []string{}},
+		{"jadwal satu langkah", models.JalurStatusJadwal, models.JadwalTerjadwal, models.JadwalMerekam, []string{models.JadwalMerekam}},
+		{"jadwal lewat selesai", models.JalurStatusJadwal, models.JadwalAktif, models.JadwalTerjadwal, []string{models.JadwalSelesai, models.JadwalTerjadwal}},
+		{"jadwal status lama", models.JalurStatusJadwal, "berlangsung", models.JadwalAktif, []string{models.JadwalAktif}},
+		{"pertemuan dibatalkan ke aktif", models.JalurStatusPertemuan, models.PertemuanDibatalkan, models.PertemuanAktif, []string{models.PertemuanTerjadwal, models.PertemuanAktif}},
+		{"pertemuan selesai final", models.JalurStatusPertemuan, models.PertemuanSelesai, models.PertemuanTerjadwal, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.jalur(tt.dari, tt.ke); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: jalur %s -> %s = %#v, want %#v", tt.nama, tt.dari, tt.ke, got, tt.want)
+		}
+	}
+}
+
+func TestUbahStatusJadwal(t *testing.T) {
+	tests := []struct {
+		dari    string
+		ke      string
+		wantErr error
+	}{
+		{models.JadwalTerjadwal, models.JadwalAktif, nil},
+		{models.JadwalTerjadwal, models.JadwalMerekam, nil},
+		{models.JadwalAktif, models.JadwalMerekam, nil},
+		{models.JadwalMerekam, models.JadwalSelesai, nil},
+		{models.JadwalSelesai, models.JadwalTerjadwal, nil},
+		{models.JadwalAktif, models.JadwalTerjadwal, models.ErrTransisiTidakValid},
+		{models.JadwalMerekam, models.JadwalTerjadwal, models.ErrTransisiTidakValid},
+		{models.JadwalSelesai, models.JadwalAktif, models.ErrTransisiTidakValid},
+		{models.JadwalTerjadwal, "dihapus", models.ErrTransisiTidakValid},
+	}
+	db := dbUji(t)
+	for _, tt := range tests {
+		jadwal := models.Jadwal{NamaMatkul: "Algoritma", Hari: "SENIN", WaktuMulai: "08:00", WaktuSelesai: "10:00", Status: tt.dari}
+		if err := db.Create(&jadwal).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		err := models.UbahStatusJadwal(db, jadwal.ID, tt.ke, models.AktorPengguna, "uji", nil)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s -> %s: err = %v, want %v", tt.dari, tt.ke, err, tt.wantErr)
+			continue
+		}
+
+		want, wantLog := tt.ke, int64(1)
+		if tt.wantErr != nil {
+			want, wantLog = tt.dari, 0
+		}
+		var tersimpan models.Jadwal
+		db.First(&tersimpan, "id = ?", jadwal.ID)
+		if tersimpan.Status != want {
+			t.Errorf("%s -> %s: status = %q, want %q", tt.dari, tt.ke, tersimpan.Status, want)
+		}
+		var jumlahLog int64
+		db.Model(&models.JadwalStatusLog{}).Where("jadwal_id = ?", jadwal.ID).Count(&jumlahLog)
+		if jumlahLog != wantLog {
+			t.Errorf("%s -> %s: jumlah log = %d, want %d", tt.dari, tt.ke, jumlahLog, wantLog)
+		}
+	}
+}
+
+func TestUbahStatusJadwalStatusSama(t *testing.T) {
+	db := dbUji(t)
+	jadwal := models.Jadwal{NamaMatkul: "Algoritma", Hari: "SENIN", WaktuMulai: "08:00", WaktuSelesai: "10:00", Status: models.JadwalMerekam}
+	if err := db.Create(&jadwal).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Status sama tidak dicatat, tetapi kolom tambahan tetap diperbarui
+	if err := models.UbahStatusJadwal(db, jadwal.ID, models.JadwalMerekam, models.AktorRekaman, "", map[string]interface{}{"sedang_rekam": true}); err != nil {
+		t.Fatal(err)
+	}
+	var tersimpan models.Jadwal
+	db.First(&tersimpan, "id = ?", jadwal.ID)
+	if !tersimpan.SedangRekam {
+		t.Error("kolom tambahan tidak diperbarui")
+	}
+	var jumlahLog int64
+	db.Model(&models.JadwalStatusLog{}).Count(&jumlahLog)
+	if jumlahLog != 0 {
+		t.Errorf("jumlah log = %d, want 0", jumlahLog)
+	}
+
+	err := models.UbahStatusJadwal(db, uuid.New(), models.JadwalAktif, models.AktorPengguna, "", nil)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("jadwal tidak ada: err = %v, want ErrRecordNotFound", err)
+	}
+}
+
+func TestUbahStatusPertemuan(t *testing.T) {
+	tests := []struct {
+		dari    string
+		ke      string
+		wantErr error
+	}{
+		{models.PertemuanTerjadwal, models.PertemuanAktif, nil},
+		{models.PertemuanTerjadwal, models.PertemuanDibatalkan, nil},
+		{models.PertemuanAktif, models.PertemuanSelesai, nil},
+		{models.PertemuanAktif, models.PertemuanTerjadwal, nil},
+		{models.PertemuanDibatalkan, models.PertemuanTerjadwal, nil},
+		{models.PertemuanSelesai, models.PertemuanTerjadwal, models.ErrTransisiTidakValid},
+		{models.PertemuanSelesai, models.PertemuanDibatalkan, models.ErrTransisiTidakValid},
+		{models.PertemuanDibatalkan, models.PertemuanAktif, models.ErrTransisiTidakValid},
+	}
+	db := dbUji(t)
+	jadwalID := uuid.New()
+	tanggal := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+	for i, tt := range tests {
+		pertemuan := models.Pertemuan{JadwalID: jadwalID, PertemuanKe: i + 1, Tanggal: tanggal, TanggalAsli: tanggal, WaktuMulai: "08:00", WaktuSelesai: "10:00", Status: tt.dari}
+		if err := db.Create(&pertemuan).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		err := models.UbahStatusPertemuan(db, pertemuan.ID, tt.ke, models.AktorPengguna, "uji", nil)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s -> %s: err = %v, want %v", tt.dari, tt.ke, err, tt.wantErr)
+			continue
+		}
+
+		want, wantLog := tt.ke, int64(1)
+		if tt.wantErr != nil {
+			want, wantLog = tt.dari, 0
+		}
+		var tersimpan models.Pertemuan
+		db.First(&tersimpan, "id = ?", pertemuan.ID)
+		if tersimpan.Status != want {
+			t.Errorf("%s -> %s: status = %q, want %q", tt.dari, tt.ke, tersimpan.Status, want)
+		}
+		var statusLog []models.JadwalStatusLog
+		db.Where("pertemuan_id = ?", pertemuan.ID).Find(&statusLog)
+		if int64(len(statusLog)) != wantLog {
+			t.Errorf("%s -> %s: jumlah log = %d, want %d", tt.dari, tt.ke, len(statusLog), wantLog)
+			continue
+		}
+		if wantLog == 1 && (statusLog[0].JadwalID != jadwalID || statusLog[0].Jenis != models.LogJenisPertemuan) {
+			t.Errorf("%s -> %s: log = %+v, want milik jadwal %s", tt.dari, tt.ke, statusLog[0], jadwalID)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tujuan upload sesi
+const (
+	UploadTujuanEvaluasi = "evaluasi"
+	UploadTujuanDosen    = "dosen"
+)
+
+// Status upload sesi
+const (
+	UploadStatusAktif    = "aktif"
+	UploadStatusDiproses = "diproses" // checksum sedang diverifikasi dan file dilampirkan
+	UploadStatusSelesai  = "selesai"
+	UploadStatusGagal    = "gagal"
+	UploadStatusBatal    = "batal"
+)
+
+// UploadSesi adalah upload audio bertahap yang bisa dilanjutkan. Klien
+// mengirim potongan byte berurutan yang disimpan di storage di bawah
+// PrefixPotongan; setelah semua diterima dan checksum cocok, potongan
+// digabung ke penyimpanan evaluasi atau dosen.
+type UploadSesi struct {
+	ID              uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	Tujuan          string    `gorm:"type:varchar(20);not null" json:"tujuan"`
+	TargetID        string    `gorm:"type:char(36);not null;index" json:"target_id"`
+	NamaFile        string    `gorm:"type:varchar(255);not null" json:"nama_file"`
+	UkuranTotal     int64     `gorm:"not null" json:"ukuran_total"`
+	Diterima        int64     `gorm:"default:0" json:"diterima"`
+	Checksum        string    `gorm:"type:char(64);not null" json:"checksum"` // SHA-256 hex
+	Status          string    `gorm:"type:varchar(20);default:'aktif';index" json:"status"`
+	PrefixPotongan  string    `gorm:"type:varchar(500)" json:"-"`
+	PathHasil       string    `gorm:"type:varchar(500)" json:"path_hasil"`
+	PesanError      string    `gorm:"type:text" json:"pesan_error"`
+	DibuatOleh      string    `gorm:"type:varchar(100)" json:"dibuat_oleh"`
+	KedaluwarsaPada time.Time `gorm:"index" json:"kedaluwarsa_pada"`
+	TanggalDibuat   time.Time `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time `json:"tanggal_diupdate"`
+}
+
+func (sesi *UploadSesi) BeforeCreate(tx *gorm.DB) error {
+	sesi.ID = uuid.New()
+	sesi.TanggalDibuat = time.Now()
+	sesi.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (sesi *UploadSesi) BeforeUpdate(tx *gorm.DB) error {
+	sesi.TanggalDiupdate = time.Now()
+	return nil
+}
+
+// Lengkap mengecek apakah semua byte sudah diterima
+func (sesi *UploadSesi) Lengkap() bool {
+	return sesi.Diterima == sesi.UkuranTotal
+}
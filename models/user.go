@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Role pengguna
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+	RoleDosen    = "dosen"
+	RoleViewer   = "viewer"
+)
+
+// RoleValid mengecek apakah role dikenal
+func RoleValid(role string) bool {
+	switch role {
+	case RoleAdmin, RoleOperator, RoleDosen, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// User adalah akun login. Akun dengan role dosen dihubungkan ke data Dosen
+// lewat DosenID; satu dosen paling banyak punya satu akun.
+type User struct {
+	ID              uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	Username        string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"`
+	Nama            string         `gorm:"type:varchar(100)" json:"nama"`
+	Email           string         `gorm:"type:varchar(100)" json:"email"`
+	PasswordHash    string         `gorm:"type:varchar(100);not null" json:"-"`
+	Role            string         `gorm:"type:varchar(20);not null;index" json:"role"`
+	DosenID         *uuid.UUID     `gorm:"type:char(36);uniqueIndex" json:"dosen_id"`
+	Dosen           *Dosen         `gorm:"foreignKey:DosenID" json:"dosen,omitempty"`
+	Aktif           bool           `gorm:"default:true" json:"aktif"`
+	VersiToken      int            `gorm:"default:1" json:"-"`
+	LoginTerakhir   *time.Time     `json:"login_terakhir"`
+	TanggalDibuat   time.Time      `json:"tanggal_dibuat"`
+	TanggalDiupdate time.Time      `json:"tanggal_diupdate"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (user *User) BeforeCreate(tx *gorm.DB) error {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.VersiToken == 0 {
+		user.VersiToken = 1
+	}
+	user.TanggalDibuat = time.Now()
+	user.TanggalDiupdate = time.Now()
+	return nil
+}
+
+func (user *User) BeforeUpdate(tx *gorm.DB) error {
+	user.TanggalDiupdate = time.Now()
+	return nil
+}
@@ -0,0 +1,286 @@
+// Package reconciler menyelaraskan status jadwal dan pertemuan dengan waktu
+// sekarang di latar belakang, sehingga endpoint baca tidak perlu menulis ke
+// database.
+package reconciler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultInterval adalah jarak antar rekonsiliasi jika tidak diatur
+const DefaultInterval = 30 * time.Second
+
+// Jenis objek yang statusnya berubah, sama dengan jenis di JadwalStatusLog
+const (
+	JenisJadwal    = models.LogJenisJadwal
+	JenisPertemuan = models.LogJenisPertemuan
+)
+
+// Transisi adalah perubahan status satu jadwal atau pertemuan
+type Transisi struct {
+	Jenis    string    `json:"jenis"`
+	ID       uuid.UUID `json:"id"`
+	JadwalID uuid.UUID `json:"jadwal_id"`
+	Dari     string    `json:"dari"`
+	Ke       string    `json:"ke"`
+	Waktu    time.Time `json:"waktu"`
+}
+
+// Listener dipanggil untuk setiap transisi status
+type Listener func(Transisi)
+
+// Hasil merangkum satu kali rekonsiliasi
+type Hasil struct {
+	Waktu    time.Time  `json:"waktu"`
+	Durasi   string     `json:"durasi"`
+	Transisi []Transisi `json:"transisi"`
+}
+
+// Reconciler menghitung status jadwal dan pertemuan secara berkala dan
+// memperbaruinya dalam satu UPDATE per jenis perubahan
+type Reconciler struct {
+	db        *gorm.DB
+	interval  time.Duration
+	wg        sync.WaitGroup
+	runMu     sync.Mutex // satu rekonsiliasi dalam satu waktu
+	mu        sync.Mutex
+	listeners []Listener
+	last      *Hasil
+}
+
+// New membuat Reconciler dengan interval tertentu
+func New(db *gorm.DB, interval time.Duration) *Reconciler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Reconciler{
+		db:       db,
+		interval: interval,
+	}
+}
+
+// OnTransition mendaftarkan listener untuk transisi status
+func (r *Reconciler) OnTransition(l Listener) {
+	r.mu.Lock()
+	r.listeners = append(r.listeners, l)
+	r.mu.Unlock()
+}
+
+// Start menjalankan loop rekonsiliasi sampai ctx dibatalkan
+func (r *Reconciler) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go r.run(ctx)
+	log.Printf("Rekonsiliasi status jadwal berjalan setiap %s", r.interval)
+}
+
+// Wait menunggu loop rekonsiliasi berhenti
+func (r *Reconciler) Wait() {
+	r.wg.Wait()
+}
+
+// Last mengembalikan hasil rekonsiliasi terakhir, nil jika belum pernah jalan
+func (r *Reconciler) Last() *Hasil {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
+
+func (r *Reconciler) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Reconcile(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Rekonsiliasi status gagal: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile menjalankan satu kali rekonsiliasi dan mengembalikan transisi
+// yang terjadi
+func (r *Reconciler) Reconcile(ctx context.Context) (*Hasil, error) {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	mulai := time.Now()
+	now := kalender.Default().Sekarang()
+	db := r.db.WithContext(ctx)
+
+	transisiJadwal, err := r.reconcileJadwal(db, now)
+	if err != nil {
+		return nil, err
+	}
+	transisiPertemuan, err := r.reconcilePertemuan(db, now)
+	if err != nil {
+		return nil, err
+	}
+
+	transisi := make([]Transisi, 0, len(transisiJadwal)+len(transisiPertemuan))
+	transisi = append(transisi, transisiJadwal...)
+	transisi = append(transisi, transisiPertemuan...)
+	hasil := &Hasil{
+		Waktu:    now,
+		Durasi:   time.Since(mulai).String(),
+		Transisi: transisi,
+	}
+
+	r.mu.Lock()
+	r.last = hasil
+	listeners := append([]Listener(nil), r.listeners...)
+	r.mu.Unlock()
+
+	for _, t := range hasil.Transisi {
+		for _, l := range listeners {
+			l(t)
+		}
+	}
+	return hasil, nil
+}
+
+// perubahan mengelompokkan ID yang berpindah dari satu status ke status lain
+type perubahan struct {
+	dari, ke string
+}
+
+// reconcileJadwal menghitung status jadwal yang tidak sedang merekam
+func (r *Reconciler) reconcileJadwal(db *gorm.DB, now time.Time) ([]Transisi, error) {
+	var jadwals []models.Jadwal
+	err := db.Select("id", "hari", "waktu_mulai", "waktu_selesai", "status").
+		Where("sedang_rekam = ?", false).Find(&jadwals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	kelompok := make(map[perubahan][]uuid.UUID)
+	jadwalID := make(map[uuid.UUID]uuid.UUID, len(jadwals))
+	for _, jadwal := range jadwals {
+		if status := jadwal.StatusPada(now); status != jadwal.Status {
+			key := perubahan{jadwal.Status, status}
+			kelompok[key] = append(kelompok[key], jadwal.ID)
+			jadwalID[jadwal.ID] = jadwal.ID
+		}
+	}
+	return terapkan(db, JenisJadwal, kelompok, jadwalID, now)
+}
+
+// reconcilePertemuan menghitung status pertemuan yang sudah dimulai
+func (r *Reconciler) reconcilePertemuan(db *gorm.DB, now time.Time) ([]Transisi, error) {
+	var pertemuan []models.Pertemuan
+	err := db.Select("id", "jadwal_id", "tanggal", "waktu_mulai", "waktu_selesai", "status").
+		Where("status IN ? AND tanggal < ?",
+			[]string{models.PertemuanTerjadwal, models.PertemuanAktif},
+			kalender.Default().Tanggal(now).AddDate(0, 0, 1)).
+		Find(&pertemuan).Error
+	if err != nil {
+		return nil, err
+	}
+
+	kelompok := make(map[perubahan][]uuid.UUID)
+	jadwalID := make(map[uuid.UUID]uuid.UUID, len(pertemuan))
+	for _, p := range pertemuan {
+		if status := p.StatusPada(now); status != p.Status {
+			key := perubahan{p.Status, status}
+			kelompok[key] = append(kelompok[key], p.ID)
+			jadwalID[p.ID] = p.JadwalID
+		}
+	}
+	return terapkan(db, JenisPertemuan, kelompok, jadwalID, now)
+}
+
+// terapkan menjalankan setiap kelompok perubahan dalam satu transaksi: satu
+// UPDATE untuk semua barisnya, lalu satu catatan JadwalStatusLog per baris
+// untuk perubahan dari status lama ke status baru
+func terapkan(db *gorm.DB, jenis string, kelompok map[perubahan][]uuid.UUID, jadwalID map[uuid.UUID]uuid.UUID, now time.Time) ([]Transisi, error) {
+	var transisi []Transisi
+	for key, ids := range kelompok {
+		var jalur []string
+		var model interface{}
+		if jenis == JenisJadwal {
+			jalur = models.JalurStatusJadwal(key.dari, key.ke)
+			model = &models.Jadwal{}
+		} else {
+			jalur = models.JalurStatusPertemuan(key.dari, key.ke)
+			model = &models.Pertemuan{}
+		}
+		if jalur == nil {
+			log.Printf("Rekonsiliasi melewati %d %s: tidak ada transisi dari %q ke %q", len(ids), jenis, key.dari, key.ke)
+			continue
+		}
+
+		var diubah []uuid.UUID
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Baris yang masih berstatus lama (dan tidak sedang merekam untuk
+			// jadwal) dikunci dulu, sehingga yang di-UPDATE dan dicatat
+			// persis baris yang diubah reconciler, bukan baris yang diubah
+			// proses lain sejak dibaca, misalnya rekaman yang baru dimulai
+			query := tx.Model(model).Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id IN ? AND status = ?", ids, key.dari)
+			if jenis == JenisJadwal {
+				query = query.Where("sedang_rekam = ?", false)
+			}
+			diubah = nil
+			if err := query.Pluck("id", &diubah).Error; err != nil {
+				return err
+			}
+			if len(diubah) == 0 {
+				return nil
+			}
+
+			err := tx.Model(model).Where("id IN ?", diubah).Updates(map[string]interface{}{
+				"status":           key.ke,
+				"tanggal_diupdate": time.Now(),
+			}).Error
+			if err != nil {
+				return err
+			}
+
+			logs := make([]models.JadwalStatusLog, 0, len(diubah))
+			for _, id := range diubah {
+				entry := models.JadwalStatusLog{
+					JadwalID:      jadwalID[id],
+					Jenis:         jenis,
+					DariStatus:    key.dari,
+					KeStatus:      key.ke,
+					Aktor:         models.AktorReconciler,
+					Alasan:        "rekonsiliasi status berkala",
+					TanggalDibuat: now,
+				}
+				if jenis == JenisPertemuan {
+					pertemuanID := id
+					entry.PertemuanID = &pertemuanID
+				}
+				logs = append(logs, entry)
+			}
+			return tx.Create(&logs).Error
+		})
+		if err != nil {
+			return transisi, err
+		}
+
+		for _, id := range diubah {
+			transisi = append(transisi, Transisi{
+				Jenis: jenis, ID: id, JadwalID: jadwalID[id],
+				Dari: key.dari, Ke: key.ke, Waktu: now,
+			})
+		}
+	}
+	return transisi, nil
+}
@@ -0,0 +1,109 @@
+package reconciler
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"CLAIRE/database"
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dbUji membuka database SQLite sementara dengan skema aplikasi
+func dbUji(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "reconciler.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MigrateDB(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// buatJadwalBerlangsung menyimpan jadwal yang sedang berlangsung sepanjang
+// hari ini dengan status tersimpan tertentu
+func buatJadwalBerlangsung(t *testing.T, db *gorm.DB, status string) models.Jadwal {
+	t.Helper()
+	jadwal := models.Jadwal{
+		NamaMatkul:   "Algoritma",
+		Hari:         kalender.Default().Hari(kalender.Default().Sekarang()),
+		WaktuMulai:   "00:00",
+		WaktuSelesai: "23:59",
+	}
+	if err := db.Create(&jadwal).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&jadwal).Update("status", status).Error; err != nil {
+		t.Fatal(err)
+	}
+	return jadwal
+}
+
+func TestReconcileCatatSatuTransisi(t *testing.T) {
+	db := dbUji(t)
+	// selesai -> aktif melewati terjadwal di state machine, tetapi yang
+	// dicatat hanya perubahan yang benar-benar terjadi
+	jadwal := buatJadwalBerlangsung(t, db, models.JadwalSelesai)
+
+	hasil, err := New(db, 0).Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hasil.Transisi) != 1 || hasil.Transisi[0].Dari != models.JadwalSelesai || hasil.Transisi[0].Ke != models.JadwalAktif {
+		t.Fatalf("transisi = %+v, want satu selesai -> aktif", hasil.Transisi)
+	}
+
+	var logs []models.JadwalStatusLog
+	if err := db.Where("jadwal_id = ?", jadwal.ID).Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("jumlah log = %d, want 1: %+v", len(logs), logs)
+	}
+	if l := logs[0]; l.DariStatus != models.JadwalSelesai || l.KeStatus != models.JadwalAktif || l.Aktor != models.AktorReconciler {
+		t.Errorf("log = %s -> %s oleh %s, want selesai -> aktif oleh %s", l.DariStatus, l.KeStatus, l.Aktor, models.AktorReconciler)
+	}
+}
+
+func TestReconcileLewatiBarisYangBerubah(t *testing.T) {
+	db := dbUji(t)
+	a := buatJadwalBerlangsung(t, db, models.JadwalTerjadwal)
+	b := buatJadwalBerlangsung(t, db, models.JadwalTerjadwal)
+
+	// Proses lain mengubah b ke status tujuan setelah reconciler membaca
+	// semua jadwal
+	sudah := false
+	err := db.Callback().Query().After("gorm:query").Register("uji:ubah", func(tx *gorm.DB) {
+		if sudah || tx.Statement.Table != "jadwals" {
+			return
+		}
+		sudah = true
+		tx.Session(&gorm.Session{NewDB: true}).Model(&models.Jadwal{}).
+			Where("id = ?", b.ID).Update("status", models.JadwalAktif)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasil, err := New(db, 0).Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hasil.Transisi) != 1 || hasil.Transisi[0].ID != a.ID {
+		t.Fatalf("transisi = %+v, want hanya jadwal %s", hasil.Transisi, a.ID)
+	}
+	var jumlah int64
+	db.Model(&models.JadwalStatusLog{}).Where("jadwal_id = ? AND aktor = ?", b.ID, models.AktorReconciler).Count(&jumlah)
+	if jumlah != 0 {
+		t.Errorf("log reconciler untuk jadwal yang diubah proses lain = %d, want 0", jumlah)
+	}
+}
@@ -0,0 +1,105 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FFmpegRecorder merekam dari device capture melalui ffmpeg
+type FFmpegRecorder struct {
+	Format string // input format ffmpeg: alsa, pulse, dshow
+	Input  string // nama input sesuai format
+	Binary string // path ffmpeg, default "ffmpeg"
+}
+
+// NewALSARecorder merekam dari device ALSA, misalnya "hw:1,0"
+func NewALSARecorder(device string) *FFmpegRecorder {
+	if device == "" {
+		device = "default"
+	}
+	return &FFmpegRecorder{Format: "alsa", Input: device}
+}
+
+// NewPulseRecorder merekam dari source PulseAudio (juga dipakai PipeWire
+// melalui pipewire-pulse)
+func NewPulseRecorder(device string) *FFmpegRecorder {
+	if device == "" {
+		device = "default"
+	}
+	return &FFmpegRecorder{Format: "pulse", Input: device}
+}
+
+// NewDShowRecorder merekam dari device DirectShow di Windows
+func NewDShowRecorder(device string) *FFmpegRecorder {
+	if device == "" {
+		device = "Microphone"
+	}
+	if !strings.HasPrefix(device, "audio=") {
+		device = "audio=" + device
+	}
+	return &FFmpegRecorder{Format: "dshow", Input: device}
+}
+
+func (r *FFmpegRecorder) Name() string {
+	return r.Format + ":" + r.Input
+}
+
+func (r *FFmpegRecorder) Record(ctx context.Context, outputPath string, duration time.Duration) error {
+	binary := r.Binary
+	if binary == "" {
+		binary = "ffmpeg"
+	}
+
+	seconds := strconv.Itoa(int(duration.Seconds()))
+	cmd := exec.CommandContext(ctx, binary,
+		"-y",           // Overwrite output file
+		"-f", r.Format, // Input format
+		"-i", r.Input, // Audio input device
+		"-t", seconds, // Durasi rekaman
+		"-acodec", "pcm_s16le", // Audio codec
+		"-ar", strconv.Itoa(SampleRate), // Sample rate
+		"-ac", strconv.Itoa(Channels), // Mono audio
+		outputPath, // Output file
+	)
+
+	// Saat dibatalkan, minta ffmpeg berhenti dengan "q" agar header WAV
+	// ditulis dengan benar. Jika tidak merespons, proses di-kill.
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Cancel = func() error {
+		_, err := io.WriteString(stdin, "q")
+		return err
+	}
+	cmd.WaitDelay = 10 * time.Second
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			FinalizeWAV(outputPath)
+			return ctx.Err()
+		}
+		return fmt.Errorf("ffmpeg %s gagal: %v: %s", r.Name(), err, lastLine(stderr.String()))
+	}
+	if ctx.Err() != nil {
+		// ffmpeg berhenti normal setelah menerima "q"
+		FinalizeWAV(outputPath)
+		return ctx.Err()
+	}
+	return nil
+}
+
+// lastLine mengambil baris terakhir output ffmpeg sebagai ringkasan error
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
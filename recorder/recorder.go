@@ -0,0 +1,134 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"CLAIRE/config"
+)
+
+// Nama backend perekam yang didukung
+const (
+	BackendALSA       = "alsa"
+	BackendPulse      = "pulse"
+	BackendDShow      = "dshow"
+	BackendFileReplay = "file"
+)
+
+// Format audio keluaran semua perekam: PCM 16-bit, 16 kHz, mono
+const (
+	SampleRate    = 16000
+	Channels      = 1
+	BitsPerSample = 16
+)
+
+// Recorder merekam audio dari sebuah sumber ke file WAV
+type Recorder interface {
+	// Record merekam selama duration ke outputPath. Pembatalan ctx
+	// menghentikan rekaman lebih awal.
+	Record(ctx context.Context, outputPath string, duration time.Duration) error
+	// Name mengembalikan deskripsi singkat sumber audio
+	Name() string
+}
+
+// New membuat Recorder berdasarkan nama backend dan device
+func New(cfg *config.Config, backend string, device string) (Recorder, error) {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case BackendALSA:
+		return NewALSARecorder(device), nil
+	case BackendPulse, "pipewire":
+		return NewPulseRecorder(device), nil
+	case BackendDShow:
+		return NewDShowRecorder(device), nil
+	case BackendFileReplay, "replay":
+		source := device
+		if source == "" {
+			source = cfg.RecorderReplayFile
+		}
+		if source == "" {
+			return nil, fmt.Errorf("file replay membutuhkan path file WAV sumber")
+		}
+		replay := NewFileReplayRecorder(source)
+		replay.Realtime = cfg.RecorderReplayRealtime
+		return replay, nil
+	default:
+		return nil, fmt.Errorf("backend perekam tidak dikenal: %s", backend)
+	}
+}
+
+// ForRoom memilih Recorder untuk ruangan tertentu. Pemetaan per ruangan
+// dari RECORDER_ROOM_DEVICES didahulukan, selain itu memakai backend global.
+func ForRoom(cfg *config.Config, ruangan string) (Recorder, error) {
+	rooms := ParseRoomDevices(cfg.RecorderRoomDevices)
+	if spec, ok := rooms[strings.ToUpper(strings.TrimSpace(ruangan))]; ok {
+		return New(cfg, spec.Backend, spec.Device)
+	}
+	return New(cfg, cfg.RecorderBackend, cfg.RecorderDevice)
+}
+
+// FromRegistry membuat Recorder dari perangkat yang tersimpan di registry
+// ruangan. Nilai registry berasal dari API, jadi untuk backend file replay
+// device bukan path melainkan nama file di RECORDER_REPLAY_FILES; device
+// kosong memakai RECORDER_REPLAY_FILE.
+func FromRegistry(cfg *config.Config, backend string, device string) (Recorder, error) {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case BackendFileReplay, "replay":
+		if device == "" {
+			return New(cfg, backend, "")
+		}
+		path, ok := ParseReplayFiles(cfg.RecorderReplayFiles)[device]
+		if !ok {
+			return nil, fmt.Errorf("file replay %q tidak terdaftar di RECORDER_REPLAY_FILES", device)
+		}
+		return New(cfg, backend, path)
+	default:
+		return New(cfg, backend, device)
+	}
+}
+
+// ParseReplayFiles membaca format "demo=/srv/audio/demo.wav;kelas=kelas.wav"
+// menjadi pemetaan nama ke path file replay
+func ParseReplayFiles(value string) map[string]string {
+	files := make(map[string]string)
+	for _, entry := range strings.Split(value, ";") {
+		name, path, found := strings.Cut(entry, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !found || name == "" || path == "" {
+			continue
+		}
+		files[name] = path
+	}
+	return files
+}
+
+// DeviceSpec adalah pasangan backend dan device untuk satu ruangan
+type DeviceSpec struct {
+	Backend string
+	Device  string
+}
+
+// ParseRoomDevices membaca format "R101=alsa:hw:1,0;R102=pulse:default".
+// Nama ruangan dinormalisasi ke huruf besar.
+func ParseRoomDevices(value string) map[string]DeviceSpec {
+	rooms := make(map[string]DeviceSpec)
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		room, spec, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+
+		backend, device, _ := strings.Cut(strings.TrimSpace(spec), ":")
+		rooms[strings.ToUpper(strings.TrimSpace(room))] = DeviceSpec{
+			Backend: strings.TrimSpace(backend),
+			Device:  strings.TrimSpace(device),
+		}
+	}
+	return rooms
+}
@@ -0,0 +1,122 @@
+package recorder
+
+import (
+	"reflect"
+	"testing"
+
+	"CLAIRE/config"
+)
+
+func TestParseRoomDevices(t *testing.T) {
+	rooms := ParseRoomDevices(" r101 = alsa:hw:1,0 ;R102=pulse:default;;rusak;R103=file")
+
+	want := map[string]DeviceSpec{
+		"R101": {Backend: "alsa", Device: "hw:1,0"},
+		"R102": {Backend: "pulse", Device: "default"},
+		"R103": {Backend: "file", Device: ""},
+	}
+	if len(rooms) != len(want) {
+		t.Fatalf("jumlah ruangan = %d, want %d (%v)", len(rooms), len(want), rooms)
+	}
+	for room, spec := range want {
+		if got := rooms[room]; got != spec {
+			t.Errorf("rooms[%q] = %+v, want %+v", room, got, spec)
+		}
+	}
+}
+
+func TestParseRoomDevicesKosong(t *testing.T) {
+	if rooms := ParseRoomDevices(""); len(rooms) != 0 {
+		t.Fatalf("ParseRoomDevices(\"\") = %v, want kosong", rooms)
+	}
+}
+
+func TestForRoom(t *testing.T) {
+	cfg := &config.Config{
+		RecorderBackend:        BackendALSA,
+		RecorderDevice:         "default",
+		RecorderReplayFile:     "global.wav",
+		RecorderReplayRealtime: false,
+		RecorderRoomDevices:    "R101=pulse:mic-kelas;R102=file:r102.wav;R103=replay",
+	}
+
+	tests := []struct {
+		ruangan string
+		name    string
+	}{
+		{"R101", "pulse:mic-kelas"},
+		{" r101 ", "pulse:mic-kelas"},
+		{"R102", "file:r102.wav"},
+		{"R103", "file:global.wav"},
+		{"R999", "alsa:default"},
+		{"", "alsa:default"},
+	}
+	for _, tt := range tests {
+		rec, err := ForRoom(cfg, tt.ruangan)
+		if err != nil {
+			t.Fatalf("ForRoom(%q): %v", tt.ruangan, err)
+		}
+		if got := rec.Name(); got != tt.name {
+			t.Errorf("ForRoom(%q).Name() = %q, want %q", tt.ruangan, got, tt.name)
+		}
+	}
+
+	rec, _ := ForRoom(cfg, "R102")
+	if replay, ok := rec.(*FileReplayRecorder); !ok || replay.Realtime {
+		t.Errorf("ForRoom(R102) = %#v, want FileReplayRecorder tanpa realtime", rec)
+	}
+}
+
+func TestForRoomBackendTidakValid(t *testing.T) {
+	cfg := &config.Config{
+		RecorderBackend:     BackendALSA,
+		RecorderRoomDevices: "R101=gramofon:1;R102=file",
+	}
+	if _, err := ForRoom(cfg, "R101"); err == nil {
+		t.Error("ForRoom dengan backend tidak dikenal harus gagal")
+	}
+	if _, err := ForRoom(cfg, "R102"); err == nil {
+		t.Error("ForRoom file replay tanpa file sumber harus gagal")
+	}
+}
+
+func TestParseReplayFiles(t *testing.T) {
+	files := ParseReplayFiles(" demo = /srv/audio/demo.wav ;;rusak;kosong=;=tanpa-nama.wav;kelas=kelas.wav")
+	want := map[string]string{"demo": "/srv/audio/demo.wav", "kelas": "kelas.wav"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ParseReplayFiles = %v, want %v", files, want)
+	}
+}
+
+func TestFromRegistry(t *testing.T) {
+	cfg := &config.Config{
+		RecorderReplayFile:  "global.wav",
+		RecorderReplayFiles: "demo=/srv/audio/demo.wav",
+	}
+
+	tests := []struct {
+		backend string
+		device  string
+		name    string
+	}{
+		{"file", "demo", "file:/srv/audio/demo.wav"},
+		{"replay", "", "file:global.wav"},
+		{"alsa", "hw:1,0", "alsa:hw:1,0"},
+	}
+	for _, tt := range tests {
+		rec, err := FromRegistry(cfg, tt.backend, tt.device)
+		if err != nil {
+			t.Fatalf("FromRegistry(%q, %q): %v", tt.backend, tt.device, err)
+		}
+		if got := rec.Name(); got != tt.name {
+			t.Errorf("FromRegistry(%q, %q).Name() = %q, want %q", tt.backend, tt.device, got, tt.name)
+		}
+	}
+
+	// Path dari API tidak pernah dipakai langsung
+	for _, device := range []string{"/etc/passwd", "/srv/audio/demo.wav", "../demo.wav", "DEMO"} {
+		if rec, err := FromRegistry(cfg, "file", device); err == nil {
+			t.Errorf("FromRegistry(file, %q) = %s, want error", device, rec.Name())
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// wavUji menulis data sebagai file WAV sumber di direktori sementara
+func wavUji(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sumber.wav")
+	if err := WriteWAV(path, data); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// dataUji membuat n byte PCM dengan pola yang mudah dikenali
+func dataUji(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestFileReplayMengulangSumber(t *testing.T) {
+	// 0,1 detik audio diputar selama 1 detik
+	sumber := dataUji(int(formatKeluaran.ByteRate) / 10)
+	rec := NewFileReplayRecorder(wavUji(t, sumber))
+	rec.Realtime = false
+
+	output := filepath.Join(t.TempDir(), "rekaman.wav")
+	if err := rec.Record(context.Background(), output, time.Second); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	format, data, err := readWAV(output)
+	if err != nil {
+		t.Fatalf("readWAV: %v", err)
+	}
+	if format != formatKeluaran {
+		t.Errorf("format = %+v, want %+v", format, formatKeluaran)
+	}
+	if len(data) != int(formatKeluaran.ByteRate) {
+		t.Fatalf("panjang data = %d, want %d", len(data), formatKeluaran.ByteRate)
+	}
+	for i := 0; i < len(data); i += len(sumber) {
+		if !bytes.Equal(data[i:i+len(sumber)], sumber) {
+			t.Fatalf("data pada offset %d bukan ulangan sumber", i)
+		}
+	}
+}
+
+func TestFileReplayDurasiDibulatkanKeFrame(t *testing.T) {
+	rec := NewFileReplayRecorder(wavUji(t, dataUji(64)))
+	rec.Realtime = false
+
+	// 1 ms = 32 byte; 1,5 ms = 48 byte = 24 frame
+	output := filepath.Join(t.TempDir(), "rekaman.wav")
+	if err := rec.Record(context.Background(), output, 1500*time.Microsecond); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	_, data, err := readWAV(output)
+	if err != nil {
+		t.Fatalf("readWAV: %v", err)
+	}
+	if len(data) != 48 {
+		t.Errorf("panjang data = %d, want 48", len(data))
+	}
+}
+
+func TestFileReplayDibatalkan(t *testing.T) {
+	rec := NewFileReplayRecorder(wavUji(t, dataUji(int(formatKeluaran.ByteRate))))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 350*time.Millisecond)
+	defer cancel()
+
+	output := filepath.Join(t.TempDir(), "rekaman.wav")
+	err := rec.Record(ctx, output, time.Minute)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Record = %v, want context.DeadlineExceeded", err)
+	}
+
+	// Header harus diperbaiki sesuai audio yang sempat ditulis
+	_, data, err := readWAV(output)
+	if err != nil {
+		t.Fatalf("readWAV: %v", err)
+	}
+	info, err := os.Stat(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 || int64(len(data)) != info.Size()-44 {
+		t.Errorf("panjang data = %d, ukuran file = %d", len(data), info.Size())
+	}
+	if len(data) >= int(formatKeluaran.ByteRate) {
+		t.Errorf("rekaman yang dibatalkan terlalu panjang: %d byte", len(data))
+	}
+}
+
+func TestFileReplaySumberTidakValid(t *testing.T) {
+	dir := t.TempDir()
+	bukanWAV := filepath.Join(dir, "bukan.wav")
+	os.WriteFile(bukanWAV, []byte("bukan file audio sama sekali"), 0644)
+
+	sumber := []string{
+		filepath.Join(dir, "tidak-ada.wav"),
+		bukanWAV,
+		wavUji(t, nil),
+	}
+	for _, path := range sumber {
+		rec := NewFileReplayRecorder(path)
+		rec.Realtime = false
+		if err := rec.Record(context.Background(), filepath.Join(dir, "out.wav"), time.Second); err == nil {
+			t.Errorf("Record dengan sumber %s harus gagal", filepath.Base(path))
+		}
+	}
+}
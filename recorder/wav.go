@@ -0,0 +1,190 @@
+package recorder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// wavFormat berisi field penting dari chunk "fmt " file WAV
+type wavFormat struct {
+	AudioFormat   uint16
+	Channels      uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+}
+
+// readWAV membaca format dan seluruh data PCM dari file WAV
+func readWAV(path string) (wavFormat, []byte, error) {
+	var format wavFormat
+
+	file, err := os.Open(path)
+	if err != nil {
+		return format, nil, err
+	}
+	defer file.Close()
+
+	var riff [12]byte
+	if _, err := io.ReadFull(file, riff[:]); err != nil {
+		return format, nil, fmt.Errorf("header WAV tidak valid: %v", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return format, nil, fmt.Errorf("%s bukan file WAV", path)
+	}
+
+	hasFormat := false
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(file, header[:]); err != nil {
+			return format, nil, fmt.Errorf("chunk data tidak ditemukan di %s", path)
+		}
+		chunkID := string(header[0:4])
+		chunkSize := binary.LittleEndian.Uint32(header[4:8])
+
+		switch chunkID {
+		case "fmt ":
+			chunk := make([]byte, chunkSize)
+			if _, err := io.ReadFull(file, chunk); err != nil {
+				return format, nil, fmt.Errorf("chunk fmt tidak valid: %v", err)
+			}
+			if len(chunk) < 16 {
+				return format, nil, fmt.Errorf("chunk fmt terlalu pendek")
+			}
+			format = wavFormat{
+				AudioFormat:   binary.LittleEndian.Uint16(chunk[0:2]),
+				Channels:      binary.LittleEndian.Uint16(chunk[2:4]),
+				SampleRate:    binary.LittleEndian.Uint32(chunk[4:8]),
+				ByteRate:      binary.LittleEndian.Uint32(chunk[8:12]),
+				BlockAlign:    binary.LittleEndian.Uint16(chunk[12:14]),
+				BitsPerSample: binary.LittleEndian.Uint16(chunk[14:16]),
+			}
+			hasFormat = true
+
+			// Chunk berukuran ganjil diberi padding satu byte
+			if chunkSize%2 == 1 {
+				file.Seek(1, io.SeekCurrent)
+			}
+		case "data":
+			if !hasFormat {
+				return format, nil, fmt.Errorf("chunk data muncul sebelum chunk fmt")
+			}
+			data, err := io.ReadAll(io.LimitReader(file, int64(chunkSize)))
+			if err != nil {
+				return format, nil, err
+			}
+			return format, data, nil
+		default:
+			if _, err := file.Seek(int64(chunkSize)+int64(chunkSize%2), io.SeekCurrent); err != nil {
+				return format, nil, err
+			}
+		}
+	}
+}
+
+// writeWAVHeader menulis header WAV 44 byte untuk data PCM sepanjang dataSize
+func writeWAVHeader(w io.Writer, format wavFormat, dataSize uint32) error {
+	header := make([]byte, 44)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], 36+dataSize)
+	copy(header[8:12], "WAVE")
+	copy(header[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16)
+	binary.LittleEndian.PutUint16(header[20:22], format.AudioFormat)
+	binary.LittleEndian.PutUint16(header[22:24], format.Channels)
+	binary.LittleEndian.PutUint32(header[24:28], format.SampleRate)
+	binary.LittleEndian.PutUint32(header[28:32], format.ByteRate)
+	binary.LittleEndian.PutUint16(header[32:34], format.BlockAlign)
+	binary.LittleEndian.PutUint16(header[34:36], format.BitsPerSample)
+	copy(header[36:40], "data")
+	binary.LittleEndian.PutUint32(header[40:44], dataSize)
+
+	_, err := w.Write(header)
+	return err
+}
+
+// formatKeluaran adalah format WAV yang dihasilkan semua perekam
+var formatKeluaran = wavFormat{
+	AudioFormat:   1,
+	Channels:      Channels,
+	SampleRate:    SampleRate,
+	ByteRate:      SampleRate * Channels * BitsPerSample / 8,
+	BlockAlign:    Channels * BitsPerSample / 8,
+	BitsPerSample: BitsPerSample,
+}
+
+// WriteWAV menulis data PCM ke path sebagai file WAV dengan format keluaran
+// perekam (PCM 16-bit, 16 kHz, mono), misalnya untuk sumber file replay
+func WriteWAV(path string, pcm []byte) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeWAVHeader(file, formatKeluaran, uint32(len(pcm))); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Write(pcm); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// FinalizeWAV memperbaiki ukuran RIFF dan data pada header WAV yang
+// terpotong, misalnya ketika rekaman dihentikan di tengah jalan. Mengembalikan
+// jumlah byte audio yang tersimpan.
+func FinalizeWAV(path string) (int64, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	var riff [12]byte
+	if _, err := io.ReadFull(file, riff[:]); err != nil {
+		return 0, fmt.Errorf("header WAV tidak valid: %v", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return 0, fmt.Errorf("%s bukan file WAV", path)
+	}
+
+	// Cari posisi chunk data
+	offset := int64(12)
+	for offset+8 <= info.Size() {
+		var header [8]byte
+		if _, err := file.ReadAt(header[:], offset); err != nil {
+			return 0, err
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+
+		if string(header[0:4]) == "data" {
+			dataSize := info.Size() - offset - 8
+			if chunkSize == dataSize {
+				return dataSize, nil
+			}
+
+			var size [4]byte
+			binary.LittleEndian.PutUint32(size[:], uint32(dataSize))
+			if _, err := file.WriteAt(size[:], offset+4); err != nil {
+				return 0, err
+			}
+			binary.LittleEndian.PutUint32(size[:], uint32(info.Size()-8))
+			if _, err := file.WriteAt(size[:], 4); err != nil {
+				return 0, err
+			}
+			return dataSize, nil
+		}
+
+		offset += 8 + chunkSize + chunkSize%2
+	}
+
+	return 0, fmt.Errorf("chunk data tidak ditemukan di %s", path)
+}
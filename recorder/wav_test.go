@@ -0,0 +1,44 @@
+package recorder
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFinalizeWAV(t *testing.T) {
+	// Header dari perekam yang berhenti mendadak masih mencatat ukuran 0
+	var buf bytes.Buffer
+	writeWAVHeader(&buf, formatKeluaran, 0)
+	buf.Write(dataUji(1000))
+	path := filepath.Join(t.TempDir(), "terpotong.wav")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := FinalizeWAV(path)
+	if err != nil {
+		t.Fatalf("FinalizeWAV: %v", err)
+	}
+	if n != 1000 {
+		t.Errorf("FinalizeWAV = %d, want 1000", n)
+	}
+	_, data, err := readWAV(path)
+	if err != nil || len(data) != 1000 {
+		t.Errorf("readWAV setelah FinalizeWAV = %d byte, %v", len(data), err)
+	}
+
+	// Header yang sudah benar tidak diubah
+	if n, err := FinalizeWAV(path); err != nil || n != 1000 {
+		t.Errorf("FinalizeWAV kedua = %d, %v; want 1000", n, err)
+	}
+}
+
+func TestFinalizeWAVBukanWAV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bukan.wav")
+	os.WriteFile(path, []byte("bukan file audio sama sekali"), 0644)
+	if _, err := FinalizeWAV(path); err == nil {
+		t.Error("FinalizeWAV untuk file bukan WAV harus gagal")
+	}
+}
@@ -0,0 +1,599 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"CLAIRE/models"
+	"CLAIRE/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Protokol agen perekam jarak jauh. Agen di ruang kelas mengambil job dari
+// antrian yang sama dengan worker lokal (worker_id "agent:<id>"), merekam di
+// komputernya sendiri, lalu mengunggah file WAV per sesi secara bertahap.
+// Setelah semua sesi diunggah, analisis dan penutupan job dikerjakan server.
+
+// awalanWorkerAgenLike dipakai di query LIKE untuk job milik agen
+const awalanWorkerAgenLike = models.AwalanWorkerAgen + "%"
+
+// batasUkuranRekamanAgen adalah ukuran maksimal file WAV yang diterima
+const batasUkuranRekamanAgen = int64(4) << 30
+
+// Nilai default jika AGENT_OFFLINE_TIMEOUT, AGENT_LONG_POLL_MAX dan
+// AGENT_UPLOAD_GRACE tidak diatur
+const (
+	defaultAgentOfflineTimeout = 90 * time.Second
+	defaultAgentLongPoll       = 30 * time.Second
+	defaultAgentUploadGrace    = 30 * time.Minute
+)
+
+var (
+	// ErrBukanJobAgen dikembalikan ketika job tidak sedang dijalankan agen yang meminta
+	ErrBukanJobAgen = errors.New("job tidak sedang dijalankan oleh agen ini")
+
+	// ErrSesiTidakDitemukan dikembalikan ketika nomor sesi tidak ada di job
+	ErrSesiTidakDitemukan = errors.New("sesi rekaman tidak ditemukan")
+
+	// ErrStatusSesiAgen dikembalikan ketika status sesi tidak mengizinkan operasi agen
+	ErrStatusSesiAgen = errors.New("status sesi tidak mengizinkan operasi ini")
+
+	// ErrUkuranUpload dikembalikan ketika ukuran total file tidak valid
+	ErrUkuranUpload = errors.New("ukuran file rekaman tidak valid")
+)
+
+// ErrOffsetUpload dikembalikan ketika chunk tidak dimulai tepat di jumlah
+// byte yang sudah diterima server. Agen harus melanjutkan dari Offset.
+type ErrOffsetUpload struct {
+	Offset int64
+}
+
+func (e *ErrOffsetUpload) Error() string {
+	return fmt.Sprintf("offset upload tidak sesuai, server sudah menerima %d byte", e.Offset)
+}
+
+// BatasOfflineAgen adalah lama agen boleh tidak mengirim heartbeat sebelum
+// dianggap offline
+func (q *Queue) BatasOfflineAgen() time.Duration {
+	if q.cfg.AgentOfflineTimeout > 0 {
+		return q.cfg.AgentOfflineTimeout
+	}
+	return defaultAgentOfflineTimeout
+}
+
+// BatasLongPollAgen adalah waktu tunggu maksimal satu request job agen
+func (q *Queue) BatasLongPollAgen() time.Duration {
+	if q.cfg.AgentLongPollMax > 0 {
+		return q.cfg.AgentLongPollMax
+	}
+	return defaultAgentLongPoll
+}
+
+// BatasUnggahAgen adalah tambahan waktu setelah akhir jadwal sesi yang
+// diberikan kepada agen offline untuk mengunggah rekamannya
+func (q *Queue) BatasUnggahAgen() time.Duration {
+	if q.cfg.AgentUploadGrace > 0 {
+		return q.cfg.AgentUploadGrace
+	}
+	return defaultAgentUploadGrace
+}
+
+// jadwalRuanganAgen membuat subquery ID jadwal yang ruangannya memenuhi
+// kondisi pada kolom ruangans
+func (q *Queue) jadwalRuanganAgen(kondisi string, args ...interface{}) *gorm.DB {
+	return q.db.Model(&models.Jadwal{}).Select("jadwals.id").
+		Joins("JOIN ruangans ON ruangans.id = jadwals.ruangan_id AND ruangans.deleted_at IS NULL").
+		Where(kondisi, args...)
+}
+
+// bangunkanAgen membangunkan semua agen yang sedang long-poll
+func (q *Queue) bangunkanAgen() {
+	q.mu.Lock()
+	close(q.sinyalAgen)
+	q.sinyalAgen = make(chan struct{})
+	q.mu.Unlock()
+}
+
+func (q *Queue) sinyalAgenSaatIni() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.sinyalAgen
+}
+
+// ClaimForAgent mengambil satu job pending di ruangan yang dilayani agen.
+// Jika belum ada, ditunggu sampai wait habis (long-poll). Mengembalikan nil
+// jika tetap tidak ada job.
+func (q *Queue) ClaimForAgent(ctx context.Context, agent *models.RecordingAgent, wait time.Duration) (*models.RekamanJob, error) {
+	batas := time.Now().Add(wait)
+	for {
+		// Sinyal diambil sebelum mencari job supaya Enqueue di antaranya tidak terlewat
+		sinyal := q.sinyalAgenSaatIni()
+		job, err := q.claimAgen(agent)
+		if err != nil || job != nil {
+			return job, err
+		}
+
+		sisa := time.Until(batas)
+		if sisa <= 0 {
+			return nil, nil
+		}
+		// Job juga bisa kembali pending tanpa Enqueue (misalnya dipulihkan)
+		if sisa > q.pollInterval {
+			sisa = q.pollInterval
+		}
+		timer := time.NewTimer(sisa)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil
+		case <-sinyal:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (q *Queue) claimAgen(agent *models.RecordingAgent) (*models.RekamanJob, error) {
+	var kandidat []models.RekamanJob
+	result := q.db.Where("status = ?", models.JobStatusPending).
+		Where("jadwal_id IN (?)", q.jadwalRuanganAgen("ruangans.agent_id = ?", agent.ID.String())).
+		Order("tanggal_dibuat ASC").
+		Limit(5).
+		Find(&kandidat)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, kandidat := range kandidat {
+		ok, err := q.ambilJob(kandidat.ID, agent.WorkerID())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		job, err := q.loadJob(kandidat.ID)
+		if err != nil {
+			return nil, err
+		}
+		err = models.UbahStatusJadwal(q.db, job.JadwalID, models.JadwalMerekam, models.AktorRekaman,
+			fmt.Sprintf("rekaman dimulai di agen %s (job %s)", agent.Nama, job.ID), map[string]interface{}{"sedang_rekam": true})
+		if err != nil {
+			log.Printf("Gagal mengubah status jadwal %s: %v", job.JadwalID, err)
+		}
+		log.Printf("Agen %s mengambil job %s untuk jadwal %s", agent.Nama, job.ID, job.JadwalID)
+		return job, nil
+	}
+	return nil, nil
+}
+
+// JobAgen memuat job yang sedang dijalankan agen
+func (q *Queue) JobAgen(agent *models.RecordingAgent, jobID string) (*models.RekamanJob, error) {
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return nil, ErrBukanJobAgen
+	}
+	job, err := q.loadJob(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBukanJobAgen
+	}
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobStatusRunning || job.WorkerID != agent.WorkerID() {
+		return nil, ErrBukanJobAgen
+	}
+	return job, nil
+}
+
+// JobBerjalanAgen mengembalikan job yang masih tercatat berjalan di agen,
+// dipakai agen untuk melanjutkan pekerjaan setelah restart
+func (q *Queue) JobBerjalanAgen(agent *models.RecordingAgent) ([]models.RekamanJob, error) {
+	var ids []uuid.UUID
+	result := q.db.Model(&models.RekamanJob{}).
+		Where("status = ? AND worker_id = ?", models.JobStatusRunning, agent.WorkerID()).
+		Order("tanggal_dibuat ASC").
+		Pluck("id", &ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	jobs := make([]models.RekamanJob, 0, len(ids))
+	for _, id := range ids {
+		job, err := q.loadJob(id)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, nil
+}
+
+// JobPerluBerhenti memeriksa job yang dilaporkan agen sedang berjalan dan
+// mengembalikan job yang harus dihentikan: dihentikan lewat API, sudah
+// tidak dimiliki agen (misalnya dipulihkan saat agen offline), atau tidak ada.
+func (q *Queue) JobPerluBerhenti(agent *models.RecordingAgent, aktif []uuid.UUID) ([]uuid.UUID, error) {
+	hentikan := []uuid.UUID{}
+	if len(aktif) == 0 {
+		return hentikan, nil
+	}
+
+	var jobs []models.RekamanJob
+	if err := q.db.Where("id IN ?", aktif).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	ditemukan := make(map[uuid.UUID]models.RekamanJob, len(jobs))
+	for _, job := range jobs {
+		ditemukan[job.ID] = job
+	}
+
+	for _, id := range aktif {
+		job, ok := ditemukan[id]
+		if !ok || job.Status != models.JobStatusRunning || job.WorkerID != agent.WorkerID() || job.StopDiminta {
+			hentikan = append(hentikan, id)
+		}
+	}
+	return hentikan, nil
+}
+
+// mintaBerhentiAgen menandai job di agen supaya dihentikan. Agen menyimpan
+// sesi yang sedang direkam sebagai rekaman parsial lalu menutup job.
+func (q *Queue) mintaBerhentiAgen(job models.RekamanJob) (*models.RekamanJob, error) {
+	result := q.db.Model(&models.RekamanJob{}).
+		Where("id = ? AND status = ?", job.ID, models.JobStatusRunning).
+		Updates(map[string]interface{}{
+			"stop_diminta":     true,
+			"tanggal_diupdate": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	log.Printf("Penghentian job %s diminta ke %s", job.ID, job.WorkerID)
+	return q.loadJob(job.ID)
+}
+
+func cariSesi(job *models.RekamanJob, nomor int) (*models.RekamanSesi, error) {
+	for i := range job.Sesi {
+		if job.Sesi[i].NomorSesi == nomor {
+			return &job.Sesi[i], nil
+		}
+	}
+	return nil, ErrSesiTidakDitemukan
+}
+
+// MulaiSesiAgen mencatat bahwa agen mulai merekam sesi. Sesi yang sudah
+// berstatus recording boleh dimulai lagi ketika agen melanjutkan setelah
+// restart; path file di server tidak berubah.
+func (q *Queue) MulaiSesiAgen(job *models.RekamanJob, nomor int) (*models.RekamanSesi, error) {
+	sesi, err := cariSesi(job, nomor)
+	if err != nil {
+		return nil, err
+	}
+	if job.StopDiminta {
+		return nil, ErrDihentikan
+	}
+
+	switch sesi.Status {
+	case models.SesiStatusPending:
+		q.updateSesi(sesi, map[string]interface{}{
+			"status":          models.SesiStatusRecording,
+			"path_file_audio": q.pathRekaman(job, sesi),
+			"waktu_mulai":     time.Now(),
+		})
+	case models.SesiStatusRecording:
+	default:
+		return nil, ErrStatusSesiAgen
+	}
+	return sesi, nil
+}
+
+// GagalSesiAgen mencatat sesi yang gagal direkam di agen
+func (q *Queue) GagalSesiAgen(job *models.RekamanJob, nomor int, pesan string) (*models.RekamanSesi, error) {
+	sesi, err := cariSesi(job, nomor)
+	if err != nil {
+		return nil, err
+	}
+	if sesi.Status != models.SesiStatusPending && sesi.Status != models.SesiStatusRecording {
+		return nil, ErrStatusSesiAgen
+	}
+	if pesan == "" {
+		pesan = "rekaman gagal di agen"
+	}
+	q.failSesi(sesi, errors.New(pesan))
+	return sesi, nil
+}
+
+// OffsetUploadAgen mengembalikan jumlah byte rekaman sesi yang sudah
+// diterima. selesai bernilai true jika file sudah lengkap.
+func (q *Queue) OffsetUploadAgen(job *models.RekamanJob, nomor int) (offset int64, selesai bool, err error) {
+	sesi, err := cariSesi(job, nomor)
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch sesi.Status {
+	case models.SesiStatusUploaded, models.SesiStatusAnalyzed:
+		info, err := q.store.Stat(q.ctx, sesi.PathFileAudio)
+		if err != nil {
+			return 0, true, nil
+		}
+		return info.Size, true, nil
+	case models.SesiStatusRecording:
+		return sesi.BytesDiterima, false, nil
+	default:
+		return 0, false, ErrStatusSesiAgen
+	}
+}
+
+// TulisChunkAgen menyimpan satu potongan file rekaman sebesar ukuran byte
+// mulai dari offset mulai. total adalah ukuran file lengkap; begitu semua
+// byte diterima, potongan digabung ke key sesi dan sesi siap dianalisis.
+// parsial menandai rekaman yang dihentikan sebelum durasi sesi selesai.
+//
+// Offset dicatat di database dengan update bersyarat sehingga potongan
+// yang sama aman diterima replika server yang berbeda.
+func (q *Queue) TulisChunkAgen(job *models.RekamanJob, nomor int, mulai, ukuran, total int64, parsial bool, body io.Reader) (offset int64, selesai bool, err error) {
+	sesi, err := cariSesi(job, nomor)
+	if err != nil {
+		return 0, false, err
+	}
+	if total <= 0 || total > batasUkuranRekamanAgen || mulai < 0 || ukuran <= 0 || mulai+ukuran > total {
+		return 0, false, ErrUkuranUpload
+	}
+
+	switch sesi.Status {
+	case models.SesiStatusUploaded, models.SesiStatusAnalyzed:
+		// Chunk terakhir dikirim ulang karena response sebelumnya hilang
+		return total, true, nil
+	case models.SesiStatusRecording:
+	default:
+		return 0, false, ErrStatusSesiAgen
+	}
+
+	if sesi.BytesDiterima != mulai {
+		return sesi.BytesDiterima, false, &ErrOffsetUpload{Offset: sesi.BytesDiterima}
+	}
+
+	// Potongan ditulis dulu, offset baru dimajukan setelah tersimpan agar
+	// offset tidak pernah mencakup byte yang belum ada. Potongan dengan
+	// offset sama yang dikirim ulang menimpa isi yang sama; update bersyarat
+	// memastikan hanya satu yang memajukan offset.
+	akhir := mulai + ukuran
+	prefix := sesi.PathFileAudio + ".part"
+	if err := storage.TulisPotongan(q.ctx, q.store, prefix, mulai, body, ukuran); err != nil {
+		return mulai, false, err
+	}
+	result := q.db.Model(&models.RekamanSesi{}).
+		Where("id = ? AND status = ? AND bytes_diterima = ?", sesi.ID, models.SesiStatusRecording, mulai).
+		Updates(map[string]interface{}{"bytes_diterima": akhir, "tanggal_diupdate": time.Now()})
+	if result.Error != nil {
+		return mulai, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		var terbaru models.RekamanSesi
+		if err := q.db.First(&terbaru, "id = ?", sesi.ID).Error; err != nil {
+			return 0, false, err
+		}
+		if terbaru.Status == models.SesiStatusUploaded || terbaru.Status == models.SesiStatusAnalyzed {
+			return total, true, nil
+		}
+		return terbaru.BytesDiterima, false, &ErrOffsetUpload{Offset: terbaru.BytesDiterima}
+	}
+
+	sesi.BytesDiterima = akhir
+	if akhir < total {
+		return akhir, false, nil
+	}
+
+	rc := storage.BacaPotongan(q.ctx, q.store, prefix, total)
+	err = q.store.Put(q.ctx, sesi.PathFileAudio, rc, total)
+	rc.Close()
+	if err != nil {
+		// Potongan tetap disimpan; agen mengirim ulang potongan terakhir
+		q.db.Model(&models.RekamanSesi{}).
+			Where("id = ? AND bytes_diterima = ?", sesi.ID, akhir).
+			Update("bytes_diterima", mulai)
+		return mulai, false, err
+	}
+	if err := q.store.DeletePrefix(q.ctx, prefix); err != nil {
+		log.Printf("Gagal menghapus potongan upload %s: %v", prefix, err)
+	}
+
+	updates := map[string]interface{}{
+		"status": models.SesiStatusUploaded,
+	}
+	if parsial {
+		updates["pesan_error"] = "rekaman parsial: dihentikan sebelum durasi sesi selesai"
+	}
+	q.updateSesi(sesi, updates)
+	log.Printf("Rekaman sesi %d job %s diterima dari agen: %s", sesi.NomorSesi, job.ID, sesi.PathFileAudio)
+	return total, true, nil
+}
+
+// SelesaiAgen dipanggil agen setelah semua sesi direkam dan diunggah. Job
+// diambil alih server: sesi yang sudah diunggah dianalisis di latar
+// belakang lalu job ditutup. pesan diisi agen jika job berakhir karena error.
+// Panggilan berulang untuk job yang sama diabaikan.
+func (q *Queue) SelesaiAgen(job *models.RekamanJob, pesan string) error {
+	result := q.db.Model(&models.RekamanJob{}).
+		Where("id = ? AND status = ? AND worker_id = ?", job.ID, models.JobStatusRunning, job.WorkerID).
+		Updates(map[string]interface{}{
+			"worker_id":        q.workerPrefix + "-agen",
+			"tanggal_diupdate": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	terbaru, err := q.loadJob(job.ID)
+	if err != nil {
+		return err
+	}
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		q.tutupJobAgen(terbaru, pesan)
+	}()
+	return nil
+}
+
+func (q *Queue) tutupJobAgen(job *models.RekamanJob, pesan string) {
+	ctx := q.ctx
+	for i := range job.Sesi {
+		sesi := &job.Sesi[i]
+		switch {
+		case sesi.Status == models.SesiStatusRecording:
+			q.failSesi(sesi, errors.New("upload rekaman dari agen tidak selesai"))
+		case sesi.Status == models.SesiStatusPending && !job.StopDiminta:
+			alasan := pesan
+			if alasan == "" {
+				alasan = "sesi tidak direkam oleh agen"
+			}
+			q.failSesi(sesi, errors.New(alasan))
+		}
+	}
+	if job.StopDiminta {
+		q.cancelPending(job)
+	}
+
+	for i := range job.Sesi {
+		if job.Sesi[i].Status == models.SesiStatusUploaded {
+			q.analyzeSession(ctx, job, &job.Sesi[i])
+		}
+	}
+	if ctx.Err() != nil {
+		// Server berhenti: job dipulihkan saat start berikutnya
+		return
+	}
+
+	var jobErr error
+	if job.StopDiminta {
+		jobErr = ErrDihentikan
+	} else if pesan != "" && !hasAnalyzed(job) {
+		jobErr = errors.New(pesan)
+	}
+	q.finish(job, jobErr)
+}
+
+// awasiAgen memeriksa agen yang berhenti mengirim heartbeat secara berkala
+func (q *Queue) awasiAgen(ctx context.Context) {
+	defer q.wg.Done()
+
+	timeout := q.BatasOfflineAgen()
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.periksaAgenOffline(timeout)
+		}
+	}
+}
+
+// periksaAgenOffline memulihkan job milik agen yang offline seperti job yang
+// terputus saat server restart. Agen tetap merekam walaupun koneksinya
+// putus, jadi job yang masih punya sesi recording dibiarkan milik agen
+// sampai akhir jadwal sesi ditambah BatasUnggahAgen; jika agen online lagi
+// dalam rentang itu, rekamannya diterima seperti biasa. Job pending di ruangan yang agennya tidak
+// kunjung online diambil alih server supaya jadwal tidak terkunci: sesi yang
+// sudah diunggah tetap dianalisis, sisanya ditandai gagal.
+func (q *Queue) periksaAgenOffline(timeout time.Duration) {
+	now := time.Now()
+
+	var pending []models.RekamanJob
+	q.db.Where("status = ? AND tanggal_diupdate < ?", models.JobStatusPending, now.Add(-timeout)).
+		Where("jadwal_id IN (?)", q.jadwalRuanganAgen("ruangans.agent_id <> ''")).
+		Find(&pending)
+	for _, job := range pending {
+		var agentID string
+		q.db.Model(&models.Ruangan{}).Select("ruangans.agent_id").
+			Joins("JOIN jadwals ON jadwals.ruangan_id = ruangans.id").
+			Where("jadwals.id = ?", job.JadwalID).
+			Scan(&agentID)
+		if !q.agenOnline(agentID, now, timeout) {
+			q.ambilAlihJob(job.ID, "agen perekam ruangan offline")
+		}
+	}
+
+	var berjalan []models.RekamanJob
+	q.db.Preload("Jadwal").Preload("Sesi").
+		Where("status = ? AND worker_id LIKE ?", models.JobStatusRunning, awalanWorkerAgenLike).
+		Find(&berjalan)
+	for _, job := range berjalan {
+		agentID, _ := models.AgenDariWorkerID(job.WorkerID)
+		if q.agenOnline(agentID.String(), now, timeout) || q.menungguUnggahAgen(&job, now) {
+			continue
+		}
+		if err := q.recoverJob(job, "agen perekam offline"); err != nil {
+			log.Printf("Gagal memulihkan job %s dari agen offline: %v", job.ID, err)
+			continue
+		}
+		var selesai int64
+		q.db.Model(&models.RekamanJob{}).
+			Where("id = ? AND status IN ?", job.ID, []string{models.JobStatusFailed, models.JobStatusCompleted}).
+			Count(&selesai)
+		if selesai > 0 {
+			releaseJadwal(q.db, job.JadwalID)
+		}
+	}
+}
+
+// menungguUnggahAgen mengecek apakah job agen masih punya sesi recording
+// yang jadwal akhirnya ditambah BatasUnggahAgen belum lewat
+func (q *Queue) menungguUnggahAgen(job *models.RekamanJob, now time.Time) bool {
+	durasi := time.Duration(job.DurasiSesi) * time.Second
+	for _, sesi := range job.Sesi {
+		if sesi.Status != models.SesiStatusRecording {
+			continue
+		}
+		mulai := sesi.TanggalDiupdate
+		if sesi.WaktuMulai != nil {
+			mulai = *sesi.WaktuMulai
+		}
+		if now.Before(mulai.Add(durasi + q.BatasUnggahAgen())) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *Queue) agenOnline(agentID string, now time.Time, timeout time.Duration) bool {
+	var agent models.RecordingAgent
+	if err := q.db.First(&agent, "id = ?", agentID).Error; err != nil {
+		return false
+	}
+	return agent.OnlinePada(now, timeout)
+}
+
+// ambilAlihJob menutup job pending yang tidak bisa dikerjakan agennya
+func (q *Queue) ambilAlihJob(id uuid.UUID, pesan string) {
+	ok, err := q.ambilJob(id, q.workerPrefix+"-agen")
+	if err != nil {
+		log.Printf("Gagal mengambil alih job %s: %v", id, err)
+	}
+	if !ok {
+		return
+	}
+
+	job, err := q.loadJob(id)
+	if err != nil {
+		log.Printf("Gagal memuat job %s: %v", id, err)
+		return
+	}
+	log.Printf("Job %s diambil alih server: %s", id, pesan)
+	q.tutupJobAgen(job, pesan)
+}
@@ -0,0 +1,368 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+	"CLAIRE/recorder"
+	"CLAIRE/storage"
+	"CLAIRE/utils"
+)
+
+// execute menjalankan semua sesi job yang belum selesai. Sesi yang sudah
+// "uploaded" (misalnya setelah restart) langsung dianalisis tanpa direkam ulang.
+func (q *Queue) execute(parent context.Context, job *models.RekamanJob) {
+	ctx, run := q.track(parent, job)
+	defer q.untrack(job, run)
+
+	var stopOnce sync.Once
+	recordingStopped := func() {
+		stopOnce.Do(func() { close(run.stopped) })
+	}
+	defer recordingStopped()
+
+	jadwal := job.Jadwal
+	log.Printf("Worker %s memulai job %s untuk jadwal %s", job.WorkerID, job.ID, jadwal.ID)
+
+	// Update status jadwal menjadi sedang merekam
+	err := models.UbahStatusJadwal(q.db, jadwal.ID, models.JadwalMerekam, models.AktorRekaman,
+		fmt.Sprintf("rekaman otomatis dimulai (job %s)", job.ID), map[string]interface{}{"sedang_rekam": true})
+	if err != nil {
+		log.Printf("Gagal mengubah status jadwal %s: %v", jadwal.ID, err)
+	}
+
+	// Buat direktori recordings jika belum ada
+	os.MkdirAll(q.cfg.RecordingDir, 0755)
+
+	// Pilih perekam sesuai ruangan jadwal
+	rec, err := q.recorderFor(jadwal)
+	if err != nil {
+		q.finish(job, fmt.Errorf("gagal menyiapkan perekam: %v", err))
+		return
+	}
+
+	duration := time.Duration(job.DurasiSesi) * time.Second
+	gap := time.Duration(job.JedaSesi) * time.Second
+	recorded := false
+	for i := range job.Sesi {
+		sesi := &job.Sesi[i]
+		if ctx.Err() != nil {
+			break
+		}
+
+		if sesi.Status == models.SesiStatusPending {
+			// Jeda antar sesi sesuai kebijakan rekaman
+			if recorded && gap > 0 {
+				select {
+				case <-ctx.Done():
+					continue
+				case <-time.After(gap):
+				}
+			}
+			q.recordSession(ctx, rec, job, sesi, duration)
+			recorded = true
+		}
+		if sesi.Status == models.SesiStatusUploaded {
+			q.analyzeSession(ctx, job, sesi)
+		}
+	}
+
+	if ctx.Err() != nil && !isStopped(ctx) {
+		// Server berhenti: biarkan job "running" agar dipulihkan saat start
+		return
+	}
+
+	if isStopped(ctx) {
+		// Dihentikan lewat API: sesi yang belum direkam dibatalkan, rekaman
+		// yang sudah tersimpan (termasuk parsial) tetap dianalisis
+		q.cancelPending(job)
+		recordingStopped()
+
+		for i := range job.Sesi {
+			if job.Sesi[i].Status == models.SesiStatusUploaded {
+				q.analyzeSession(context.Background(), job, &job.Sesi[i])
+			}
+		}
+		q.finish(job, ErrDihentikan)
+		return
+	}
+
+	recordingStopped()
+	q.finish(job, nil)
+}
+
+// recorderFor memilih perekam untuk ruangan jadwal. Perangkat yang
+// terdaftar di tabel ruangans didahulukan, selain itu memakai pemetaan
+// RECORDER_ROOM_DEVICES atau backend global.
+func (q *Queue) recorderFor(jadwal models.Jadwal) (recorder.Recorder, error) {
+	var ruangan models.Ruangan
+	query := q.db.Model(&models.Ruangan{})
+	if jadwal.RuanganID != nil {
+		query = query.Where("id = ?", *jadwal.RuanganID)
+	} else {
+		query = query.Where("UPPER(kode) = ?", models.NormalisasiKodeRuangan(jadwal.Ruangan))
+	}
+
+	if err := query.First(&ruangan).Error; err == nil {
+		if !ruangan.Aktif {
+			return nil, fmt.Errorf("ruangan %s tidak aktif", ruangan.Kode)
+		}
+		if ruangan.RecorderBackend != "" {
+			log.Printf("Memakai perekam ruangan %s: %s %s", ruangan.Kode, ruangan.RecorderBackend, ruangan.RecorderDevice)
+			return recorder.FromRegistry(q.cfg, ruangan.RecorderBackend, ruangan.RecorderDevice)
+		}
+	}
+	return recorder.ForRoom(q.cfg, jadwal.Ruangan)
+}
+
+// pathRekaman menentukan key storage file WAV sebuah sesi. Key memakai
+// RECORDING_DIR sebagai prefix agar sama dengan path lama; jika RECORDING_DIR
+// berupa path absolut, prefix "recordings" yang dipakai.
+func (q *Queue) pathRekaman(job *models.RekamanJob, sesi *models.RekamanSesi) string {
+	timestamp := time.Now().Format("20060102_150405")
+	filename := fmt.Sprintf("recording_%s_session%d_%s.wav", job.JadwalID, sesi.NomorSesi, timestamp)
+	prefix, err := storage.BersihkanKey(filepath.ToSlash(q.cfg.RecordingDir))
+	if err != nil {
+		prefix = "recordings"
+	}
+	return storage.Key(prefix, filename)
+}
+
+// pathKerja adalah lokasi file rekaman di disk selama perekam berjalan
+func (q *Queue) pathKerja(key string) string {
+	return filepath.Join(q.cfg.RecordingDir, path.Base(key))
+}
+
+func (q *Queue) recordSession(ctx context.Context, rec recorder.Recorder, job *models.RekamanJob, sesi *models.RekamanSesi, duration time.Duration) {
+	key := q.pathRekaman(job, sesi)
+	lokal := q.pathKerja(key)
+
+	now := time.Now()
+	q.updateSesi(sesi, map[string]interface{}{
+		"status":          models.SesiStatusRecording,
+		"path_file_audio": key,
+		"waktu_mulai":     now,
+	})
+	log.Printf("Starting recording session %d for jadwal %s with %s", sesi.NomorSesi, job.JadwalID, rec.Name())
+
+	if err := rec.Record(ctx, lokal, duration); err != nil {
+		if isStopped(ctx) {
+			q.keepPartial(sesi)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		q.failSesi(sesi, fmt.Errorf("error recording audio: %v", err))
+		return
+	}
+
+	if err := storage.SimpanFile(context.Background(), q.store, key, lokal); err != nil {
+		q.failSesi(sesi, fmt.Errorf("gagal menyimpan rekaman ke storage: %v", err))
+		return
+	}
+
+	q.updateSesi(sesi, map[string]interface{}{
+		"status": models.SesiStatusUploaded,
+	})
+	log.Printf("Recording session %d completed: %s", sesi.NomorSesi, key)
+}
+
+// keepPartial menyimpan rekaman yang dihentikan di tengah sesi. File WAV
+// dirapikan dulu; jika tidak berisi audio sama sekali, sesi ditandai gagal.
+func (q *Queue) keepPartial(sesi *models.RekamanSesi) {
+	lokal := q.pathKerja(sesi.PathFileAudio)
+	size, err := recorder.FinalizeWAV(lokal)
+	if err != nil || size == 0 {
+		q.failSesi(sesi, fmt.Errorf("rekaman dihentikan sebelum ada audio yang tersimpan"))
+		return
+	}
+	if err := storage.SimpanFile(context.Background(), q.store, sesi.PathFileAudio, lokal); err != nil {
+		q.failSesi(sesi, fmt.Errorf("gagal menyimpan rekaman ke storage: %v", err))
+		return
+	}
+
+	q.updateSesi(sesi, map[string]interface{}{
+		"status":      models.SesiStatusUploaded,
+		"pesan_error": "rekaman parsial: dihentikan sebelum durasi sesi selesai",
+	})
+	log.Printf("Recording session %d stopped, partial file kept: %s", sesi.NomorSesi, sesi.PathFileAudio)
+}
+
+// cancelPending menandai sesi yang belum sempat direkam sebagai dibatalkan
+func (q *Queue) cancelPending(job *models.RekamanJob) {
+	for i := range job.Sesi {
+		if job.Sesi[i].Status == models.SesiStatusPending {
+			q.updateSesi(&job.Sesi[i], map[string]interface{}{
+				"status": models.SesiStatusCancelled,
+			})
+		}
+	}
+}
+
+func (q *Queue) analyzeSession(ctx context.Context, job *models.RekamanJob, sesi *models.RekamanSesi) {
+	// File kerja lokal dipakai jika masih ada, selain itu diunduh dari storage
+	file, hapus, err := q.fileAnalisis(ctx, sesi)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		q.failSesi(sesi, fmt.Errorf("error membaca rekaman dari storage: %v", err))
+		return
+	}
+	defer hapus()
+	q.catatMetadata(ctx, sesi, file)
+
+	// Kirim ke Python backend untuk analisis
+	result, err := q.analyzer.Analyze(ctx, file)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		q.failSesi(sesi, fmt.Errorf("error sending audio for analysis: %v", err))
+		return
+	}
+
+	// Hubungkan evaluasi ke pertemuan pada hari job dimulai
+	kal := kalender.Default()
+	tanggal := kal.TanggalHariIni()
+	if job.WaktuMulai != nil {
+		tanggal = kal.Tanggal(*job.WaktuMulai)
+	}
+
+	// Simpan hasil analisis ke tabel Evaluasi
+	evaluasi := models.Evaluasi{
+		JadwalID:             job.JadwalID,
+		PertemuanID:          models.CariPertemuanID(q.db, job.JadwalID, tanggal),
+		KepercayaanPembicara: result.Similarity,
+		TeksTranskripsi:      result.Transcript,
+		Rangkuman:            result.Analysis.Summary,
+		SkorEfektivitas:      float64(result.Analysis.Effectiveness) / 100.0,
+		PathFileAudio:        sesi.PathFileAudio,
+		WaktuPemrosesan:      0,
+	}
+	if err := q.db.Create(&evaluasi).Error; err != nil {
+		q.failSesi(sesi, fmt.Errorf("error saving evaluasi: %v", err))
+		return
+	}
+
+	q.updateSesi(sesi, map[string]interface{}{
+		"status":        models.SesiStatusAnalyzed,
+		"evaluasi_id":   evaluasi.ID,
+		"waktu_selesai": time.Now(),
+	})
+	log.Printf("Evaluation saved successfully for session %d", sesi.NomorSesi)
+	q.hapusFileKerja(sesi)
+}
+
+// catatMetadata menyimpan durasi dan level audio rekaman sesi agar rekaman
+// yang hening atau clipping terlihat sebelum hasil analisis keluar
+func (q *Queue) catatMetadata(ctx context.Context, sesi *models.RekamanSesi, file string) {
+	berkas := utils.MetadataAudio(ctx, sesi.PathFileAudio, file, "")
+	if err := models.SimpanAudioFile(q.db, berkas); err != nil {
+		log.Printf("Gagal menyimpan metadata rekaman sesi %d: %v", sesi.NomorSesi, err)
+		return
+	}
+	if berkas.PesanError != "" {
+		log.Printf("Metadata rekaman sesi %d tidak terbaca: %s", sesi.NomorSesi, berkas.PesanError)
+	} else if berkas.RasioHening != nil && *berkas.RasioHening >= 0.95 {
+		log.Printf("Peringatan: rekaman sesi %d hampir seluruhnya hening (%.0f%%)", sesi.NomorSesi, *berkas.RasioHening*100)
+	}
+}
+
+// fileAnalisis menyediakan rekaman sesi sebagai file di disk untuk layanan
+// analisis. hapus wajib dipanggil setelah analisis selesai.
+func (q *Queue) fileAnalisis(ctx context.Context, sesi *models.RekamanSesi) (string, func(), error) {
+	lokal := q.pathKerja(sesi.PathFileAudio)
+	if _, err := os.Stat(lokal); err == nil {
+		return lokal, func() {}, nil
+	}
+	return storage.UnduhSementara(ctx, q.store, sesi.PathFileAudio)
+}
+
+// hapusFileKerja membuang file kerja setelah rekaman tersimpan di store,
+// kecuali file itu sendiri yang disimpan oleh store lokal
+func (q *Queue) hapusFileKerja(sesi *models.RekamanSesi) {
+	lokal := q.pathKerja(sesi.PathFileAudio)
+	infoKerja, err := os.Stat(lokal)
+	if err != nil {
+		return
+	}
+	if p, ok := storage.PathLokal(q.store, sesi.PathFileAudio); ok {
+		if info, err := os.Stat(p); err == nil && os.SameFile(info, infoKerja) {
+			return
+		}
+	}
+	os.Remove(lokal)
+}
+
+// finish menutup job dan mengembalikan status jadwal
+func (q *Queue) finish(job *models.RekamanJob, jobErr error) {
+	status := models.JobStatusCompleted
+	pesan := ""
+	if errors.Is(jobErr, ErrDihentikan) {
+		status = models.JobStatusCancelled
+		pesan = jobErr.Error()
+	} else if jobErr != nil {
+		status = models.JobStatusFailed
+		pesan = jobErr.Error()
+	} else if !hasAnalyzed(job) {
+		status = models.JobStatusFailed
+		pesan = "tidak ada sesi yang berhasil dianalisis"
+	}
+
+	q.db.Model(&models.RekamanJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"status":           status,
+		"pesan_error":      pesan,
+		"waktu_selesai":    time.Now(),
+		"tanggal_diupdate": time.Now(),
+	})
+	job.Status = status
+
+	releaseJadwal(q.db, job.JadwalID)
+	if pesan != "" {
+		log.Printf("Auto recording %s for jadwal %s: %s", status, job.JadwalID, pesan)
+	} else {
+		log.Printf("Auto recording %s for jadwal %s", status, job.JadwalID)
+	}
+}
+
+func (q *Queue) updateSesi(sesi *models.RekamanSesi, updates map[string]interface{}) {
+	updates["tanggal_diupdate"] = time.Now()
+	if err := q.db.Model(&models.RekamanSesi{}).Where("id = ?", sesi.ID).Updates(updates).Error; err != nil {
+		log.Printf("Gagal update sesi %s: %v", sesi.ID, err)
+	}
+	if status, ok := updates["status"].(string); ok {
+		sesi.Status = status
+	}
+	if path, ok := updates["path_file_audio"].(string); ok {
+		sesi.PathFileAudio = path
+	}
+}
+
+func (q *Queue) failSesi(sesi *models.RekamanSesi, err error) {
+	log.Printf("Sesi %d gagal: %v", sesi.NomorSesi, err)
+	q.updateSesi(sesi, map[string]interface{}{
+		"status":        models.SesiStatusFailed,
+		"pesan_error":   err.Error(),
+		"waktu_selesai": time.Now(),
+	})
+}
+
+func hasAnalyzed(job *models.RekamanJob) bool {
+	for _, sesi := range job.Sesi {
+		if sesi.Status == models.SesiStatusAnalyzed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"CLAIRE/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrDihentikan adalah penyebab pembatalan context ketika job dihentikan
+// lewat API, untuk membedakannya dari server yang sedang shutdown
+var ErrDihentikan = errors.New("rekaman dihentikan")
+
+// ErrTidakAdaJob dikembalikan ketika jadwal tidak punya job yang aktif
+var ErrTidakAdaJob = errors.New("tidak ada job rekaman aktif untuk jadwal ini")
+
+// ErrJobDiWorkerLain dikembalikan ketika job aktif dijalankan proses lain
+var ErrJobDiWorkerLain = errors.New("job rekaman sedang dijalankan oleh worker lain")
+
+// stopTimeout adalah batas waktu menunggu perekam berhenti dan file WAV
+// parsial selesai ditulis
+const stopTimeout = 30 * time.Second
+
+// detakJob adalah interval worker memperbarui tanggal_diupdate job yang
+// sedang dijalankannya sebagai tanda job masih hidup
+const detakJob = 30 * time.Second
+
+// batasDetakJob adalah umur detak terakhir sebelum job running milik worker
+// lain dianggap ditinggalkan oleh proses yang mati
+const batasDetakJob = 3 * detakJob
+
+// runningJob adalah job yang sedang dieksekusi di proses ini
+type runningJob struct {
+	jobID   uuid.UUID
+	cancel  context.CancelCauseFunc
+	stopped chan struct{} // ditutup ketika fase rekaman berakhir
+	selesai chan struct{} // ditutup ketika eksekusi job berakhir
+}
+
+// track mendaftarkan job yang mulai dieksekusi dan mengembalikan context
+// yang dibatalkan ketika job dihentikan
+func (q *Queue) track(parent context.Context, job *models.RekamanJob) (context.Context, *runningJob) {
+	ctx, cancel := context.WithCancelCause(parent)
+	run := &runningJob{
+		jobID:   job.ID,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+		selesai: make(chan struct{}),
+	}
+
+	q.mu.Lock()
+	q.running[job.JadwalID] = run
+	q.mu.Unlock()
+
+	go q.detak(job.ID, run.selesai)
+	return ctx, run
+}
+
+// detak memperbarui tanggal_diupdate job secara berkala sampai eksekusinya
+// berakhir, supaya Recover di proses lain tidak menganggap job terputus
+func (q *Queue) detak(jobID uuid.UUID, selesai <-chan struct{}) {
+	ticker := time.NewTicker(detakJob)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-selesai:
+			return
+		case <-ticker.C:
+			q.db.Model(&models.RekamanJob{}).
+				Where("id = ? AND status = ?", jobID, models.JobStatusRunning).
+				UpdateColumn("tanggal_diupdate", time.Now())
+		}
+	}
+}
+
+// untrack menghapus job dari daftar job yang berjalan
+func (q *Queue) untrack(job *models.RekamanJob, run *runningJob) {
+	q.mu.Lock()
+	if q.running[job.JadwalID] == run {
+		delete(q.running, job.JadwalID)
+	}
+	q.mu.Unlock()
+	run.cancel(nil)
+	close(run.selesai)
+}
+
+// Running mengembalikan ID jadwal yang sedang direkam oleh proses ini
+func (q *Queue) Running() []uuid.UUID {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ids := make([]uuid.UUID, 0, len(q.running))
+	for id := range q.running {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Stop menghentikan job rekaman aktif untuk jadwal. Job yang masih pending
+// langsung dibatalkan; job yang sedang berjalan dihentikan melalui context,
+// sesi yang sedang direkam disimpan sebagai rekaman parsial. Stop menunggu
+// sampai perekam benar-benar berhenti lalu mengembalikan kondisi job terakhir.
+// Job di agen perekam hanya ditandai stop_diminta; agen menghentikannya
+// setelah menerima tanda itu lewat heartbeat.
+func (q *Queue) Stop(jadwalID uuid.UUID) (*models.RekamanJob, error) {
+	q.mu.Lock()
+	run, ok := q.running[jadwalID]
+	q.mu.Unlock()
+
+	if ok {
+		run.cancel(ErrDihentikan)
+		select {
+		case <-run.stopped:
+		case <-time.After(stopTimeout):
+		}
+		return q.loadJob(run.jobID)
+	}
+
+	// Job belum diambil worker: batalkan langsung di database
+	var job models.RekamanJob
+	err := q.db.Where("jadwal_id = ? AND status IN ?", jadwalID,
+		[]string{models.JobStatusPending, models.JobStatusRunning}).
+		Order("tanggal_dibuat DESC").First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTidakAdaJob
+	}
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == models.JobStatusRunning {
+		if _, diAgen := models.AgenDariWorkerID(job.WorkerID); diAgen {
+			return q.mintaBerhentiAgen(job)
+		}
+		return nil, ErrJobDiWorkerLain
+	}
+
+	now := time.Now()
+	err = q.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RekamanJob{}).
+			Where("id = ? AND status = ?", job.ID, models.JobStatusPending).
+			Updates(map[string]interface{}{
+				"status":           models.JobStatusCancelled,
+				"pesan_error":      ErrDihentikan.Error(),
+				"waktu_selesai":    now,
+				"tanggal_diupdate": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Baru saja diambil worker
+			return ErrJobDiWorkerLain
+		}
+		return tx.Model(&models.RekamanSesi{}).
+			Where("job_id = ? AND status = ?", job.ID, models.SesiStatusPending).
+			Updates(map[string]interface{}{
+				"status":           models.SesiStatusCancelled,
+				"tanggal_diupdate": now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	releaseJadwal(q.db, jadwalID)
+	return q.loadJob(job.ID)
+}
+
+// isStopped melaporkan apakah ctx dibatalkan karena job dihentikan lewat API
+func isStopped(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrDihentikan)
+}
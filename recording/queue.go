@@ -0,0 +1,256 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"CLAIRE/analysis"
+	"CLAIRE/config"
+	"CLAIRE/models"
+	"CLAIRE/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrJobAktif dikembalikan ketika jadwal masih punya job yang belum selesai
+var ErrJobAktif = errors.New("jadwal masih memiliki job rekaman yang aktif")
+
+// Queue adalah antrian job rekaman yang disimpan di database. Worker
+// mengambil job pending dari tabel rekaman_jobs sehingga job tidak hilang
+// ketika server restart.
+type Queue struct {
+	db           *gorm.DB
+	cfg          *config.Config
+	analyzer     analysis.AnalysisClient
+	store        storage.BlobStore
+	workers      int
+	pollInterval time.Duration
+	workerPrefix string
+	wake         chan struct{}
+	wg           sync.WaitGroup
+	mu           sync.Mutex
+	running      map[uuid.UUID]*runningJob // key: jadwal ID
+
+	// Agen perekam jarak jauh
+	ctx        context.Context
+	sinyalAgen chan struct{} // ditutup lalu diganti setiap ada job baru
+}
+
+// NewQueue membuat Queue dari konfigurasi
+func NewQueue(db *gorm.DB, cfg *config.Config, analyzer analysis.AnalysisClient) *Queue {
+	workers := cfg.RecordingWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	pollInterval := cfg.RecordingPollInterval
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+
+	hostname, _ := os.Hostname()
+	return &Queue{
+		db:           db,
+		cfg:          cfg,
+		analyzer:     analyzer,
+		store:        storage.NewLocal(""),
+		workers:      workers,
+		pollInterval: pollInterval,
+		workerPrefix: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		wake:         make(chan struct{}, 1),
+		running:      make(map[uuid.UUID]*runningJob),
+		ctx:          context.Background(),
+		sinyalAgen:   make(chan struct{}),
+	}
+}
+
+// SetStore memasang penyimpanan hasil rekaman. Rekaman tetap ditulis ke
+// RECORDING_DIR selama berlangsung lalu disimpan ke store setelah selesai.
+func (q *Queue) SetStore(store storage.BlobStore) {
+	q.store = store
+}
+
+// Start menjalankan worker pool dan pemantau agen perekam sampai ctx
+// dibatalkan
+func (q *Queue) Start(ctx context.Context) {
+	q.ctx = ctx
+	q.wg.Add(1)
+	go q.awasiAgen(ctx)
+
+	for i := 1; i <= q.workers; i++ {
+		workerID := fmt.Sprintf("%s-w%d", q.workerPrefix, i)
+		q.wg.Add(1)
+		go q.runWorker(ctx, workerID)
+	}
+	log.Printf("Antrian rekaman berjalan dengan %d worker", q.workers)
+}
+
+// Wait menunggu semua worker berhenti
+func (q *Queue) Wait() {
+	q.wg.Wait()
+}
+
+// Enqueue membuat job rekaman baru beserta sesi-sesinya sesuai kebijakan
+// rekaman jadwal
+func (q *Queue) Enqueue(jadwal models.Jadwal) (*models.RekamanJob, error) {
+	kebijakan := jadwal.KebijakanRekaman()
+	rencana, err := kebijakan.Jadwalkan(jadwal.WaktuMulai, jadwal.WaktuSelesai)
+	if err != nil {
+		return nil, err
+	}
+	if len(rencana) == 0 {
+		return nil, fmt.Errorf("kebijakan rekaman jadwal tidak menghasilkan sesi")
+	}
+
+	job := models.RekamanJob{
+		JadwalID:   jadwal.ID,
+		Status:     models.JobStatusPending,
+		TotalSesi:  len(rencana),
+		DurasiSesi: rencana[0].DurasiSesi,
+		JedaSesi:   kebijakan.JedaSesi,
+	}
+	for _, sesi := range rencana {
+		job.Sesi = append(job.Sesi, models.RekamanSesi{
+			NomorSesi: sesi.NomorSesi,
+			Status:    models.SesiStatusPending,
+		})
+	}
+
+	err = q.db.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris jadwal supaya dua Enqueue bersamaan untuk jadwal yang
+		// sama tidak sama-sama lolos pengecekan job aktif
+		var terkunci models.Jadwal
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&terkunci, "id = ?", jadwal.ID).Error; err != nil {
+			return err
+		}
+
+		var aktif int64
+		if err := tx.Model(&models.RekamanJob{}).
+			Where("jadwal_id = ? AND status IN ?", jadwal.ID, []string{models.JobStatusPending, models.JobStatusRunning}).
+			Count(&aktif).Error; err != nil {
+			return err
+		}
+		if aktif > 0 {
+			return ErrJobAktif
+		}
+		return tx.Create(&job).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Bangunkan worker dan agen yang sedang idle
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	q.bangunkanAgen()
+
+	return &job, nil
+}
+
+// LatestJob mengambil job terakhir untuk jadwal beserta sesinya
+func (q *Queue) LatestJob(jadwalID string) (*models.RekamanJob, error) {
+	var job models.RekamanJob
+	result := q.db.Preload("Sesi", func(db *gorm.DB) *gorm.DB {
+		return db.Order("nomor_sesi ASC")
+	}).Where("jadwal_id = ?", jadwalID).Order("tanggal_dibuat DESC").First(&job)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &job, nil
+}
+
+func (q *Queue) runWorker(ctx context.Context, workerID string) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Proses semua job yang tersedia sebelum kembali menunggu
+		for ctx.Err() == nil {
+			job, err := q.claim(workerID)
+			if err != nil {
+				log.Printf("Worker %s gagal mengambil job: %v", workerID, err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			q.execute(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+// claim mengambil satu job pending secara atomik. Mengembalikan nil jika
+// tidak ada job yang bisa diambil. Job di ruangan yang dilayani agen
+// perekam dilewati karena diambil oleh agennya.
+func (q *Queue) claim(workerID string) (*models.RekamanJob, error) {
+	var kandidat []models.RekamanJob
+	result := q.db.Where("status = ?", models.JobStatusPending).
+		Where("jadwal_id NOT IN (?)", q.jadwalRuanganAgen("ruangans.agent_id <> ''")).
+		Order("tanggal_dibuat ASC").
+		Limit(q.workers).
+		Find(&kandidat)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, job := range kandidat {
+		ok, err := q.ambilJob(job.ID, workerID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			// Job berhasil diklaim oleh worker ini
+			return q.loadJob(job.ID)
+		}
+	}
+
+	return nil, nil
+}
+
+// ambilJob memindahkan job pending ke running atas nama workerID. Bernilai
+// false jika job sudah diambil worker lain lebih dulu.
+func (q *Queue) ambilJob(id uuid.UUID, workerID string) (bool, error) {
+	now := time.Now()
+	result := q.db.Model(&models.RekamanJob{}).
+		Where("id = ? AND status = ?", id, models.JobStatusPending).
+		Updates(map[string]interface{}{
+			"status":           models.JobStatusRunning,
+			"worker_id":        workerID,
+			"stop_diminta":     false,
+			"percobaan":        gorm.Expr("percobaan + 1"),
+			"waktu_mulai":      now,
+			"tanggal_diupdate": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (q *Queue) loadJob(id uuid.UUID) (*models.RekamanJob, error) {
+	var job models.RekamanJob
+	result := q.db.Preload("Jadwal.Dosen").Preload("Sesi", func(db *gorm.DB) *gorm.DB {
+		return db.Order("nomor_sesi ASC")
+	}).First(&job, "id = ?", id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &job, nil
+}
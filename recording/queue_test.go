@@ -0,0 +1,64 @@
+package recording
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"CLAIRE/config"
+	"CLAIRE/database"
+	"CLAIRE/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dbUji membuka database SQLite sementara dengan skema aplikasi
+func dbUji(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "recording.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MigrateDB(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestEnqueue(t *testing.T) {
+	db := dbUji(t)
+	q := NewQueue(db, &config.Config{}, nil)
+
+	jumlahSesi, durasi := 3, 60
+	jadwal := models.Jadwal{NamaMatkul: "Algoritma", Hari: "SENIN", WaktuMulai: "08:00", WaktuSelesai: "10:00", RekamJumlahSesi: &jumlahSesi, RekamDurasiSesi: &durasi}
+	if err := db.Create(&jadwal).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := q.Enqueue(jadwal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tersimpan models.RekamanJob
+	if err := db.Preload("Sesi").First(&tersimpan, "id = ?", job.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if tersimpan.Status != models.JobStatusPending || tersimpan.TotalSesi != 3 || len(tersimpan.Sesi) != 3 {
+		t.Errorf("job = status %q, total %d, sesi %d; want pending dengan 3 sesi", tersimpan.Status, tersimpan.TotalSesi, len(tersimpan.Sesi))
+	}
+
+	if _, err := q.Enqueue(jadwal); !errors.Is(err, ErrJobAktif) {
+		t.Errorf("Enqueue kedua = %v, want ErrJobAktif", err)
+	}
+
+	hilang := jadwal
+	hilang.ID = uuid.New()
+	if _, err := q.Enqueue(hilang); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Enqueue jadwal yang tidak ada = %v, want ErrRecordNotFound", err)
+	}
+}
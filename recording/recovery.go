@@ -0,0 +1,148 @@
+package recording
+
+import (
+	"log"
+	"time"
+
+	"CLAIRE/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Recover dijalankan sekali saat startup, sebelum worker mulai. Job yang
+// masih "running" adalah sisa proses sebelumnya yang mati di tengah jalan:
+//   - sesi yang terputus saat merekam ditandai gagal (file-nya tidak utuh)
+//   - jika kelas masih berlangsung, job dikembalikan ke pending dan dilanjutkan
+//   - jika kelas sudah lewat, sesi yang belum direkam ditandai gagal; sesi yang
+//     sudah terekam tetap dianalisis
+//
+// Jadwal yang tertinggal dengan sedang_rekam=true tanpa job aktif juga dilepas.
+// Job yang dijalankan agen perekam tidak ikut dipulihkan karena agennya tetap
+// berjalan; job tersebut diawasi lewat heartbeat (lihat awasiAgen). Job milik
+// worker server lain yang masih hidup juga dibiarkan: hanya job dengan
+// prefix worker proses ini (misalnya PID yang sama setelah container
+// restart) atau yang detaknya sudah berhenti lebih dari batasDetakJob yang
+// dipulihkan.
+func (q *Queue) Recover() error {
+	var orphaned []models.RekamanJob
+	result := q.db.Preload("Jadwal").Preload("Sesi").
+		Where("status = ? AND worker_id NOT LIKE ?", models.JobStatusRunning, awalanWorkerAgenLike).
+		Where("worker_id LIKE ? OR tanggal_diupdate < ?", q.workerPrefix+"-%", time.Now().Add(-batasDetakJob)).
+		Find(&orphaned)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	for _, job := range orphaned {
+		if err := q.recoverJob(job, "server restart"); err != nil {
+			log.Printf("Gagal memulihkan job %s: %v", job.ID, err)
+		}
+	}
+
+	// Lepas jadwal yang masih terkunci tanpa job aktif
+	var aktif []uuid.UUID
+	q.db.Model(&models.RekamanJob{}).
+		Where("status IN ?", []string{models.JobStatusPending, models.JobStatusRunning}).
+		Pluck("jadwal_id", &aktif)
+
+	var stuck []models.Jadwal
+	query := q.db.Where("sedang_rekam = ?", true)
+	if len(aktif) > 0 {
+		query = query.Where("id NOT IN ?", aktif)
+	}
+	query.Find(&stuck)
+	for _, jadwal := range stuck {
+		releaseJadwal(q.db, jadwal.ID)
+		log.Printf("Jadwal %s dilepas dari status merekam setelah restart", jadwal.ID)
+	}
+
+	if len(orphaned) > 0 {
+		log.Printf("%d job rekaman yang terputus telah dipulihkan", len(orphaned))
+	}
+	return nil
+}
+
+// recoverJob mengembalikan job yang terputus ke antrian, atau menandainya
+// gagal jika tidak ada lagi yang bisa dikerjakan. alasan dipakai di pesan
+// error sesi dan job.
+func (q *Queue) recoverJob(job models.RekamanJob, alasan string) error {
+	masihBerlangsung := job.Jadwal.IsOngoing()
+	now := time.Now()
+
+	return q.db.Transaction(func(tx *gorm.DB) error {
+		sisa := 0
+		for _, sesi := range job.Sesi {
+			switch {
+			case sesi.Status == models.SesiStatusRecording,
+				sesi.Status == models.SesiStatusPending && !masihBerlangsung:
+				pesan := "rekaman terputus karena " + alasan
+				if sesi.Status == models.SesiStatusPending {
+					pesan = "jadwal sudah lewat saat job dipulihkan"
+				}
+				if err := tx.Model(&models.RekamanSesi{}).Where("id = ?", sesi.ID).Updates(map[string]interface{}{
+					"status":           models.SesiStatusFailed,
+					"pesan_error":      pesan,
+					"waktu_selesai":    now,
+					"tanggal_diupdate": now,
+				}).Error; err != nil {
+					return err
+				}
+			case sesi.Status == models.SesiStatusPending,
+				sesi.Status == models.SesiStatusUploaded:
+				sisa++
+			}
+		}
+
+		updates := map[string]interface{}{
+			"worker_id":        "",
+			"tanggal_diupdate": now,
+		}
+		if sisa > 0 {
+			// Masih ada pekerjaan: kembalikan ke antrian
+			updates["status"] = models.JobStatusPending
+			log.Printf("Job %s dikembalikan ke antrian setelah %s (%d sesi tersisa)", job.ID, alasan, sisa)
+		} else if hasAnalyzed(&job) {
+			// Semua sesi sudah selesai sebelum job sempat ditutup
+			updates["status"] = models.JobStatusCompleted
+			updates["pesan_error"] = ""
+			updates["waktu_selesai"] = now
+			log.Printf("Job %s ditandai selesai setelah %s", job.ID, alasan)
+		} else {
+			updates["status"] = models.JobStatusFailed
+			updates["pesan_error"] = "job terputus karena " + alasan
+			updates["waktu_selesai"] = now
+			log.Printf("Job %s ditandai gagal setelah %s", job.ID, alasan)
+		}
+
+		return tx.Model(&models.RekamanJob{}).Where("id = ?", job.ID).Updates(updates).Error
+	})
+}
+
+// releaseJadwal mengembalikan jadwal dari status merekam sesuai waktunya
+func releaseJadwal(db *gorm.DB, jadwalID uuid.UUID) {
+	var jadwal models.Jadwal
+	if err := db.First(&jadwal, "id = ?", jadwalID).Error; err != nil {
+		return
+	}
+
+	if jadwal.Status != models.JadwalMerekam {
+		// Status sudah dipindahkan proses lain, cukup lepaskan flag rekaman
+		db.Model(&models.Jadwal{}).Where("id = ?", jadwalID).Updates(map[string]interface{}{
+			"sedang_rekam":     false,
+			"tanggal_diupdate": time.Now(),
+		})
+		return
+	}
+
+	newStatus := models.JadwalSelesai
+	if jadwal.IsOngoing() {
+		newStatus = models.JadwalAktif
+	}
+
+	err := models.UbahStatusJadwal(db, jadwalID, newStatus, models.AktorRekaman, "rekaman otomatis selesai",
+		map[string]interface{}{"sedang_rekam": false})
+	if err != nil {
+		log.Printf("Gagal mengubah status jadwal %s: %v", jadwalID, err)
+	}
+}
@@ -0,0 +1,51 @@
+package recording
+
+import (
+	"testing"
+	"time"
+
+	"CLAIRE/config"
+	"CLAIRE/models"
+
+	"github.com/google/uuid"
+)
+
+func TestRecoverHanyaJobTerputus(t *testing.T) {
+	db := dbUji(t)
+	q := NewQueue(db, &config.Config{}, nil)
+
+	tests := []struct {
+		nama       string
+		workerID   string
+		umurDetak  time.Duration
+		dipulihkan bool
+	}{
+		{"worker lain masih berdetak", "server-lain-42-w1", time.Minute, false},
+		{"worker lain berhenti berdetak", "server-lain-42-w1", batasDetakJob + time.Minute, true},
+		{"worker proses ini", q.workerPrefix + "-w1", 0, true},
+		{"agen perekam", models.AwalanWorkerAgen + uuid.NewString(), batasDetakJob + time.Minute, false},
+	}
+	jobs := make([]models.RekamanJob, len(tests))
+	for i, tt := range tests {
+		jadwal := models.Jadwal{NamaMatkul: "Algoritma", Hari: "SENIN", WaktuMulai: "08:00", WaktuSelesai: "10:00"}
+		if err := db.Create(&jadwal).Error; err != nil {
+			t.Fatal(err)
+		}
+		jobs[i] = models.RekamanJob{JadwalID: jadwal.ID, Status: models.JobStatusRunning, TotalSesi: 1, DurasiSesi: 60, WorkerID: tt.workerID}
+		if err := db.Create(&jobs[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+		db.Model(&jobs[i]).UpdateColumn("tanggal_diupdate", time.Now().Add(-tt.umurDetak))
+	}
+
+	if err := q.Recover(); err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range tests {
+		var job models.RekamanJob
+		db.First(&job, "id = ?", jobs[i].ID)
+		if dipulihkan := job.Status != models.JobStatusRunning; dipulihkan != tt.dipulihkan {
+			t.Errorf("%s: status = %q, dipulihkan %v, want %v", tt.nama, job.Status, dipulihkan, tt.dipulihkan)
+		}
+	}
+}
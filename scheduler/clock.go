@@ -0,0 +1,20 @@
+package scheduler
+
+import "time"
+
+// Clock adalah sumber waktu scheduler. Diganti dengan jam palsu saat pengujian.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// RealClock memakai jam sistem
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,358 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultGrace adalah toleransi keterlambatan. Rekaman yang terlewat kurang
+// dari grace (misalnya server baru start) tetap dijalankan.
+const DefaultGrace = 2 * time.Minute
+
+// Batas pencarian minggu berikutnya yang bukan hari libur
+const maxMingguLibur = 53
+
+// Starter memulai rekaman untuk sebuah jadwal. pertemuan berisi pertemuan
+// yang direkam, nil jika jadwal belum punya pertemuan. Starter hanya
+// dipanggil setelah pertemuan dan hari libur diperiksa ulang, dan jam jadwal
+// sudah mengikuti jam pertemuan.
+type Starter func(jadwal models.Jadwal, pertemuan *models.Pertemuan) error
+
+// Entry adalah jadwal beserta waktu rekaman berikutnya
+type Entry struct {
+	Jadwal    models.Jadwal     `json:"jadwal"`
+	Pertemuan *models.Pertemuan `json:"pertemuan,omitempty"`
+	NextAt    time.Time         `json:"next_at"`
+}
+
+// Scheduler menjalankan rekaman otomatis pada waktu yang dihitung dari
+// pertemuan jadwal yang tidak dibatalkan, atau dari Hari/WaktuMulai jika
+// jadwal belum punya pertemuan. Hari libur dilewati.
+type Scheduler struct {
+	db      *gorm.DB
+	clock   Clock
+	start   Starter
+	Grace   time.Duration
+	mu      sync.Mutex
+	entries map[uuid.UUID]*Entry
+	fired   map[uuid.UUID]time.Time
+	changed chan struct{}
+}
+
+// New membuat Scheduler
+func New(db *gorm.DB, clock Clock, start Starter) *Scheduler {
+	if clock == nil {
+		clock = RealClock{}
+	}
+	return &Scheduler{
+		db:      db,
+		clock:   clock,
+		start:   start,
+		Grace:   DefaultGrace,
+		entries: make(map[uuid.UUID]*Entry),
+		fired:   make(map[uuid.UUID]time.Time),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// Load membaca ulang semua jadwal dari database
+func (s *Scheduler) Load() error {
+	var jadwals []models.Jadwal
+	if err := s.db.Preload("Dosen").Find(&jadwals).Error; err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	libur := s.hariLibur(now)
+	s.mu.Lock()
+	fired := make(map[uuid.UUID]time.Time, len(s.fired))
+	for id, waktu := range s.fired {
+		fired[id] = waktu
+	}
+	s.mu.Unlock()
+
+	entries := make(map[uuid.UUID]*Entry, len(jadwals))
+	for _, jadwal := range jadwals {
+		entry, err := s.nextRun(jadwal, now, fired[jadwal.ID], libur)
+		if err != nil {
+			log.Printf("Scheduler melewati jadwal %s: %v", jadwal.ID, err)
+			continue
+		}
+		entries[jadwal.ID] = entry
+	}
+
+	s.mu.Lock()
+	s.entries = entries
+	s.mu.Unlock()
+	s.notify()
+	return nil
+}
+
+// Refresh membaca ulang satu jadwal setelah jadwal atau pertemuannya dibuat
+// atau diubah. Jadwal yang sudah dihapus akan dikeluarkan dari scheduler.
+func (s *Scheduler) Refresh(id uuid.UUID) {
+	var jadwal models.Jadwal
+	err := s.db.Preload("Dosen").First(&jadwal, "id = ?", id).Error
+
+	var entry *Entry
+	if err == nil {
+		now := s.clock.Now()
+		s.mu.Lock()
+		// Jangan jalankan ulang rekaman yang baru saja dimulai
+		terakhir := s.fired[id]
+		s.mu.Unlock()
+		entry, err = s.nextRun(jadwal, now, terakhir, s.hariLibur(now))
+		if err != nil {
+			log.Printf("Scheduler melewati jadwal %s: %v", jadwal.ID, err)
+		}
+	}
+
+	s.mu.Lock()
+	if err != nil {
+		delete(s.entries, id)
+	} else {
+		s.entries[id] = entry
+	}
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Upcoming mengembalikan jadwal rekaman berikutnya, terurut dari yang terdekat
+func (s *Scheduler) Upcoming(limit int) []Entry {
+	s.mu.Lock()
+	upcoming := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		upcoming = append(upcoming, *entry)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].NextAt.Before(upcoming[j].NextAt)
+	})
+	if limit > 0 && len(upcoming) > limit {
+		upcoming = upcoming[:limit]
+	}
+	return upcoming
+}
+
+// Run menjalankan loop scheduler sampai ctx dibatalkan
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		wait := s.fireDue()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(wait):
+		case <-s.changed:
+		}
+	}
+}
+
+// fireDue menjalankan semua entry yang sudah jatuh tempo dan mengembalikan
+// lama waktu tunggu sampai entry berikutnya
+func (s *Scheduler) fireDue() time.Duration {
+	now := s.clock.Now()
+
+	var due []Entry
+	s.mu.Lock()
+	for _, entry := range s.entries {
+		if !entry.NextAt.After(now) {
+			due = append(due, *entry)
+			s.fired[entry.Jadwal.ID] = entry.NextAt
+		}
+	}
+	s.mu.Unlock()
+
+	for _, entry := range due {
+		jadwal := entry.Jadwal
+		pertemuan, lanjut, err := s.periksaUlang(&jadwal, entry.Pertemuan, now)
+		if err != nil {
+			log.Printf("Scheduler gagal memeriksa ulang jadwal %s: %v", jadwal.ID, err)
+			continue
+		}
+		if !lanjut {
+			continue
+		}
+		log.Printf("Scheduler memulai rekaman otomatis untuk jadwal %s (%s)", jadwal.ID, jadwal.NamaMatkul)
+		if err := s.start(jadwal, pertemuan); err != nil {
+			log.Printf("Scheduler gagal memulai rekaman jadwal %s: %v", jadwal.ID, err)
+		}
+	}
+
+	// Jadwalkan pertemuan atau minggu berikutnya
+	if len(due) > 0 {
+		libur := s.hariLibur(now)
+		for _, lama := range due {
+			entry, err := s.nextRun(lama.Jadwal, now, lama.NextAt, libur)
+			if err != nil {
+				log.Printf("Scheduler melewati jadwal %s: %v", lama.Jadwal.ID, err)
+			}
+
+			s.mu.Lock()
+			// Entry yang sudah diganti Refresh dibiarkan
+			if current, ok := s.entries[lama.Jadwal.ID]; ok && current.NextAt.Equal(lama.NextAt) {
+				if err != nil {
+					delete(s.entries, lama.Jadwal.ID)
+				} else {
+					s.entries[lama.Jadwal.ID] = entry
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+
+	wait := time.Hour
+	s.mu.Lock()
+	for _, entry := range s.entries {
+		if d := entry.NextAt.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	s.mu.Unlock()
+
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// periksaUlang membaca ulang pertemuan dan hari libur saat entry jatuh
+// tempo, karena pertemuan bisa dibatalkan atau hari libur ditambah setelah
+// entry dihitung. Jam jadwal diganti jam pertemuan terbaru sehingga
+// pertemuan yang dijadwal ulang direkam pada jam barunya.
+func (s *Scheduler) periksaUlang(jadwal *models.Jadwal, pertemuan *models.Pertemuan, now time.Time) (*models.Pertemuan, bool, error) {
+	hariIni := kalender.Default().Tanggal(now)
+	if pertemuan != nil {
+		var terbaru models.Pertemuan
+		if err := s.db.First(&terbaru, "id = ?", pertemuan.ID).Error; err != nil {
+			return nil, false, err
+		}
+		if terbaru.Status == models.PertemuanDibatalkan || terbaru.Tanggal.Format("2006-01-02") != hariIni.Format("2006-01-02") {
+			log.Printf("Rekaman jadwal %s dilewati: pertemuan %d dibatalkan atau dijadwal ulang", jadwal.ID, terbaru.PertemuanKe)
+			return nil, false, nil
+		}
+		pertemuan = &terbaru
+		jadwal.WaktuMulai = pertemuan.WaktuMulai
+		jadwal.WaktuSelesai = pertemuan.WaktuSelesai
+	}
+	if models.HariLiburPada(s.db, hariIni) && (pertemuan == nil || !pertemuan.DijadwalUlang) {
+		log.Printf("Rekaman jadwal %s dilewati: hari libur", jadwal.ID)
+		return nil, false, nil
+	}
+	return pertemuan, true, nil
+}
+
+// hariLibur membaca tanggal libur mulai kemarin (format YYYY-MM-DD).
+// Gagal membaca dianggap tidak ada libur.
+func (s *Scheduler) hariLibur(now time.Time) map[string]bool {
+	var daftar []models.HariLibur
+	kemarin := kalender.Default().Tanggal(now).AddDate(0, 0, -1)
+	if err := s.db.Where("tanggal >= ?", kemarin).Find(&daftar).Error; err != nil {
+		log.Printf("Scheduler gagal membaca hari libur: %v", err)
+	}
+
+	libur := make(map[string]bool, len(daftar))
+	for _, l := range daftar {
+		libur[l.Tanggal.Format("2006-01-02")] = true
+	}
+	return libur
+}
+
+// nextRun menghitung rekaman berikutnya memakai offset dari kebijakan
+// rekaman jadwal. Waktu rekaman harus setelah terakhir (rekaman yang sudah
+// dijalankan, zero jika belum ada). Jika jadwal punya pertemuan, rekaman
+// mengikuti pertemuan terdekat yang tidak dibatalkan beserta tanggal dan jam
+// hasil jadwal ulang; selain itu mengikuti Hari/WaktuMulai mingguan. Tanggal
+// libur dilewati kecuali pertemuan sengaja dijadwal ulang ke tanggal itu.
+func (s *Scheduler) nextRun(jadwal models.Jadwal, now time.Time, terakhir time.Time, libur map[string]bool) (*Entry, error) {
+	kal := kalender.Default()
+	offset := time.Duration(jadwal.KebijakanRekaman().OffsetMulai) * time.Minute
+	// Hari dan jam jadwal berlaku di zona waktu kampus
+	now = now.In(kal.Lokasi())
+
+	var jumlah int64
+	if err := s.db.Model(&models.Pertemuan{}).Where("jadwal_id = ?", jadwal.ID).Count(&jumlah).Error; err != nil {
+		return nil, err
+	}
+	if jumlah > 0 {
+		var daftar []models.Pertemuan
+		err := s.db.Where("jadwal_id = ? AND status NOT IN ? AND tanggal >= ?", jadwal.ID,
+			[]string{models.PertemuanDibatalkan, models.PertemuanSelesai}, kal.Tanggal(now).AddDate(0, 0, -1)).
+			Order("tanggal ASC, waktu_mulai ASC").Find(&daftar).Error
+		if err != nil {
+			return nil, err
+		}
+		for i := range daftar {
+			pertemuan := &daftar[i]
+			next := pertemuan.Mulai().Add(offset)
+			if next.Add(s.Grace).Before(now) || (!terakhir.IsZero() && !next.After(terakhir)) {
+				continue
+			}
+			if libur[pertemuan.Tanggal.Format("2006-01-02")] && !pertemuan.DijadwalUlang {
+				continue
+			}
+			return &Entry{Jadwal: jadwal, Pertemuan: pertemuan, NextAt: next}, nil
+		}
+		return nil, fmt.Errorf("tidak ada pertemuan berikutnya")
+	}
+
+	next, err := NextOccurrence(jadwal.Hari, jadwal.WaktuMulai, offset, s.Grace, now)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; ; i++ {
+		if i > maxMingguLibur {
+			return nil, fmt.Errorf("tidak ada tanggal rekaman di luar hari libur")
+		}
+		sudahDirekam := !terakhir.IsZero() && !next.After(terakhir)
+		if !sudahDirekam && !libur[kal.Tanggal(next.Add(-offset)).Format("2006-01-02")] {
+			break
+		}
+		next = next.AddDate(0, 0, 7)
+	}
+	return &Entry{Jadwal: jadwal, NextAt: next}, nil
+}
+
+// NextOccurrence menghitung waktu rekaman berikutnya untuk jadwal mingguan
+// pada hari dan jam mulai tertentu, ditambah offset. Waktu yang terlewat
+// kurang dari grace masih dianggap jatuh tempo. Perhitungan memakai zona
+// waktu dari now.
+func NextOccurrence(hari string, waktuMulai string, offset time.Duration, grace time.Duration, now time.Time) (time.Time, error) {
+	weekday, ok := kalender.Weekday(hari)
+	if !ok {
+		return time.Time{}, fmt.Errorf("hari tidak valid: %s", hari)
+	}
+
+	jamMulai, err := time.Parse("15:04", waktuMulai)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("waktu mulai tidak valid: %s", waktuMulai)
+	}
+
+	days := (int(weekday) - int(now.Weekday()) + 7) % 7
+	date := now.AddDate(0, 0, days)
+	next := time.Date(date.Year(), date.Month(), date.Day(),
+		jamMulai.Hour(), jamMulai.Minute(), 0, 0, now.Location()).Add(offset)
+
+	if next.Add(grace).Before(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next, nil
+}
@@ -0,0 +1,429 @@
+package scheduler
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"CLAIRE/database"
+	"CLAIRE/kalender"
+	"CLAIRE/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// wib adalah zona waktu kampus di pengujian, sengaja berbeda dari UTC
+var wib = time.FixedZone("WIB", 7*60*60)
+
+// jamPalsu adalah Clock yang hanya maju ketika diatur dari pengujian
+type jamPalsu struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (j *jamPalsu) Now() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.now
+}
+
+func (j *jamPalsu) After(d time.Duration) <-chan time.Time {
+	return make(chan time.Time)
+}
+
+func (j *jamPalsu) set(t time.Time) {
+	j.mu.Lock()
+	j.now = t
+	j.mu.Unlock()
+}
+
+func TestNextOccurrence(t *testing.T) {
+	// 2026-10-19 adalah hari Senin
+	senin := func(jam, menit int) time.Time { return time.Date(2026, 10, 19, jam, menit, 0, 0, wib) }
+
+	tests := []struct {
+		nama   string
+		hari   string
+		mulai  string
+		offset time.Duration
+		now    time.Time
+		want   time.Time
+	}{
+		{"hari yang sama, belum mulai", "SENIN", "08:00", 0, senin(7, 0), senin(8, 0)},
+		{"hari berikutnya", "RABU", "13:30", 0, senin(7, 0), time.Date(2026, 10, 21, 13, 30, 0, 0, wib)},
+		{"melewati akhir minggu", "SENIN", "08:00", 0, time.Date(2026, 10, 24, 9, 0, 0, 0, wib), time.Date(2026, 10, 26, 8, 0, 0, 0, wib)},
+		{"minggu ke senin", "SENIN", "08:00", 0, time.Date(2026, 10, 25, 23, 59, 0, 0, wib), senin(8, 0).AddDate(0, 0, 7)},
+		{"terlambat dalam grace", "SENIN", "08:00", 0, senin(8, 1), senin(8, 0)},
+		{"tepat di batas grace", "SENIN", "08:00", 0, senin(8, 2), senin(8, 0)},
+		{"lewat grace pindah minggu depan", "SENIN", "08:00", 0, senin(8, 3), senin(8, 0).AddDate(0, 0, 7)},
+		{"offset ditambahkan", "SENIN", "08:00", 10 * time.Minute, senin(8, 5), senin(8, 10)},
+		{"offset dan grace", "SENIN", "08:00", 10 * time.Minute, senin(8, 13), senin(8, 10).AddDate(0, 0, 7)},
+	}
+	for _, tt := range tests {
+		got, err := NextOccurrence(tt.hari, tt.mulai, tt.offset, DefaultGrace, tt.now)
+		if err != nil {
+			t.Errorf("%s: %v", tt.nama, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: NextOccurrence = %v, want %v", tt.nama, got, tt.want)
+		}
+	}
+}
+
+func TestNextOccurrenceZonaWaktu(t *testing.T) {
+	// Minggu 20:00 UTC sudah Senin 03:00 WIB
+	now := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+
+	got, err := NextOccurrence("SENIN", "08:00", 0, DefaultGrace, now.In(wib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextOccurrence di WIB = %v, want %v", got, want)
+	}
+
+	got, err = NextOccurrence("SENIN", "00:30", 0, DefaultGrace, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 19, 0, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextOccurrence di UTC = %v, want %v", got, want)
+	}
+}
+
+func TestNextOccurrenceTidakValid(t *testing.T) {
+	now := time.Date(2026, 10, 19, 7, 0, 0, 0, wib)
+	if _, err := NextOccurrence("SENEN", "08:00", 0, DefaultGrace, now); err == nil {
+		t.Error("hari tidak valid harus gagal")
+	}
+	if _, err := NextOccurrence("SENIN", "8 pagi", 0, DefaultGrace, now); err == nil {
+		t.Error("waktu mulai tidak valid harus gagal")
+	}
+}
+
+// zonaKampus memasang Kalender default di WIB selama pengujian berjalan
+func zonaKampus(t *testing.T) {
+	t.Helper()
+	lama := kalender.Default()
+	kalender.SetDefault(kalender.New(wib))
+	t.Cleanup(func() { kalender.SetDefault(lama) })
+}
+
+// dbUji membuka database SQLite sementara dengan skema aplikasi
+func dbUji(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "scheduler.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MigrateDB(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// buatJadwal menyimpan jadwal mingguan tanpa offset rekaman
+func buatJadwal(t *testing.T, db *gorm.DB, hari string, mulai string) models.Jadwal {
+	t.Helper()
+	offset := 0
+	jadwal := models.Jadwal{
+		NamaMatkul:       "Algoritma",
+		Hari:             hari,
+		WaktuMulai:       mulai,
+		WaktuSelesai:     "23:59",
+		RekamOffsetMulai: &offset,
+	}
+	if err := db.Create(&jadwal).Error; err != nil {
+		t.Fatal(err)
+	}
+	return jadwal
+}
+
+// tanggal membaca tanggal kalender YYYY-MM-DD seperti kolom DATE
+func tanggal(t *testing.T, value string) time.Time {
+	t.Helper()
+	tgl, err := kalender.ParseTanggal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tgl
+}
+
+func nextAt(t *testing.T, s *Scheduler) time.Time {
+	t.Helper()
+	upcoming := s.Upcoming(0)
+	if len(upcoming) != 1 {
+		t.Fatalf("jumlah entry = %d, want 1", len(upcoming))
+	}
+	return upcoming[0].NextAt
+}
+
+func TestFireDueMingguan(t *testing.T) {
+	senin := time.Date(2026, 10, 19, 8, 0, 0, 0, wib)
+	zonaKampus(t)
+	db := dbUji(t)
+	buatJadwal(t, db, "SENIN", "08:00")
+
+	jam := &jamPalsu{now: senin.Add(-30 * time.Minute)}
+	dimulai := 0
+	s := New(db, jam, func(models.Jadwal, *models.Pertemuan) error {
+		dimulai++
+		return nil
+	})
+
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextAt(t, s); !got.Equal(senin) {
+		t.Fatalf("NextAt = %v, want %v", got, senin)
+	}
+
+	// Belum jatuh tempo: tunggu sampai jam mulai
+	if wait := s.fireDue(); wait != 30*time.Minute {
+		t.Errorf("wait = %v, want 30m", wait)
+	}
+	if dimulai != 0 {
+		t.Fatalf("rekaman dimulai %d kali sebelum jatuh tempo", dimulai)
+	}
+
+	// Jatuh tempo: mulai sekali lalu pindah ke minggu berikutnya
+	jam.set(senin.Add(30 * time.Second))
+	if wait := s.fireDue(); wait != time.Hour {
+		t.Errorf("wait = %v, want 1h (batas tunggu maksimum)", wait)
+	}
+	if dimulai != 1 {
+		t.Fatalf("rekaman dimulai %d kali, want 1", dimulai)
+	}
+	if got, want := nextAt(t, s), senin.AddDate(0, 0, 7); !got.Equal(want) {
+		t.Errorf("NextAt = %v, want %v", got, want)
+	}
+
+	// Minggu berikutnya berjalan lagi
+	jam.set(senin.AddDate(0, 0, 7))
+	s.fireDue()
+	if dimulai != 2 {
+		t.Errorf("rekaman dimulai %d kali, want 2", dimulai)
+	}
+}
+
+func TestFireDueGrace(t *testing.T) {
+	senin := time.Date(2026, 10, 19, 8, 0, 0, 0, wib)
+
+	tests := []struct {
+		nama    string
+		now     time.Time
+		dimulai int
+		next    time.Time
+	}{
+		// Server start satu menit setelah jam mulai: rekaman tetap dijalankan
+		{"dalam grace", senin.Add(time.Minute), 1, senin.AddDate(0, 0, 7)},
+		// Lewat grace: langsung dijadwalkan minggu depan
+		{"lewat grace", senin.Add(DefaultGrace + time.Minute), 0, senin.AddDate(0, 0, 7)},
+	}
+	zonaKampus(t)
+	for _, tt := range tests {
+		db := dbUji(t)
+		buatJadwal(t, db, "SENIN", "08:00")
+		dimulai := 0
+		s := New(db, &jamPalsu{now: tt.now}, func(models.Jadwal, *models.Pertemuan) error {
+			dimulai++
+			return nil
+		})
+
+		if err := s.Load(); err != nil {
+			t.Fatal(err)
+		}
+		s.fireDue()
+		if dimulai != tt.dimulai {
+			t.Errorf("%s: rekaman dimulai %d kali, want %d", tt.nama, dimulai, tt.dimulai)
+		}
+		if got := nextAt(t, s); !got.Equal(tt.next) {
+			t.Errorf("%s: NextAt = %v, want %v", tt.nama, got, tt.next)
+		}
+	}
+}
+
+func TestFireDueZonaWaktuKampus(t *testing.T) {
+	zonaKampus(t)
+	db := dbUji(t)
+	buatJadwal(t, db, "SENIN", "08:00")
+
+	// Minggu 20:00 UTC sudah Senin 03:00 di kampus
+	jam := &jamPalsu{now: time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)}
+	dimulai := 0
+	s := New(db, jam, func(models.Jadwal, *models.Pertemuan) error {
+		dimulai++
+		return nil
+	})
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)
+	if got := nextAt(t, s); !got.Equal(want) {
+		t.Fatalf("NextAt = %v, want %v (Senin 08:00 WIB)", got, want)
+	}
+
+	jam.set(want)
+	s.fireDue()
+	if dimulai != 1 {
+		t.Errorf("rekaman dimulai %d kali, want 1", dimulai)
+	}
+}
+
+func TestFireDueMelewatiHariLibur(t *testing.T) {
+	zonaKampus(t)
+	db := dbUji(t)
+	buatJadwal(t, db, "SENIN", "08:00")
+	db.Create(&models.HariLibur{Tanggal: tanggal(t, "2026-10-19")})
+	db.Create(&models.HariLibur{Tanggal: tanggal(t, "2026-10-26")})
+
+	senin := time.Date(2026, 10, 19, 8, 0, 0, 0, wib)
+	s := New(db, &jamPalsu{now: senin.Add(-time.Hour)}, func(models.Jadwal, *models.Pertemuan) error { return nil })
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := nextAt(t, s), senin.AddDate(0, 0, 14); !got.Equal(want) {
+		t.Errorf("NextAt = %v, want %v", got, want)
+	}
+}
+
+func TestFireDuePertemuan(t *testing.T) {
+	zonaKampus(t)
+	db := dbUji(t)
+	jadwal := buatJadwal(t, db, "SENIN", "08:00")
+	pertemuan := []models.Pertemuan{
+		{JadwalID: jadwal.ID, PertemuanKe: 1, Tanggal: tanggal(t, "2026-10-19"), TanggalAsli: tanggal(t, "2026-10-19"), WaktuMulai: "08:00", WaktuSelesai: "10:00", Status: models.PertemuanDibatalkan},
+		{JadwalID: jadwal.ID, PertemuanKe: 2, Tanggal: tanggal(t, "2026-10-28"), TanggalAsli: tanggal(t, "2026-10-26"), WaktuMulai: "13:00", WaktuSelesai: "15:00", Status: models.PertemuanTerjadwal, DijadwalUlang: true},
+		{JadwalID: jadwal.ID, PertemuanKe: 3, Tanggal: tanggal(t, "2026-11-02"), TanggalAsli: tanggal(t, "2026-11-02"), WaktuMulai: "08:00", WaktuSelesai: "10:00", Status: models.PertemuanTerjadwal},
+	}
+	if err := db.Create(&pertemuan).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	jam := &jamPalsu{now: time.Date(2026, 10, 19, 7, 0, 0, 0, wib)}
+	var dimulai []int
+	s := New(db, jam, func(_ models.Jadwal, p *models.Pertemuan) error {
+		if p == nil {
+			t.Error("rekaman jadwal dengan pertemuan dimulai tanpa pertemuan")
+			return nil
+		}
+		dimulai = append(dimulai, p.PertemuanKe)
+		return nil
+	})
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pertemuan yang dibatalkan dilewati, pertemuan hasil jadwal ulang
+	// mengikuti tanggal dan jam barunya
+	rabu := time.Date(2026, 10, 28, 13, 0, 0, 0, wib)
+	upcoming := s.Upcoming(0)
+	if len(upcoming) != 1 || !upcoming[0].NextAt.Equal(rabu) || upcoming[0].Pertemuan.PertemuanKe != 2 {
+		t.Fatalf("Upcoming = %+v, want pertemuan 2 pada %v", upcoming, rabu)
+	}
+
+	jam.set(time.Date(2026, 10, 19, 8, 0, 0, 0, wib))
+	s.fireDue()
+	if len(dimulai) != 0 {
+		t.Fatalf("pertemuan yang dibatalkan tetap direkam")
+	}
+
+	jam.set(rabu)
+	s.fireDue()
+	if !reflect.DeepEqual(dimulai, []int{2}) {
+		t.Fatalf("pertemuan yang direkam = %v, want [2]", dimulai)
+	}
+	if got, want := nextAt(t, s), time.Date(2026, 11, 2, 8, 0, 0, 0, wib); !got.Equal(want) {
+		t.Errorf("NextAt = %v, want %v", got, want)
+	}
+}
+
+func TestFireDuePeriksaUlang(t *testing.T) {
+	senin := time.Date(2026, 10, 19, 8, 0, 0, 0, wib)
+	tests := []struct {
+		name string
+		ubah func(db *gorm.DB, p *models.Pertemuan)
+		// jam rekaman yang dimulai, kosong jika rekaman dilewati
+		jam string
+	}{
+		{"tidak berubah", func(*gorm.DB, *models.Pertemuan) {}, "08:00-10:00"},
+		{"dibatalkan", func(db *gorm.DB, p *models.Pertemuan) {
+			db.Model(p).Update("status", models.PertemuanDibatalkan)
+		}, ""},
+		{"dijadwal ulang ke hari lain", func(db *gorm.DB, p *models.Pertemuan) {
+			db.Model(p).Updates(map[string]interface{}{"tanggal": tanggal(t, "2026-10-21"), "dijadwal_ulang": true})
+		}, ""},
+		{"jam diganti", func(db *gorm.DB, p *models.Pertemuan) {
+			db.Model(p).Updates(map[string]interface{}{"waktu_mulai": "08:00", "waktu_selesai": "09:30"})
+		}, "08:00-09:30"},
+		{"hari libur ditambah", func(db *gorm.DB, _ *models.Pertemuan) {
+			db.Create(&models.HariLibur{Tanggal: tanggal(t, "2026-10-19")})
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zonaKampus(t)
+			db := dbUji(t)
+			jadwal := buatJadwal(t, db, "SENIN", "08:00")
+			pertemuan := models.Pertemuan{JadwalID: jadwal.ID, PertemuanKe: 1, Tanggal: tanggal(t, "2026-10-19"), TanggalAsli: tanggal(t, "2026-10-19"), WaktuMulai: "08:00", WaktuSelesai: "10:00", Status: models.PertemuanTerjadwal}
+			if err := db.Create(&pertemuan).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			jam := &jamPalsu{now: senin.Add(-time.Hour)}
+			var dimulai []string
+			s := New(db, jam, func(j models.Jadwal, _ *models.Pertemuan) error {
+				dimulai = append(dimulai, j.WaktuMulai+"-"+j.WaktuSelesai)
+				return nil
+			})
+			if err := s.Load(); err != nil {
+				t.Fatal(err)
+			}
+
+			// Perubahan setelah entry dihitung baru terlihat saat jatuh tempo
+			tt.ubah(db, &pertemuan)
+			jam.set(senin)
+			s.fireDue()
+
+			var want []string
+			if tt.jam != "" {
+				want = []string{tt.jam}
+			}
+			if !reflect.DeepEqual(dimulai, want) {
+				t.Fatalf("rekaman dimulai %v, want %v", dimulai, want)
+			}
+		})
+	}
+}
+
+func TestFireDueHariLiburTanpaPertemuan(t *testing.T) {
+	zonaKampus(t)
+	db := dbUji(t)
+	buatJadwal(t, db, "SENIN", "08:00")
+
+	senin := time.Date(2026, 10, 19, 8, 0, 0, 0, wib)
+	jam := &jamPalsu{now: senin.Add(-time.Hour)}
+	dimulai := 0
+	s := New(db, jam, func(models.Jadwal, *models.Pertemuan) error {
+		dimulai++
+		return nil
+	})
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	db.Create(&models.HariLibur{Tanggal: tanggal(t, "2026-10-19")})
+	jam.set(senin)
+	s.fireDue()
+	if dimulai != 0 {
+		t.Errorf("rekaman dimulai %d kali pada hari libur, want 0", dimulai)
+	}
+}
@@ -0,0 +1,62 @@
+// Package spreadsheet membaca tabel sederhana dari file CSV atau XLSX
+// menjadi baris-baris string, tanpa dependensi eksternal.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Read membaca isi file berdasarkan ekstensi nama file. Baris kosong
+// dilewati; setiap baris dikembalikan apa adanya tanpa header diproses.
+func Read(filename string, data []byte) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return ReadCSV(data)
+	case ".xlsx":
+		return ReadXLSX(data)
+	default:
+		return nil, fmt.Errorf("format file tidak didukung: %s (gunakan .csv atau .xlsx)", filepath.Ext(filename))
+	}
+}
+
+// ReadCSV membaca CSV dengan pemisah koma atau titik koma. Pemisah
+// ditentukan dari baris pertama karena ekspor Excel berbahasa Indonesia
+// memakai titik koma.
+func ReadCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM UTF-8
+
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca CSV: %v", err)
+	}
+	return dropEmptyRows(records), nil
+}
+
+func dropEmptyRows(rows [][]string) [][]string {
+	result := rows[:0]
+	for _, row := range rows {
+		for _, cell := range row {
+			if strings.TrimSpace(cell) != "" {
+				result = append(result, row)
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package spreadsheet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		nama string
+		data string
+		want [][]string
+	}{
+		{
+			"pemisah koma",
+			"hari,mulai\nSENIN,08:00\n",
+			[][]string{{"hari", "mulai"}, {"SENIN", "08:00"}},
+		},
+		{
+			"pemisah titik koma dari Excel Indonesia",
+			"hari;mulai;ruang\nSENIN;08:00;R1, lantai 2\n",
+			[][]string{{"hari", "mulai", "ruang"}, {"SENIN", "08:00", "R1, lantai 2"}},
+		},
+		{
+			"BOM dan baris kosong",
+			"\xef\xbb\xbfhari,mulai\n,\n\nSELASA, 10:00\n",
+			[][]string{{"hari", "mulai"}, {"SELASA", "10:00"}},
+		},
+		{
+			"jumlah kolom berbeda",
+			"a,b,c\nx\n",
+			[][]string{{"a", "b", "c"}, {"x"}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := ReadCSV([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: %v", tt.nama, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ReadCSV = %q, want %q", tt.nama, got, tt.want)
+		}
+	}
+}
+
+func TestReadCSVTidakValid(t *testing.T) {
+	if _, err := ReadCSV([]byte("a,\"b\nc")); err == nil {
+		t.Error("CSV dengan tanda kutip tidak tertutup harus gagal")
+	}
+}
+
+func TestReadFormat(t *testing.T) {
+	got, err := Read("jadwal.CSV", []byte("a,b\n"))
+	if err != nil || !reflect.DeepEqual(got, [][]string{{"a", "b"}}) {
+		t.Errorf("Read(.CSV) = %q, %v", got, err)
+	}
+	if _, err := Read("jadwal.xls", []byte("a,b\n")); err == nil {
+		t.Error("Read(.xls) harus gagal: format tidak didukung")
+	}
+}
@@ -0,0 +1,229 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Batas isi file XLSX. Referensi sel dan isi zip berasal dari file upload,
+// jadi keduanya dibatasi agar file kecil tidak bisa menghabiskan memori.
+const (
+	// maxKolom adalah indeks kolom terakhir Excel (XFD)
+	maxKolom = 16383
+	// maxUkuranXML adalah ukuran maksimal satu file XML setelah didekompresi
+	maxUkuranXML = 50 << 20
+	// maxSel adalah jumlah sel maksimal semua baris, termasuk sel kosong
+	// yang ditambahkan untuk mengisi kolom yang dilompati
+	maxSel = 1 << 20
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX membaca sheet pertama dari file XLSX. Angka dikembalikan
+// sebagai teks mentah (misalnya jam 08:00 menjadi "0.333333333333333").
+// File yang menghasilkan lebih dari maxSel sel ditolak.
+func ReadXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("file XLSX tidak valid: %v", err)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("sheet %s tidak ditemukan di file XLSX", sheetPath)
+	}
+	var sheet xlsxSheet
+	if err := decodeXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	jumlahSel := 0
+	for _, r := range sheet.Rows {
+		var row []string
+		for i, cell := range r.Cells {
+			col := columnIndex(cell.Ref)
+			if col > maxKolom {
+				return nil, fmt.Errorf("referensi sel %.20q melebihi kolom terakhir XFD", cell.Ref)
+			}
+			if col < 0 {
+				col = i
+			}
+			// Sel jauh di kanan membuat banyak sel kosong, jadi semuanya
+			// dihitung ke batas jumlah sel
+			if len(row) <= col {
+				jumlahSel += col + 1 - len(row)
+				if jumlahSel > maxSel {
+					return nil, fmt.Errorf("file XLSX berisi lebih dari %d sel", maxSel)
+				}
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				var idx int
+				if _, err := fmt.Sscanf(cell.Value, "%d", &idx); err == nil && idx >= 0 && idx < len(shared.Items) {
+					row[col] = shared.Items[idx].String()
+				}
+			case "inlineStr":
+				row[col] = cell.Inline.String()
+			default:
+				row[col] = cell.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return dropEmptyRows(rows), nil
+}
+
+// firstSheetPath mencari path worksheet pertama lewat workbook.xml dan
+// relasinya
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", fmt.Errorf("file XLSX tidak valid: workbook.xml tidak ada")
+	}
+	var workbook xlsxWorkbook
+	if err := decodeXML(wbFile, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("file XLSX tidak memiliki sheet")
+	}
+
+	relFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback, nil
+	}
+	var rels xlsxRelationships
+	if err := decodeXML(relFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	if f.UncompressedSize64 > maxUkuranXML {
+		return fmt.Errorf("%s di file XLSX terlalu besar", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// Ukuran di header zip bisa dipalsukan, jadi hasil baca tetap dibatasi
+	content, err := io.ReadAll(io.LimitReader(rc, maxUkuranXML+1))
+	if err != nil {
+		return err
+	}
+	if len(content) > maxUkuranXML {
+		return fmt.Errorf("%s di file XLSX terlalu besar", f.Name)
+	}
+	if err := xml.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("gagal membaca %s: %v", f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex mengubah referensi sel seperti "C5" menjadi indeks kolom 2.
+// Referensi yang melewati XFD menghasilkan indeks di atas maxKolom.
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+		if col > maxKolom+1 {
+			// Berhenti sebelum overflow pada referensi yang sangat panjang
+			break
+		}
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
@@ -0,0 +1,126 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// xlsxUji membuat file XLSX minimal dengan satu sheet di path sheet
+func xlsxUji(t *testing.T, sheet string, isiSheet string, sharedStrings string) []byte {
+	t.Helper()
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Jadwal" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships>` +
+			`<Relationship Id="rId1" Target="styles.xml"/>` +
+			`<Relationship Id="rId7" Target="` + sheet + `"/></Relationships>`,
+		"xl/" + sheet: isiSheet,
+	}
+	if sharedStrings != "" {
+		files["xl/sharedStrings.xml"] = sharedStrings
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	shared := `<sst><si><t>hari</t></si><si><t>mulai</t></si>` +
+		`<si><r><t>SEN</t></r><r><t>IN</t></r></si></sst>`
+	sheet := `<worksheet><sheetData>` +
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>` +
+		`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>0.333333333333333</v></c><c r="D2" t="inlineStr"><is><t>R101</t></is></c></row>` +
+		`<row r="3"><c r="A3" t="s"><v>99</v></c></row>` +
+		`</sheetData></worksheet>`
+
+	got, err := ReadXLSX(xlsxUji(t, "worksheets/jadwal.xml", sheet, shared))
+	if err != nil {
+		t.Fatalf("ReadXLSX: %v", err)
+	}
+	want := [][]string{
+		{"hari", "mulai"},
+		{"SENIN", "0.333333333333333", "", "R101"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadXLSX = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXTidakValid(t *testing.T) {
+	if _, err := ReadXLSX([]byte("bukan zip")); err == nil {
+		t.Error("ReadXLSX untuk file bukan zip harus gagal")
+	}
+
+	var buf bytes.Buffer
+	zip.NewWriter(&buf).Close()
+	if _, err := ReadXLSX(buf.Bytes()); err == nil {
+		t.Error("ReadXLSX tanpa workbook.xml harus gagal")
+	}
+
+	if _, err := ReadXLSX(xlsxUji(t, "worksheets/sheet1.xml", "<worksheet><sheetData><row>", "")); err == nil {
+		t.Error("ReadXLSX dengan XML rusak harus gagal")
+	}
+
+	sheet := `<worksheet><sheetData><row r="1"><c r="XFE1" t="inlineStr"><is><t>x</t></is></c></row></sheetData></worksheet>`
+	if _, err := ReadXLSX(xlsxUji(t, "worksheets/sheet1.xml", sheet, "")); err == nil {
+		t.Error("ReadXLSX dengan kolom melewati XFD harus gagal")
+	}
+
+	// Setiap sel di XFD menambah 16384 sel kosong ke barisnya
+	var jauh strings.Builder
+	jauh.WriteString("<worksheet><sheetData>")
+	for i := 1; i <= maxSel/maxKolom+1; i++ {
+		fmt.Fprintf(&jauh, `<row r="%d"><c r="XFD%d"><v>1</v></c></row>`, i, i)
+	}
+	jauh.WriteString("</sheetData></worksheet>")
+	if _, err := ReadXLSX(xlsxUji(t, "worksheets/sheet1.xml", jauh.String(), "")); err == nil {
+		t.Error("ReadXLSX dengan terlalu banyak sel harus gagal")
+	}
+
+	// Sheet kecil setelah dikompresi tetapi melebihi batas setelah didekompresi
+	besar := "<worksheet><sheetData>" + strings.Repeat(" ", maxUkuranXML) + "</sheetData></worksheet>"
+	if _, err := ReadXLSX(xlsxUji(t, "worksheets/sheet1.xml", besar, "")); err == nil {
+		t.Error("ReadXLSX dengan XML terlalu besar harus gagal")
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := map[string]int{
+		"A1":   0,
+		"C5":   2,
+		"Z10":  25,
+		"AA1":  26,
+		"AZ3":  51,
+		"XFD1": 16383,
+		"12":   -1,
+		"":     -1,
+	}
+	for ref, want := range tests {
+		if got := columnIndex(ref); got != want {
+			t.Errorf("columnIndex(%q) = %d, want %d", ref, got, want)
+		}
+	}
+
+	// Referensi setelah XFD, termasuk yang sangat panjang, tidak boleh
+	// overflow menjadi indeks kecil atau negatif
+	for _, ref := range []string{"XFE1", "ZZZZ1", strings.Repeat("Z", 100) + "1"} {
+		if got := columnIndex(ref); got <= maxKolom {
+			t.Errorf("columnIndex(%.10q) = %d, want > %d", ref, got, maxKolom)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Local menyimpan file di filesystem di bawah direktori root. Cocok untuk
+// satu server, atau beberapa replika yang me-mount volume bersama (NFS).
+type Local struct {
+	root string
+}
+
+// NewLocal membuat Local. root kosong berarti direktori kerja aplikasi.
+func NewLocal(root string) *Local {
+	if root == "" {
+		root = "."
+	}
+	return &Local{root: root}
+}
+
+func (l *Local) Name() string {
+	return "local"
+}
+
+// Path mengembalikan lokasi file untuk key di disk
+func (l *Local) Path(key string) (string, error) {
+	bersih, err := BersihkanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(bersih)), nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	p, err := l.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	// Tulis ke file sementara lalu rename agar pembaca tidak melihat file
+	// setengah jadi
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && size >= 0 && n != size {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, *Info, error) {
+	p, err := l.Path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if stat.IsDir() {
+		f.Close()
+		return nil, nil, ErrNotFound
+	}
+	return f, l.info(key, stat), nil
+}
+
+func (l *Local) Stat(ctx context.Context, key string) (*Info, error) {
+	p, err := l.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, ErrNotFound
+	}
+	return l.info(key, stat), nil
+}
+
+func (l *Local) info(key string, stat os.FileInfo) *Info {
+	return &Info{
+		Key:         key,
+		Size:        stat.Size(),
+		ModTime:     stat.ModTime(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+	}
+}
+
+// Delete menghapus file lalu folder induknya jika sudah kosong, seperti
+// object storage yang tidak mengenal folder kosong
+func (l *Local) Delete(ctx context.Context, key string) error {
+	p, err := l.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	dir := filepath.Dir(p)
+	if filepath.Clean(dir) != filepath.Clean(l.root) {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			os.Remove(dir)
+		}
+	}
+	return nil
+}
+
+func (l *Local) DeletePrefix(ctx context.Context, prefix string) error {
+	p, err := l.Path(prefix)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(p)
+}
+
+func (l *Local) PresignGet(ctx context.Context, key string, berlaku time.Duration) (string, error) {
+	return "", ErrPresignTidakDidukung
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Upload bertahap disimpan sebagai satu object per potongan dengan nama
+// offset awalnya, misalnya "<prefix>/00000000000004194304". Object storage
+// tidak bisa menambah isi file yang sudah ada, dan replika API yang berbeda
+// bisa menerima potongan dari upload yang sama; setelah lengkap, potongan
+// dibaca berurutan dengan BacaPotongan.
+
+// KeyPotongan mengembalikan key potongan yang dimulai di offset mulai
+func KeyPotongan(prefix string, mulai int64) string {
+	return Key(prefix, fmt.Sprintf("%020d", mulai))
+}
+
+// TulisPotongan menyimpan tepat size byte dari r sebagai potongan di offset
+// mulai. Potongan yang terpotong (koneksi putus) tidak disimpan.
+func TulisPotongan(ctx context.Context, store BlobStore, prefix string, mulai int64, r io.Reader, size int64) error {
+	return store.Put(ctx, KeyPotongan(prefix, mulai), io.LimitReader(r, size), size)
+}
+
+// BacaPotongan membaca potongan di bawah prefix secara berurutan sebagai
+// satu stream berukuran total. Potongan yang hilang menghasilkan error.
+func BacaPotongan(ctx context.Context, store BlobStore, prefix string, total int64) io.ReadCloser {
+	return &pembacaPotongan{ctx: ctx, store: store, prefix: prefix, total: total}
+}
+
+type pembacaPotongan struct {
+	ctx    context.Context
+	store  BlobStore
+	prefix string
+	total  int64
+	offset int64
+	aktif  io.ReadCloser
+}
+
+func (p *pembacaPotongan) Read(buf []byte) (int, error) {
+	for {
+		if p.aktif == nil {
+			if p.offset >= p.total {
+				return 0, io.EOF
+			}
+			rc, _, err := p.store.Get(p.ctx, KeyPotongan(p.prefix, p.offset))
+			if errors.Is(err, ErrNotFound) {
+				return 0, fmt.Errorf("potongan upload di offset %d tidak ditemukan", p.offset)
+			}
+			if err != nil {
+				return 0, err
+			}
+			p.aktif = rc
+		}
+
+		n, err := p.aktif.Read(buf)
+		p.offset += int64(n)
+		if err == io.EOF {
+			p.aktif.Close()
+			p.aktif = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (p *pembacaPotongan) Close() error {
+	if p.aktif != nil {
+		return p.aktif.Close()
+	}
+	return nil
+}
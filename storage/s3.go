@@ -0,0 +1,442 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// payloadTanpaTanda dipakai agar body upload bisa di-stream tanpa dihitung
+// hash-nya terlebih dahulu
+const payloadTanpaTanda = "UNSIGNED-PAYLOAD"
+
+// S3Options berisi pengaturan S3
+type S3Options struct {
+	Endpoint  string // misalnya https://s3.ap-southeast-1.amazonaws.com atau http://minio:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Prefix    string // awalan key di bucket, opsional
+	PathStyle bool   // http://host/bucket/key; wajib untuk MinIO
+	HTTP      *http.Client
+}
+
+// S3 menyimpan file di object storage yang kompatibel dengan S3 (AWS S3,
+// MinIO, Ceph RGW). Request ditandatangani dengan AWS Signature V4.
+type S3 struct {
+	opts     S3Options
+	endpoint *url.URL
+	http     *http.Client
+	now      func() time.Time
+}
+
+// NewS3 membuat S3 dari S3Options
+func NewS3(opts S3Options) (*S3, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return nil, errors.New("S3_ENDPOINT dan S3_BUCKET wajib diisi")
+	}
+	if opts.AccessKey == "" || opts.SecretKey == "" {
+		return nil, errors.New("S3_ACCESS_KEY dan S3_SECRET_KEY wajib diisi")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("S3_ENDPOINT tidak valid: %s", opts.Endpoint)
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	opts.Prefix = strings.Trim(opts.Prefix, "/")
+	client := opts.HTTP
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &S3{opts: opts, endpoint: endpoint, http: client, now: time.Now}, nil
+}
+
+func (s *S3) Name() string {
+	return "s3"
+}
+
+// objectKey menambahkan prefix bucket ke key storage
+func (s *S3) objectKey(key string) (string, error) {
+	bersih, err := BersihkanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if s.opts.Prefix != "" {
+		return s.opts.Prefix + "/" + bersih, nil
+	}
+	return bersih, nil
+}
+
+// objectURL membuat URL object (atau bucket jika objKey kosong)
+func (s *S3) objectURL(objKey string, query url.Values) (host string, uriPath string, rawURL string) {
+	host = s.endpoint.Host
+	uriPath = "/" + uriEncode(objKey, false)
+	if s.opts.PathStyle {
+		uriPath = "/" + uriEncode(s.opts.Bucket, true)
+		if objKey != "" {
+			uriPath += "/" + uriEncode(objKey, false)
+		}
+	} else {
+		host = s.opts.Bucket + "." + host
+	}
+	basePath := strings.TrimRight(s.endpoint.EscapedPath(), "/")
+	uriPath = basePath + uriPath
+
+	rawURL = s.endpoint.Scheme + "://" + host + uriPath
+	if len(query) > 0 {
+		rawURL += "?" + queryKanonik(query)
+	}
+	return host, uriPath, rawURL
+}
+
+// request membuat dan menandatangani request ke object
+func (s *S3) request(ctx context.Context, method string, objKey string, query url.Values, body io.Reader, size int64) (*http.Request, error) {
+	_, _, rawURL := s.objectURL(objKey, query)
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	req.Header.Set("x-amz-content-sha256", payloadTanpaTanda)
+	s.tandatangani(req, payloadTanpaTanda, s.now())
+	return req, nil
+}
+
+// kirim menjalankan request dan mengubah status error menjadi error Go
+func (s *S3) kirim(req *http.Request) (*http.Response, error) {
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	xml.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&s3Err)
+	if s3Err.Code == "NoSuchKey" {
+		return nil, ErrNotFound
+	}
+	if s3Err.Code == "" {
+		s3Err.Code = resp.Status
+	}
+	return nil, fmt.Errorf("S3 %s %s: %s %s", req.Method, req.URL.Path, s3Err.Code, s3Err.Message)
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	objKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+
+	// PUT object butuh Content-Length; ukuran yang tidak diketahui ditampung
+	// dulu di file sementara
+	if size < 0 {
+		tmp, err := os.CreateTemp("", "claire-s3-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if size, err = io.Copy(tmp, r); err != nil {
+			return err
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r = tmp
+	}
+	if size == 0 {
+		r = http.NoBody
+	}
+
+	req, err := s.request(ctx, http.MethodPut, objKey, nil, r, size)
+	if err != nil {
+		return err
+	}
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.kirim(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *Info, error) {
+	objKey, err := s.objectKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := s.request(ctx, http.MethodGet, objKey, nil, nil, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.kirim(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, infoDariHeader(key, resp), nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (*Info, error) {
+	objKey, err := s.objectKey(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s.request(ctx, http.MethodHead, objKey, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.kirim(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return infoDariHeader(key, resp), nil
+}
+
+func infoDariHeader(key string, resp *http.Response) *Info {
+	info := &Info{Key: key, Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = modTime
+	}
+	return info
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	objKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+	return s.hapusObject(ctx, objKey)
+}
+
+func (s *S3) hapusObject(ctx context.Context, objKey string) error {
+	req, err := s.request(ctx, http.MethodDelete, objKey, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	resp, err := s.kirim(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// DeletePrefix mendaftar object dengan ListObjectsV2 lalu menghapusnya satu
+// per satu
+func (s *S3) DeletePrefix(ctx context.Context, prefix string) error {
+	objPrefix, err := s.objectKey(prefix)
+	if err != nil {
+		return err
+	}
+	objPrefix += "/"
+
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {objPrefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := s.request(ctx, http.MethodGet, "", query, nil, 0)
+		if err != nil {
+			return err
+		}
+		resp, err := s.kirim(req)
+		if err != nil {
+			return err
+		}
+		var hasil struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&hasil)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("gagal membaca daftar object S3: %v", err)
+		}
+
+		for _, obj := range hasil.Contents {
+			if err := s.hapusObject(ctx, obj.Key); err != nil {
+				return err
+			}
+		}
+		if !hasil.IsTruncated || hasil.NextContinuationToken == "" {
+			return nil
+		}
+		token = hasil.NextContinuationToken
+	}
+}
+
+// PresignGet membuat URL GET yang berlaku selama berlaku (maksimal 7 hari)
+func (s *S3) PresignGet(ctx context.Context, key string, berlaku time.Duration) (string, error) {
+	objKey, err := s.objectKey(key)
+	if err != nil {
+		return "", err
+	}
+	detik := int(berlaku.Seconds())
+	if detik < 1 {
+		detik = 1
+	}
+	if detik > 7*24*3600 {
+		detik = 7 * 24 * 3600
+	}
+	return s.presign(http.MethodGet, objKey, detik, s.now()), nil
+}
+
+func (s *S3) presign(method string, objKey string, detik int, now time.Time) string {
+	now = now.UTC()
+	tanggal := now.Format("20060102")
+	amzDate := now.Format("20060102T150405Z")
+	scope := tanggal + "/" + s.opts.Region + "/s3/aws4_request"
+
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {s.opts.AccessKey + "/" + scope},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {strconv.Itoa(detik)},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	host, uriPath, _ := s.objectURL(objKey, nil)
+	kanonik := strings.Join([]string{
+		method,
+		uriPath,
+		queryKanonik(query),
+		"host:" + host + "\n",
+		"host",
+		payloadTanpaTanda,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(tanggal, amzDate, scope, kanonik))
+
+	_, _, rawURL := s.objectURL(objKey, query)
+	return rawURL
+}
+
+// tandatangani menambahkan header Authorization AWS Signature V4. Header
+// yang ditandatangani: host, range, dan semua x-amz-*.
+func (s *S3) tandatangani(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	tanggal := now.Format("20060102")
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("x-amz-date", amzDate)
+
+	header := map[string]string{"host": req.URL.Host}
+	for nama, nilai := range req.Header {
+		lower := strings.ToLower(nama)
+		if lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			header[lower] = strings.TrimSpace(strings.Join(nilai, ","))
+		}
+	}
+	nama := make([]string, 0, len(header))
+	for n := range header {
+		nama = append(nama, n)
+	}
+	sort.Strings(nama)
+	var headerKanonik strings.Builder
+	for _, n := range nama {
+		headerKanonik.WriteString(n + ":" + header[n] + "\n")
+	}
+	signedHeaders := strings.Join(nama, ";")
+
+	kanonik := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		queryKanonik(req.URL.Query()),
+		headerKanonik.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := tanggal + "/" + s.opts.Region + "/s3/aws4_request"
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKey, scope, signedHeaders, s.signature(tanggal, amzDate, scope, kanonik)))
+}
+
+func (s *S3) signature(tanggal, amzDate, scope, kanonik string) string {
+	hash := sha256.Sum256([]byte(kanonik))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	kunci := hmacSHA256([]byte("AWS4"+s.opts.SecretKey), tanggal)
+	kunci = hmacSHA256(kunci, s.opts.Region)
+	kunci = hmacSHA256(kunci, "s3")
+	kunci = hmacSHA256(kunci, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(kunci, stringToSign))
+}
+
+func hmacSHA256(kunci []byte, data string) []byte {
+	mac := hmac.New(sha256.New, kunci)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// queryKanonik mengurutkan dan meng-encode query sesuai aturan SigV4
+func queryKanonik(query url.Values) string {
+	kunci := make([]string, 0, len(query))
+	for k := range query {
+		kunci = append(kunci, k)
+	}
+	sort.Strings(kunci)
+
+	var bagian []string
+	for _, k := range kunci {
+		nilai := append([]string(nil), query[k]...)
+		sort.Strings(nilai)
+		for _, v := range nilai {
+			bagian = append(bagian, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(bagian, "&")
+}
+
+// uriEncode meng-encode semua karakter selain A-Z a-z 0-9 - _ . ~ (dan /
+// jika encodeSlash false)
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
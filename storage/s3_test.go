@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// s3Palsu adalah pengganti S3 di memori untuk pengujian: PUT, GET, HEAD dan
+// DELETE object serta ListObjectsV2 dengan gaya path (/bucket/key). Daftar
+// object dibagi per dua item supaya continuation token ikut teruji; seperti
+// S3, token melanjutkan daftar setelah key terakhir yang sudah dikirim.
+type s3Palsu struct {
+	t      *testing.T
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+const ukuranHalamanS3 = 2
+
+func (s *s3Palsu) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=kunci/") || r.Header.Get("x-amz-date") == "" {
+		s.t.Errorf("%s %s tanpa tanda tangan SigV4: %q", r.Method, r.URL.Path, auth)
+		http.Error(w, "", http.StatusForbidden)
+		return
+	}
+
+	if r.URL.Path == "/"+s.bucket {
+		s.daftar(w, r)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+s.bucket+"/")
+	if !ok || key == "" {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(data)) != r.ContentLength {
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = data
+	case http.MethodGet, http.MethodHead:
+		data, ada := s.objects[key]
+		if !ada {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			}
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *s3Palsu) daftar(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if r.Method != http.MethodGet || query.Get("list-type") != "2" {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	setelah := query.Get("continuation-token")
+	s.mu.Lock()
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > setelah {
+			keys = append(keys, key)
+		}
+	}
+	s.mu.Unlock()
+	sort.Strings(keys)
+
+	akhir := ukuranHalamanS3
+	if akhir > len(keys) {
+		akhir = len(keys)
+	}
+
+	type object struct {
+		Key string `xml:"Key"`
+	}
+	hasil := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Contents              []object `xml:"Contents"`
+		IsTruncated           bool     `xml:"IsTruncated"`
+		NextContinuationToken string   `xml:"NextContinuationToken,omitempty"`
+	}{IsTruncated: akhir < len(keys)}
+	for _, key := range keys[:akhir] {
+		hasil.Contents = append(hasil.Contents, object{Key: key})
+	}
+	if hasil.IsTruncated {
+		hasil.NextContinuationToken = keys[akhir-1]
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(hasil)
+}
+
+// keys mengembalikan semua key object yang tersimpan, terurut
+func (s *s3Palsu) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// s3Uji membuat klien S3 ke s3Palsu yang berjalan di server HTTP lokal
+func s3Uji(t *testing.T, palsu *s3Palsu) *S3 {
+	t.Helper()
+	server := httptest.NewServer(palsu)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3(S3Options{
+		Endpoint:  server.URL,
+		Bucket:    palsu.bucket,
+		AccessKey: "kunci",
+		SecretKey: "rahasia",
+		Prefix:    "/claire/",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestS3Conformance(t *testing.T) {
+	palsu := &s3Palsu{t: t, bucket: "rekaman", objects: make(map[string][]byte)}
+	ujiBlobStore(t, s3Uji(t, palsu))
+}
+
+func TestS3MemakaiPrefix(t *testing.T) {
+	palsu := &s3Palsu{t: t, bucket: "rekaman", objects: make(map[string][]byte)}
+	store := s3Uji(t, palsu)
+	if err := store.Put(bg, "sampel_suara/a b.wav", strings.NewReader("isi"), 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := palsu.keys(); len(got) != 1 || got[0] != "claire/sampel_suara/a b.wav" {
+		t.Errorf("object tersimpan = %v, want [claire/sampel_suara/a b.wav]", got)
+	}
+}
+
+func TestS3PresignGet(t *testing.T) {
+	palsu := &s3Palsu{t: t, bucket: "rekaman", objects: make(map[string][]byte)}
+	store := s3Uji(t, palsu)
+	rawURL, err := store.PresignGet(bg, "rekaman/job/sesi1.wav", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bagian := range []string{"/rekaman/claire/rekaman/job/sesi1.wav?", "X-Amz-Expires=3600", "X-Amz-Signature="} {
+		if !strings.Contains(rawURL, bagian) {
+			t.Errorf("PresignGet = %s, tidak memuat %s", rawURL, bagian)
+		}
+	}
+	if _, err := store.PresignGet(bg, "../luar.wav", time.Hour); !errors.Is(err, ErrKeyTidakValid) {
+		t.Errorf("PresignGet key tidak valid = %v, want ErrKeyTidakValid", err)
+	}
+}
@@ -0,0 +1,182 @@
+// Package storage menyimpan file audio (sampel suara dosen, audio evaluasi,
+// rekaman kuliah) di balik satu interface BlobStore sehingga beberapa replika
+// API bisa berbagi penyimpanan yang sama.
+//
+// File dialamatkan dengan key relatif bergaya path, misalnya
+// "sampel_suara/Budi_MT/<uuid>.wav". Key inilah yang disimpan di database;
+// Local dengan root "." memetakan key ke path yang sama seperti sebelum
+// storage dipakai, sehingga data lama tetap terbaca.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"CLAIRE/config"
+)
+
+var (
+	// ErrNotFound dikembalikan jika key tidak ada di store
+	ErrNotFound = errors.New("file tidak ditemukan di storage")
+	// ErrPresignTidakDidukung dikembalikan store yang tidak bisa membuat URL
+	// langsung; file harus di-stream lewat API
+	ErrPresignTidakDidukung = errors.New("storage tidak mendukung URL presigned")
+	// ErrKeyTidakValid dikembalikan untuk key kosong, absolut, atau keluar
+	// dari root storage
+	ErrKeyTidakValid = errors.New("key storage tidak valid")
+)
+
+// Info adalah metadata sebuah file di store
+type Info struct {
+	Key         string
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+}
+
+// BlobStore adalah penyimpanan file berbasis key
+type BlobStore interface {
+	// Put menyimpan isi r ke key, menimpa file lama. size adalah jumlah byte
+	// r, atau -1 jika tidak diketahui.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get membuka file untuk dibaca. Reader dari Local juga io.ReadSeeker
+	// sehingga bisa dilayani dengan Range request.
+	Get(ctx context.Context, key string) (io.ReadCloser, *Info, error)
+	// Stat mengembalikan metadata file atau ErrNotFound
+	Stat(ctx context.Context, key string) (*Info, error)
+	// Delete menghapus file. Key yang tidak ada tidak dianggap error.
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix menghapus semua file di bawah prefix (seperti folder)
+	DeletePrefix(ctx context.Context, prefix string) error
+	// PresignGet membuat URL sementara untuk mengunduh file langsung dari
+	// storage, atau ErrPresignTidakDidukung
+	PresignGet(ctx context.Context, key string, berlaku time.Duration) (string, error)
+	// Name mengembalikan nama backend untuk log dan health check
+	Name() string
+}
+
+// Key menggabungkan bagian-bagian path menjadi key storage
+func Key(bagian ...string) string {
+	return path.Join(bagian...)
+}
+
+// BersihkanKey menormalkan key dan menolak key yang keluar dari root,
+// misalnya "../config" atau "/etc/passwd"
+func BersihkanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", ErrKeyTidakValid
+	}
+	bersih := path.Clean(key)
+	if bersih == "." || bersih == ".." || strings.HasPrefix(bersih, "../") {
+		return "", ErrKeyTidakValid
+	}
+	return bersih, nil
+}
+
+// FromConfig membuat BlobStore sesuai STORAGE_BACKEND
+func FromConfig(cfg *config.Config) (BlobStore, error) {
+	switch cfg.StorageBackend {
+	case "", "local":
+		return NewLocal(cfg.StorageLocalDir), nil
+	case "s3":
+		return NewS3(S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			Prefix:    cfg.S3Prefix,
+			PathStyle: cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("STORAGE_BACKEND tidak dikenal: %s (gunakan local atau s3)", cfg.StorageBackend)
+	}
+}
+
+// PathLokal mengembalikan path file di disk jika store adalah Local
+func PathLokal(store BlobStore, key string) (string, bool) {
+	local, ok := store.(*Local)
+	if !ok {
+		return "", false
+	}
+	p, err := local.Path(key)
+	if err != nil {
+		return "", false
+	}
+	return p, true
+}
+
+// SimpanFile mengunggah file lokal ke key. Jika store adalah Local dan file
+// sudah berada di lokasi key, tidak ada yang disalin.
+func SimpanFile(ctx context.Context, store BlobStore, key string, pathFile string) error {
+	if p, ok := PathLokal(store, key); ok && samaFile(p, pathFile) {
+		return nil
+	}
+
+	f, err := os.Open(pathFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return store.Put(ctx, key, f, info.Size())
+}
+
+// UnduhSementara menyediakan isi key sebagai file di disk, misalnya untuk
+// dikirim ke layanan analisis. Untuk Local path aslinya dipakai langsung;
+// selain itu file diunduh ke direktori sementara. hapus wajib dipanggil
+// setelah file selesai dipakai.
+func UnduhSementara(ctx context.Context, store BlobStore, key string) (pathFile string, hapus func(), err error) {
+	if p, ok := PathLokal(store, key); ok {
+		if _, err := os.Stat(p); err != nil {
+			if os.IsNotExist(err) {
+				return "", nil, ErrNotFound
+			}
+			return "", nil, err
+		}
+		return p, func() {}, nil
+	}
+
+	rc, _, err := store.Get(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rc.Close()
+
+	tmp, err := os.CreateTemp("", "claire-*"+path.Ext(key))
+	if err != nil {
+		return "", nil, err
+	}
+	hapus = func() { os.Remove(tmp.Name()) }
+	if _, err := io.Copy(tmp, rc); err != nil {
+		tmp.Close()
+		hapus()
+		return "", nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		hapus()
+		return "", nil, err
+	}
+	return tmp.Name(), hapus, nil
+}
+
+func samaFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return os.SameFile(infoA, infoB)
+}
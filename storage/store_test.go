@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var bg = context.Background()
+
+func TestBersihkanKey(t *testing.T) {
+	valid := map[string]string{
+		"sampel_suara/a.wav":        "sampel_suara/a.wav",
+		"sampel_suara//a.wav":       "sampel_suara/a.wav",
+		"sampel_suara/./x/../a.wav": "sampel_suara/a.wav",
+		"rekaman\\job\\sesi1.wav":   "rekaman/job/sesi1.wav",
+		"a/..b":                     "a/..b",
+	}
+	for key, want := range valid {
+		got, err := BersihkanKey(key)
+		if err != nil || got != want {
+			t.Errorf("BersihkanKey(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	for _, key := range keyTidakValid {
+		if got, err := BersihkanKey(key); !errors.Is(err, ErrKeyTidakValid) {
+			t.Errorf("BersihkanKey(%q) = %q, %v; want ErrKeyTidakValid", key, got, err)
+		}
+	}
+}
+
+// keyTidakValid berisi key yang keluar dari root storage
+var keyTidakValid = []string{
+	"",
+	".",
+	"..",
+	"../luar.wav",
+	"/etc/passwd",
+	"a/../../luar.wav",
+	"..\\luar.wav",
+	"\\\\server\\share",
+}
+
+func TestLocalConformance(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "root")
+	ujiBlobStore(t, NewLocal(root))
+
+	// Key yang ditolak tidak boleh menulis apa pun di luar root
+	entries, err := os.ReadDir(filepath.Dir(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "root" {
+		t.Errorf("isi direktori di luar root berubah: %v", entries)
+	}
+}
+
+func TestLocalPresignTidakDidukung(t *testing.T) {
+	if _, err := NewLocal(t.TempDir()).PresignGet(bg, "a.wav", 0); !errors.Is(err, ErrPresignTidakDidukung) {
+		t.Errorf("PresignGet = %v, want ErrPresignTidakDidukung", err)
+	}
+}
+
+// ujiBlobStore menjalankan perilaku yang sama untuk setiap BlobStore
+func ujiBlobStore(t *testing.T, store BlobStore) {
+	t.Run("PutGetStat", func(t *testing.T) {
+		isi := []byte("RIFF audio")
+		if err := store.Put(bg, "sampel_suara/Budi/a.wav", bytes.NewReader(isi), int64(len(isi))); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		cekIsi(t, store, "sampel_suara/Budi/a.wav", isi)
+
+		info, err := store.Stat(bg, "sampel_suara/Budi/a.wav")
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		if info.Key != "sampel_suara/Budi/a.wav" || info.Size != int64(len(isi)) {
+			t.Errorf("Stat = %+v, want key dan size %d", info, len(isi))
+		}
+	})
+
+	t.Run("PutMenimpa", func(t *testing.T) {
+		store.Put(bg, "timpa.wav", strings.NewReader("lama sekali"), 11)
+		if err := store.Put(bg, "timpa.wav", strings.NewReader("baru"), 4); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		cekIsi(t, store, "timpa.wav", []byte("baru"))
+	})
+
+	t.Run("PutUkuranTidakDiketahui", func(t *testing.T) {
+		if err := store.Put(bg, "stream.wav", strings.NewReader("tanpa ukuran"), -1); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		cekIsi(t, store, "stream.wav", []byte("tanpa ukuran"))
+
+		if err := store.Put(bg, "kosong.wav", strings.NewReader(""), 0); err != nil {
+			t.Fatalf("Put kosong: %v", err)
+		}
+		cekIsi(t, store, "kosong.wav", nil)
+	})
+
+	t.Run("PutTerpotong", func(t *testing.T) {
+		if err := store.Put(bg, "terpotong.wav", strings.NewReader("pendek"), 100); err == nil {
+			t.Fatal("Put dengan body lebih pendek dari size harus gagal")
+		}
+		if _, err := store.Stat(bg, "terpotong.wav"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat setelah Put gagal = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("TidakDitemukan", func(t *testing.T) {
+		if _, _, err := store.Get(bg, "tidak/ada.wav"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get = %v, want ErrNotFound", err)
+		}
+		if _, err := store.Stat(bg, "tidak/ada.wav"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat = %v, want ErrNotFound", err)
+		}
+		if err := store.Delete(bg, "tidak/ada.wav"); err != nil {
+			t.Errorf("Delete key yang tidak ada = %v, want nil", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store.Put(bg, "hapus/a.wav", strings.NewReader("a"), 1)
+		if err := store.Delete(bg, "hapus/a.wav"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.Stat(bg, "hapus/a.wav"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat setelah Delete = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("DeletePrefix", func(t *testing.T) {
+		keys := []string{"evaluasi/x/1.wav", "evaluasi/x/2.wav", "evaluasi/x/sub/3.wav", "evaluasi/x/sub/4.wav", "evaluasi/x/5.wav"}
+		for _, key := range keys {
+			store.Put(bg, key, strings.NewReader(key), int64(len(key)))
+		}
+		store.Put(bg, "evaluasi/xy/lain.wav", strings.NewReader("lain"), 4)
+
+		if err := store.DeletePrefix(bg, "evaluasi/x"); err != nil {
+			t.Fatalf("DeletePrefix: %v", err)
+		}
+		for _, key := range keys {
+			if _, err := store.Stat(bg, key); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Stat(%s) setelah DeletePrefix = %v, want ErrNotFound", key, err)
+			}
+		}
+		cekIsi(t, store, "evaluasi/xy/lain.wav", []byte("lain"))
+
+		if err := store.DeletePrefix(bg, "evaluasi/tidak-ada"); err != nil {
+			t.Errorf("DeletePrefix prefix kosong = %v, want nil", err)
+		}
+	})
+
+	t.Run("KeyTidakValid", func(t *testing.T) {
+		for _, key := range keyTidakValid {
+			if err := store.Put(bg, key, strings.NewReader("x"), 1); !errors.Is(err, ErrKeyTidakValid) {
+				t.Errorf("Put(%q) = %v, want ErrKeyTidakValid", key, err)
+			}
+			if _, _, err := store.Get(bg, key); !errors.Is(err, ErrKeyTidakValid) {
+				t.Errorf("Get(%q) = %v, want ErrKeyTidakValid", key, err)
+			}
+			if _, err := store.Stat(bg, key); !errors.Is(err, ErrKeyTidakValid) {
+				t.Errorf("Stat(%q) = %v, want ErrKeyTidakValid", key, err)
+			}
+			if err := store.Delete(bg, key); !errors.Is(err, ErrKeyTidakValid) {
+				t.Errorf("Delete(%q) = %v, want ErrKeyTidakValid", key, err)
+			}
+			if err := store.DeletePrefix(bg, key); !errors.Is(err, ErrKeyTidakValid) {
+				t.Errorf("DeletePrefix(%q) = %v, want ErrKeyTidakValid", key, err)
+			}
+		}
+	})
+
+	t.Run("Potongan", func(t *testing.T) {
+		prefix := "rekaman/job/sesi1.wav.part"
+		potongan := [][]byte{bytes.Repeat([]byte("a"), 5), bytes.Repeat([]byte("b"), 3), bytes.Repeat([]byte("c"), 7)}
+
+		var semua []byte
+		for _, isi := range potongan {
+			// Reader lebih panjang dari size: hanya size byte yang disimpan
+			r := io.MultiReader(bytes.NewReader(isi), strings.NewReader("lebih"))
+			if err := TulisPotongan(bg, store, prefix, int64(len(semua)), r, int64(len(isi))); err != nil {
+				t.Fatalf("TulisPotongan: %v", err)
+			}
+			semua = append(semua, isi...)
+		}
+
+		rc := BacaPotongan(bg, store, prefix, int64(len(semua)))
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("BacaPotongan: %v", err)
+		}
+		if !bytes.Equal(got, semua) {
+			t.Errorf("BacaPotongan = %q, want %q", got, semua)
+		}
+
+		// Potongan yang terpotong tidak disimpan
+		if err := TulisPotongan(bg, store, prefix, int64(len(semua)), strings.NewReader("xy"), 10); err == nil {
+			t.Error("TulisPotongan dengan body terpotong harus gagal")
+		}
+
+		// Potongan yang hilang dilaporkan sebagai error
+		store.Delete(bg, KeyPotongan(prefix, 5))
+		rc = BacaPotongan(bg, store, prefix, int64(len(semua)))
+		_, err = io.ReadAll(rc)
+		rc.Close()
+		if err == nil || !strings.Contains(err.Error(), "offset 5") {
+			t.Errorf("BacaPotongan dengan potongan hilang = %v, want error offset 5", err)
+		}
+
+		if err := store.DeletePrefix(bg, prefix); err != nil {
+			t.Fatalf("DeletePrefix: %v", err)
+		}
+		if _, err := store.Stat(bg, KeyPotongan(prefix, 0)); !errors.Is(err, ErrNotFound) {
+			t.Errorf("potongan tersisa setelah DeletePrefix: %v", err)
+		}
+	})
+}
+
+// cekIsi memastikan isi key sama dengan want
+func cekIsi(t *testing.T, store BlobStore, key string, want []byte) {
+	t.Helper()
+	rc, info, err := store.Get(bg, key)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", key, err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", key, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Get(%s) = %q, want %q", key, got, want)
+	}
+	if info.Size != int64(len(want)) {
+		t.Errorf("Get(%s) size = %d, want %d", key, info.Size, len(want))
+	}
+}
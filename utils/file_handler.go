@@ -1,13 +1,19 @@
 package utils
 
 import (
+    "context"
     "fmt"
     "io"
     "mime/multipart"
     "os"
+    "path"
     "path/filepath"
     "strings"
 
+    "CLAIRE/audio"
+    "CLAIRE/models"
+    "CLAIRE/storage"
+
     "github.com/google/uuid"
 )
 
@@ -19,84 +25,217 @@ func EnsureDir(dirPath string) error {
     return nil
 }
 
-// SaveAudioFile menyimpan file audio dan mengembalikan path-nya
-func SaveAudioFile(file *multipart.FileHeader, uploadDir string, dosenFolder string) (string, error) {
-    // Buat path direktori dosen
-    dosenDir := filepath.Join(uploadDir, dosenFolder)
-    
-    // Buat direktori jika belum ada
-    if err := EnsureDir(dosenDir); err != nil {
-        return "", fmt.Errorf("gagal membuat direktori dosen: %v", err)
+// SaveAudioFile menyimpan file audio ke store dan mengembalikan catatan
+// metadatanya; Path berisi key file di store
+func SaveAudioFile(ctx context.Context, store storage.BlobStore, file *multipart.FileHeader, uploadDir string, dosenFolder string) (*models.AudioFile, error) {
+    // Validasi ekstensi sebelum membuka file
+    if err := ValidateAudioExtension(file.Filename); err != nil {
+        return nil, err
     }
 
-    // Validasi ekstensi file
-    ext := strings.ToLower(filepath.Ext(file.Filename))
-    allowedExtensions := []string{".wav", ".webm", ".mp3", ".ogg", ".m4a", ".flac"}
-    
-    isValidExtension := false
-    for _, allowedExt := range allowedExtensions {
-        if ext == allowedExt {
-            isValidExtension = true
-            break
-        }
+    // Buka file source
+    src, err := file.Open()
+    if err != nil {
+        return nil, fmt.Errorf("gagal membuka file: %v", err)
     }
-    
-    if !isValidExtension {
-        return "", fmt.Errorf("hanya file audio yang diizinkan: %s", strings.Join(allowedExtensions, ", "))
+    defer src.Close()
+
+    return SaveAudioReader(ctx, store, src, file.Size, file.Filename, uploadDir, dosenFolder)
+}
+
+// SaveAudioReader menyimpan isi src dengan layout yang sama seperti
+// SaveAudioFile (<uploadDir>/<folder>/<uuid>.wav). filename adalah nama file
+// asli untuk validasi ekstensi, size bernilai -1 jika tidak diketahui.
+//
+// Format audio dikenali dari isinya. Selain WAV PCM 16 kHz mono, file
+// dikonversi ke format tersebut dengan ffmpeg dan file aslinya disimpan di
+// sampingnya sebagai <uuid>.asli<ext>. Isi yang bukan audio ditolak dengan
+// error yang membungkus audio.ErrBukanAudio.
+//
+// Metadata file yang disimpan (durasi, level audio) dikembalikan tanpa
+// dicatat ke database; pemanggil menyimpannya setelah file dipakai.
+func SaveAudioReader(ctx context.Context, store storage.BlobStore, src io.Reader, size int64, filename string, uploadDir string, dosenFolder string) (*models.AudioFile, error) {
+    siap, err := SiapkanAudio(ctx, src, size, filename)
+    if err != nil {
+        return nil, err
     }
+    defer siap.Hapus()
 
-    // Generate nama file unik dengan ekstensi .wav
-    fileID := uuid.New().String()
-    filename := fileID + ".wav" // Selalu simpan sebagai WAV untuk konsistensi
-    filePath := filepath.Join(dosenDir, filename)
+    return siap.Simpan(ctx, store, uploadDir, dosenFolder)
+}
 
-    // Buka file source
-    src, err := file.Open()
+// AudioSiap adalah upload audio yang sudah dikenali, dinormalkan, dan
+// dianalisis di file sementara lokal. Dipakai untuk memeriksa audio sebelum
+// disimpan ke store; panggil Hapus setelah selesai.
+type AudioSiap struct {
+    Format   string            // format upload sebelum dikonversi
+    File     *models.AudioFile // metadata file hasil normalisasi, Path kosong sampai Simpan
+    Metadata *audio.Metadata   // nil jika analisis gagal
+
+    asli  string // file upload
+    hasil string // file WAV normal, sama dengan asli jika tidak dikonversi
+}
+
+// SiapkanAudio menampung isi src di file sementara, mengonversinya ke WAV
+// PCM 16 kHz mono jika perlu, lalu menganalisisnya tanpa menyimpan ke store
+func SiapkanAudio(ctx context.Context, src io.Reader, size int64, filename string) (*AudioSiap, error) {
+    if err := ValidateAudioExtension(filename); err != nil {
+        return nil, err
+    }
+
+    format, src, err := audio.DeteksiReader(src)
     if err != nil {
-        return "", fmt.Errorf("gagal membuka file: %v", err)
+        return nil, err
     }
-    defer src.Close()
 
-    // Buat file destination
-    dst, err := os.Create(filePath)
+    // Tampung di file sementara karena ffmpeg membutuhkan file input
+    tmp, err := os.CreateTemp("", "claire-upload-*"+audio.Ekstensi(format))
+    if err != nil {
+        return nil, fmt.Errorf("gagal membuat file sementara: %v", err)
+    }
+    siap := &AudioSiap{Format: format, asli: tmp.Name(), hasil: tmp.Name()}
+    written, err := io.Copy(tmp, src)
+    if closeErr := tmp.Close(); err == nil {
+        err = closeErr
+    }
     if err != nil {
-        return "", fmt.Errorf("gagal membuat file: %v", err)
+        siap.Hapus()
+        return nil, fmt.Errorf("gagal menyimpan file: %v", err)
+    }
+    if size >= 0 && written != size {
+        siap.Hapus()
+        return nil, fmt.Errorf("ukuran file tidak sesuai: %d dari %d byte", written, size)
     }
-    defer dst.Close()
 
-    // Copy file (konversi format akan dilakukan di pipeline AI nanti)
-    if _, err = io.Copy(dst, src); err != nil {
-        return "", fmt.Errorf("gagal menyalin file: %v", err)
+    perlu, err := audio.PerluTranscode(siap.asli, format)
+    if err != nil {
+        siap.Hapus()
+        return nil, err
+    }
+    if perlu {
+        siap.hasil = siap.asli + ".wav"
+        if err := audio.Transcode(ctx, siap.asli, format, siap.hasil); err != nil {
+            siap.Hapus()
+            return nil, err
+        }
     }
 
-    return filePath, nil
+    siap.File, siap.Metadata = metadataAudio(ctx, "", siap.hasil, format)
+    if perlu {
+        // Sample rate rendah tidak terlihat lagi setelah dikonversi ke 16 kHz
+        siap.File.SampleRateAsli, _ = audio.SampleRateFile(ctx, siap.asli)
+    }
+    return siap, nil
 }
 
-// DeleteAudioFile menghapus file audio
-func DeleteAudioFile(filePath string) error {
-    if filePath == "" {
-        return nil
+// Kualitas memeriksa audio terhadap syarat kualitas sampel suara
+func (siap *AudioSiap) Kualitas(syarat audio.SyaratKualitas) audio.LaporanKualitas {
+    return audio.PeriksaKualitas(siap.Metadata, siap.File.SampleRateAsli, syarat)
+}
+
+// Simpan menyimpan audio ke store di <uploadDir>/<folder>/<uuid>.wav beserta
+// file aslinya jika audio hasil konversi
+func (siap *AudioSiap) Simpan(ctx context.Context, store storage.BlobStore, uploadDir string, dosenFolder string) (*models.AudioFile, error) {
+    // Generate nama file unik dengan ekstensi .wav
+    fileID := uuid.New().String()
+    key := storage.Key(uploadDir, dosenFolder, fileID+".wav")
+
+    if err := storage.SimpanFile(ctx, store, key, siap.hasil); err != nil {
+        return nil, fmt.Errorf("gagal menyimpan file: %v", err)
     }
+    if siap.hasil != siap.asli {
+        if err := storage.SimpanFile(ctx, store, KeyAudioAsli(key, siap.Format), siap.asli); err != nil {
+            store.Delete(ctx, key)
+            return nil, fmt.Errorf("gagal menyimpan file asli: %v", err)
+        }
+    }
+
+    file := *siap.File
+    file.Path = key
+    return &file, nil
+}
+
+// Hapus menghapus file sementara
+func (siap *AudioSiap) Hapus() {
+    os.Remove(siap.asli)
+    if siap.hasil != siap.asli {
+        os.Remove(siap.hasil)
+    }
+}
+
+// MetadataAudio menganalisis file lokal pathFile yang tersimpan di key.
+// Analisis yang gagal tidak menggagalkan penyimpanan; pesannya dicatat di
+// PesanError.
+func MetadataAudio(ctx context.Context, key string, pathFile string, formatAsli string) *models.AudioFile {
+    file, _ := metadataAudio(ctx, key, pathFile, formatAsli)
+    return file
+}
+
+// metadataAudio seperti MetadataAudio, juga mengembalikan hasil analisis
+// (nil jika gagal)
+func metadataAudio(ctx context.Context, key string, pathFile string, formatAsli string) (*models.AudioFile, *audio.Metadata) {
+    ukuran, _ := GetFileSize(pathFile)
+
+    meta, err := audio.AnalisisFile(ctx, pathFile)
+    if err != nil {
+        return &models.AudioFile{
+            Path:       key,
+            FormatAsli: formatAsli,
+            Ukuran:     ukuran,
+            PesanError: err.Error(),
+        }, nil
+    }
+
+    file := models.AudioFileDariMetadata(key, ukuran, meta)
+    file.FormatAsli = formatAsli
+    return file, meta
+}
+
+// KeyAudioAsli mengembalikan key file asli untuk file hasil konversi key,
+// misalnya "sampel_suara/Budi/<uuid>.asli.webm"
+func KeyAudioAsli(key string, format string) string {
+    return strings.TrimSuffix(key, path.Ext(key)) + ".asli" + audio.Ekstensi(format)
+}
+
+// ValidateAudioExtension memeriksa ekstensi nama file audio
+func ValidateAudioExtension(filename string) error {
+    ext := strings.ToLower(filepath.Ext(filename))
+    allowedExtensions := []string{".wav", ".webm", ".mp3", ".ogg", ".m4a", ".flac"}
     
-    if _, err := os.Stat(filePath); os.IsNotExist(err) {
-        return nil // File tidak ada, tidak perlu dihapus
+    for _, allowedExt := range allowedExtensions {
+        if ext == allowedExt {
+            return nil
+        }
     }
     
-    return os.Remove(filePath)
+    return fmt.Errorf("hanya file audio yang diizinkan: %s", strings.Join(allowedExtensions, ", "))
 }
 
-// DeleteDosenFolder menghapus seluruh folder dosen
-func DeleteDosenFolder(uploadDir string, dosenFolder string) error {
-    if dosenFolder == "" {
+// DeleteAudioFile menghapus file audio dari store beserta file aslinya
+// jika file tersebut hasil konversi
+func DeleteAudioFile(ctx context.Context, store storage.BlobStore, key string) error {
+    if key == "" {
         return nil
     }
     
-    dosenDir := filepath.Join(uploadDir, dosenFolder)
-    if _, err := os.Stat(dosenDir); os.IsNotExist(err) {
-        return nil // Folder tidak ada, tidak perlu dihapus
+    if err := store.Delete(ctx, key); err != nil {
+        return err
+    }
+    for _, format := range audio.SemuaFormat {
+        if err := store.Delete(ctx, KeyAudioAsli(key, format)); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// DeleteDosenFolder menghapus seluruh folder dosen dari store
+func DeleteDosenFolder(ctx context.Context, store storage.BlobStore, uploadDir string, dosenFolder string) error {
+    if dosenFolder == "" {
+        return nil
     }
     
-    return os.RemoveAll(dosenDir)
+    return store.DeletePrefix(ctx, storage.Key(uploadDir, dosenFolder))
 }
 
 // GetAudioFileURL mengembalikan URL untuk mengakses file audio